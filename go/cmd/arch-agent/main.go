@@ -19,17 +19,20 @@ import (
 	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
 )
 
-const dslRelativePath = "internal/usecase/ecommerce_architecture.go"
-
 type contentSnapshot struct {
-	GoCode string `json:"goCode"`
-	D2Code string `json:"d2Code"`
-	SVG    string `json:"svg"`
-	JSON   string `json:"json"`
+	GoCode       string   `json:"goCode"`
+	D2Code       string   `json:"d2Code"`
+	SVG          string   `json:"svg"`
+	JSON         string   `json:"json"`
+	SchemaErrors []string `json:"schemaErrors"`
+	// Validation is the result of the validation rules, as printed by
+	// arch-gen -validate -output json.
+	Validation *usecase.ValidationReport `json:"validation,omitempty"`
 }
 
 type server struct {
 	goDir        string
+	arch         usecase.WorkspaceArchitecture
 	generateMode string
 	studioSvc    usecase.StudioService
 	contentMu    sync.RWMutex
@@ -40,6 +43,8 @@ func main() {
 	port := flag.String("port", "8787", "Local agent port")
 	dir := flag.String("dir", ".", "Repository root directory")
 	mode := flag.String("mode", "gorun", "Generation mode: gorun or in-process")
+	archID := flag.String("arch", "", "Architecture to serve (default: the workspace default)")
+	listArchs := flag.Bool("list", false, "List the workspace architectures and exit")
 	flag.Parse()
 
 	goDir, err := filepath.Abs(*dir)
@@ -47,9 +52,25 @@ func main() {
 		log.Fatal(err)
 	}
 
+	ws, err := repository.LoadWorkspace(goDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *listArchs {
+		for _, a := range ws.Architectures {
+			fmt.Printf("%-24s %s\n", a.ID, a.DSL)
+		}
+		return
+	}
+	arch, err := ws.Select(*archID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	layoutRepo := repository.NewFSLayoutRepository(filepath.Join(goDir, "architectures"))
 	srv := &server{
 		goDir:        goDir,
+		arch:         arch,
 		generateMode: *mode,
 		studioSvc:    usecase.NewStudioService(layoutRepo, ast.GoASTSyncer{}),
 	}
@@ -63,7 +84,7 @@ func main() {
 	http.HandleFunc("/layout", withCORS(srv.handleLayout))
 
 	addr := fmt.Sprintf("127.0.0.1:%s", *port)
-	log.Printf("🧭 Arch Agent listening on http://%s (dir=%s, mode=%s)", addr, goDir, *mode)
+	log.Printf("🧭 Arch Agent listening on http://%s (dir=%s, arch=%s, mode=%s)", addr, goDir, arch.ID, *mode)
 	log.Fatal(http.ListenAndServe(addr, nil))
 }
 
@@ -88,6 +109,7 @@ func (s *server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{
 		"name": "arch-agent",
 		"mode": s.generateMode,
+		"arch": s.arch.ID,
 	})
 }
 
@@ -117,7 +139,7 @@ func (s *server) handleSVG(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *server) getContent(includeSVG bool) (contentSnapshot, error) {
-	mainPath := filepath.Join(s.goDir, dslRelativePath)
+	mainPath := s.dslPath()
 	data, err := os.ReadFile(mainPath)
 	if err != nil {
 		return contentSnapshot{}, err
@@ -146,10 +168,12 @@ func (s *server) getContent(includeSVG bool) (contentSnapshot, error) {
 	}
 
 	s.lastContent = contentSnapshot{
-		GoCode: goCode,
-		D2Code: d2Out,
-		SVG:    svg,
-		JSON:   jsonOut,
+		GoCode:       goCode,
+		D2Code:       d2Out,
+		SVG:          svg,
+		JSON:         jsonOut,
+		SchemaErrors: generator.DefaultGenerator().DocumentMessages(jsonOut),
+		Validation:   s.validate(),
 	}
 
 	return s.lastContent, nil
@@ -157,7 +181,11 @@ func (s *server) getContent(includeSVG bool) (contentSnapshot, error) {
 
 func (s *server) generateOutputs() (string, string, error) {
 	if s.generateMode == "in-process" {
-		gen := generator.DefaultGenerator()
+		gen, err := generator.ForArchitecture(s.arch.ID)
+		if err != nil {
+			return "", "", err
+		}
+		gen.Documents = nil // checked by DocumentMessages so that the preview still shows
 		jsonOut, _, err := gen.Generate(usecase.FormatJSON, false)
 		if err != nil {
 			return "", "", err
@@ -171,7 +199,7 @@ func (s *server) generateOutputs() (string, string, error) {
 		return jsonOut, d2Out, nil
 	}
 
-	cmdJSON := exec.Command("go", "run", "./cmd/arch-gen")
+	cmdJSON := exec.Command("go", "run", "./cmd/arch-gen", "-arch", s.arch.ID, "-schema=false")
 	cmdJSON.Dir = s.goDir
 	var jsonOut bytes.Buffer
 	cmdJSON.Stdout = &jsonOut
@@ -180,7 +208,7 @@ func (s *server) generateOutputs() (string, string, error) {
 		return "", "", fmt.Errorf("go run json failed: %w: %s", err, jsonOut.String())
 	}
 
-	cmdD2 := exec.Command("go", "run", "./cmd/arch-gen", "-format", "rich-d2")
+	cmdD2 := exec.Command("go", "run", "./cmd/arch-gen", "-arch", s.arch.ID, "-format", "rich-d2")
 	cmdD2.Dir = s.goDir
 	var d2Out bytes.Buffer
 	cmdD2.Stdout = &d2Out
@@ -192,6 +220,46 @@ func (s *server) generateOutputs() (string, string, error) {
 	return jsonOut.String(), d2Out.String(), nil
 }
 
+// validate runs the validation rules, including those of the workspace policy
+// in gorun mode, and returns their results; nil when they cannot run.
+func (s *server) validate() *usecase.ValidationReport {
+	if s.generateMode == "in-process" {
+		gen, err := generator.ForArchitecture(s.arch.ID)
+		if err != nil {
+			log.Printf("❌ Validation error: %v", err)
+			return nil
+		}
+		errs, err := gen.Validate()
+		if err != nil {
+			log.Printf("❌ Validation error: %v", err)
+			return nil
+		}
+		report := usecase.NewValidationReport(s.arch.ID, errs, gen.FailOn, s.goDir)
+		return &report
+	}
+
+	// arch-gen exits with 1 when validation fails, so only output that is not
+	// a report counts as an error.
+	cmd := exec.Command("go", "run", "./cmd/arch-gen", "-arch", s.arch.ID, "-validate", "-output", "json")
+	cmd.Dir = s.goDir
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var report usecase.ValidationReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		log.Printf("❌ Validation error: %v\n%s", runErr, stderr.String())
+		return nil
+	}
+	return &report
+}
+
+// dslPath returns the absolute path of the served architecture's DSL file.
+func (s *server) dslPath() string {
+	return filepath.Join(s.goDir, s.arch.DSL)
+}
+
 func generateSVGFromD2(d2Source string) string {
 	if strings.TrimSpace(d2Source) == "" {
 		return ""
@@ -231,7 +299,7 @@ func (s *server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("POST /update (go) bytes=%d from %s", len(update.Content), r.RemoteAddr)
-	mainPath := filepath.Join(s.goDir, dslRelativePath)
+	mainPath := s.dslPath()
 	if err := os.WriteFile(mainPath, []byte(update.Content), 0644); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -255,7 +323,7 @@ func (s *server) handlePreviewJSONSync(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("POST /preview-json-sync bytes=%d from %s", len(req.JSON), r.RemoteAddr)
-	mainPath := filepath.Join(s.goDir, dslRelativePath)
+	mainPath := s.dslPath()
 	src, err := os.ReadFile(mainPath)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -295,7 +363,7 @@ func (s *server) handleASTSync(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("POST /sync-ast action=%s node=%s from %s", req.Action, req.NodeID, r.RemoteAddr)
-	mainPath := filepath.Join(s.goDir, dslRelativePath)
+	mainPath := s.dslPath()
 	src, err := os.ReadFile(mainPath)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -326,6 +394,9 @@ func (s *server) handleASTSync(w http.ResponseWriter, r *http.Request) {
 
 func (s *server) handleLayout(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
+	if s.arch.Layout != "" {
+		id = s.arch.Layout
+	}
 	if id == "" {
 		http.Error(w, "Missing architecture id", http.StatusBadRequest)
 		return
@@ -1,26 +1,170 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 	"github.com/sokoide/advent-of-calm-2025/internal/infra/generator"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/parser"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/render"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/report"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/repository"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/schema"
 	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
 )
 
 const (
-	colorGreen = "\033[32m"
-	colorRed   = "\033[31m"
-	colorReset = "\033[0m"
+	colorGreen  = "\033[32m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
 )
 
 func main() {
 	outputFormat := flag.String("format", "json", "Output format: json, d2, rich-d2")
 	runValidation := flag.Bool("validate", false, "Run validation rules")
+	inputPath := flag.String("input", "", "Load a CALM JSON file instead of building the Go DSL")
+	archID := flag.String("arch", "", "Architecture to build (default: the workspace default)")
+	listArchs := flag.Bool("list", false, "List the available architectures and exit")
+	metaSchema := flag.Bool("metadata-schema", false, "Print the metadata vocabulary as JSON Schema and exit")
+	canonical := flag.Bool("canonical", false, "Sort nodes, relationships and flows for byte-stable output")
+	splitDir := flag.String("split", "", "Write each part of a composite architecture to this directory")
+	followDir := flag.String("follow", "",
+		"Write the architecture and every architecture linked through node details to this directory")
+	choices := choiceFlag{}
+	patternPath := flag.String("pattern", "", "Check the architecture against a CALM pattern (JSON Schema) and exit")
+	checkSchema := flag.Bool("schema", true, "Check JSON output before writing it against the CALM 1.1 meta-schema (mapped in url-mapping.json, vendored, or else the embedded structural subset of it)")
+	failOn := flag.String("fail-on", "error", "Lowest validation severity that fails: error, warning or info")
+	detailsLink := flag.String("details-link", "",
+		"Prefix for node details links in d2 and rich-d2 output; the query-escaped reference is appended")
+	reportFormat := flag.String("output", "text", "Validation output with -validate: text, json, sarif or junit")
+	policyPath := flag.String("policy", "",
+		"JSON or YAML policy file with extra validation rules (default: "+repository.PolicyFileName+" or calm-policy.yaml from here upwards)")
+	flag.Var(choices, "choose",
+		"Resolve an options relationship: <options-id>=<description or 1-based index> (repeatable)")
 	flag.Parse()
 
-	gen := generator.DefaultGenerator()
+	ws, err := repository.LoadWorkspace(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *listArchs {
+		printArchitectures(ws)
+		return
+	}
+	if *metaSchema {
+		out, err := json.MarshalIndent(schema.VocabularySchema(domain.MetaKeys()), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	id := *archID
+	if id == "" {
+		id = ws.Default
+	}
+	if id == "" {
+		id = usecase.DefaultArchitectureID
+	}
+	gen, err := generator.ForArchitecture(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *inputPath != "" {
+		arch, err := parser.LoadJSONFile(*inputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		gen.Builder = usecase.StaticBuilder{Architecture: arch}
+		gen.Ref = filepath.Base(*inputPath)
+	}
+	gen.Choices = choices
+	if !*checkSchema {
+		gen.Documents = nil
+	}
+	gen.FailOn = domain.Severity(*failOn)
+	if !gen.FailOn.Valid() || gen.FailOn == "" {
+		fmt.Fprintf(os.Stderr, "Error: -fail-on must be error, warning or info, got %q\n", *failOn)
+		os.Exit(1)
+	}
+	if *reportFormat != "text" {
+		if err := checkReportFormat(report.Format(*reportFormat), *runValidation,
+			*splitDir != "" || *followDir != "" || *patternPath != ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *runValidation {
+		if gen, err = withPolicy(gen, *policyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *detailsLink != "" {
+		link := render.QueryLink(*detailsLink)
+		gen.Renderers[usecase.FormatD2] = render.D2Renderer{DetailsLink: link}
+		gen.Renderers[usecase.FormatRichD2] = render.RichD2Renderer{DetailsLink: link}
+	}
+	if *canonical {
+		for format, r := range gen.Renderers {
+			gen.Renderers[format] = render.CanonicalRenderer{Renderer: r}
+		}
+	}
+
+	if *splitDir != "" {
+		outputs, invalid, err := gen.GenerateParts(usecase.OutputFormat(*outputFormat), *runValidation)
+		if err == nil {
+			err = writeOutputs(outputs, invalid, "Part", usecase.OutputFormat(*outputFormat), *splitDir, gen.FailOn)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *followDir != "" {
+		dir := "."
+		if *inputPath != "" {
+			// Links in the input file are relative to its directory.
+			dir = filepath.Dir(*inputPath)
+		}
+		gen.Details = generator.NewDetailsLoader(dir)
+		outputs, invalid, err := gen.GenerateLinked(usecase.OutputFormat(*outputFormat), *runValidation)
+		if err == nil {
+			err = writeOutputs(outputs, invalid, "Architecture", usecase.OutputFormat(*outputFormat), *followDir,
+				gen.FailOn)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *patternPath != "" {
+		ok, err := checkPattern(gen, *patternPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
 
 	output, validationErrors, err := gen.Generate(usecase.OutputFormat(*outputFormat), *runValidation)
 	if err != nil {
@@ -28,21 +172,209 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *runValidation && *reportFormat != "text" {
+		if err := writeReport(report.Format(*reportFormat), id, validationErrors, gen.FailOn); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(usecase.Failing(validationErrors, gen.FailOn)) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 	if *runValidation {
 		if len(validationErrors) > 0 {
-			printValidationErrors(validationErrors)
-			os.Exit(1)
+			printValidationErrors(validationErrors, gen.FailOn)
+			if len(usecase.Failing(validationErrors, gen.FailOn)) > 0 {
+				os.Exit(1)
+			}
+			return
 		}
 		fmt.Printf("%s✅ All validation rules passed%s\n", colorGreen, colorReset)
 		return
 	}
 
+	if len(usecase.Failing(validationErrors, gen.FailOn)) > 0 {
+		printValidationErrors(validationErrors, gen.FailOn)
+		os.Exit(1)
+	}
 	fmt.Println(output)
 }
 
-func printValidationErrors(errors []usecase.ValidationError) {
-	fmt.Printf("%s❌ Validation failed with %d error(s):%s\n", colorRed, len(errors), colorReset)
+// checkPattern validates the JSON form of the architecture against the
+// pattern at path, resolving $ref through url-mapping.json, and prints the
+// result. It reports whether the architecture conforms.
+func checkPattern(gen usecase.Generator, path string) (bool, error) {
+	out, validationErrors, err := gen.Generate(usecase.FormatJSON, false)
+	if err != nil {
+		return false, err
+	}
+	if len(usecase.Failing(validationErrors, gen.FailOn)) > 0 {
+		printValidationErrors(validationErrors, gen.FailOn)
+		return false, nil
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		return false, err
+	}
+	violations, err := schema.DefaultValidator().ValidateFile(path, doc)
+	if err != nil {
+		return false, err
+	}
+	if len(violations) == 0 {
+		fmt.Printf("%s✅ Architecture conforms to %s%s\n", colorGreen, path, colorReset)
+		return true, nil
+	}
+	fmt.Printf("%s❌ Architecture does not conform to %s: %d violation(s):%s\n",
+		colorRed, path, len(violations), colorReset)
+	for _, v := range violations {
+		pointer := v.Pointer
+		if pointer == "" {
+			pointer = "/"
+		}
+		fmt.Printf("  %s• %s: %s%s\n", colorRed, pointer, v.Message, colorReset)
+	}
+	return false, nil
+}
+
+// withPolicy adds the rules of the policy file at path, or of the nearest
+// calm-policy.json when path is empty, to gen.
+func withPolicy(gen usecase.Generator, path string) (usecase.Generator, error) {
+	if path == "" {
+		var err error
+		if path, err = repository.FindPolicy("."); err != nil || path == "" {
+			return gen, err
+		}
+	}
+	p, err := repository.LoadPolicy(path)
+	if err != nil {
+		return gen, err
+	}
+	gen, err = generator.WithPolicy(gen, p)
+	if err != nil {
+		return gen, fmt.Errorf("%s: %w", path, err)
+	}
+	return gen, nil
+}
+
+// checkReportFormat reports whether format can be used with -output: it needs
+// -validate and a single architecture.
+func checkReportFormat(format report.Format, validate, multi bool) error {
+	if !slices.Contains(report.Formats, format) {
+		return fmt.Errorf("-output must be text, json, sarif or junit, got %q", format)
+	}
+	if !validate {
+		return fmt.Errorf("-output %s requires -validate", format)
+	}
+	if multi {
+		return fmt.Errorf("-output %s cannot be combined with -split, -follow or -pattern", format)
+	}
+	return nil
+}
+
+// writeReport prints the validation errors of architecture id to stdout in
+// format, with DSL source files relative to the working directory.
+func writeReport(format report.Format, id string, errors []usecase.ValidationError, failOn domain.Severity) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format, usecase.NewValidationReport(id, errors, failOn, wd))
+}
+
+// printValidationErrors prints the errors, in red those at failOn or above.
+func printValidationErrors(errors []usecase.ValidationError, failOn domain.Severity) {
+	if failing := len(usecase.Failing(errors, failOn)); failing > 0 {
+		fmt.Printf("%s❌ Validation failed: %d of %d finding(s) at %s or above:%s\n",
+			colorRed, failing, len(errors), failOn, colorReset)
+	} else {
+		fmt.Printf("%s⚠️ Validation passed with %d finding(s) below %s:%s\n",
+			colorYellow, len(errors), failOn, colorReset)
+	}
 	for _, err := range errors {
-		fmt.Printf("  %s• %s%s\n", colorRed, err.String(), colorReset)
+		color := colorYellow
+		if err.Severity.AtLeast(failOn) {
+			color = colorRed
+		}
+		fmt.Printf("  %s• %s%s\n", color, err.String(), colorReset)
+	}
+}
+
+// writeOutputs writes rendered outputs into dir as <name>.<ext>, or prints the
+// validation errors (labelled with kind) and exits when any output is invalid.
+func writeOutputs(
+	outputs map[string]string,
+	invalid map[string][]usecase.ValidationError,
+	kind string,
+	format usecase.OutputFormat,
+	dir string,
+	failOn domain.Severity,
+) error {
+	if len(invalid) > 0 {
+		for _, id := range sortedKeys(invalid) {
+			fmt.Printf("%s %s:\n", kind, id)
+			printValidationErrors(invalid[id], failOn)
+		}
+		os.Exit(1)
+	}
+
+	ext := ".json"
+	if format == usecase.FormatD2 || format == usecase.FormatRichD2 {
+		ext = ".d2"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, id := range sortedKeys(outputs) {
+		path := filepath.Join(dir, id+ext)
+		if err := os.WriteFile(path, []byte(outputs[id]+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s\n", path)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printArchitectures lists registered builders with their workspace DSL files.
+func printArchitectures(ws usecase.Workspace) {
+	dsl := make(map[string]string, len(ws.Architectures))
+	for _, a := range ws.Architectures {
+		dsl[a.ID] = a.DSL
+	}
+	for _, id := range usecase.BuilderIDs() {
+		marker := " "
+		if id == ws.Default {
+			marker = "*"
+		}
+		fmt.Printf("%s %-24s %s\n", marker, id, dsl[id])
+	}
+}
+
+// choiceFlag collects repeated -choose id=choice flags.
+type choiceFlag map[string]string
+
+func (c choiceFlag) String() string {
+	pairs := make([]string, 0, len(c))
+	for id, choice := range c {
+		pairs = append(pairs, id+"="+choice)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (c choiceFlag) Set(v string) error {
+	id, choice, ok := strings.Cut(v, "=")
+	if !ok || id == "" || choice == "" {
+		return fmt.Errorf("expected <options-id>=<choice>, got %q", v)
 	}
+	c[id] = choice
+	return nil
 }
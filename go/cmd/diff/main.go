@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"sort"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 )
 
 const (
@@ -26,23 +29,25 @@ type Architecture struct {
 }
 
 type Node struct {
-	UniqueID string         `json:"unique-id"`
-	Name     string         `json:"name"`
-	NodeType string         `json:"node-type"`
-	Owner    string         `json:"owner"`
-	Metadata map[string]any `json:"metadata"`
-	Controls map[string]any `json:"controls"`
+	UniqueID string          `json:"unique-id"`
+	Name     string          `json:"name"`
+	NodeType string          `json:"node-type"`
+	Owner    string          `json:"owner"`
+	Metadata domain.Metadata `json:"metadata"` // object or array form
+	Controls map[string]any  `json:"controls"`
 }
 
 type Relationship struct {
-	UniqueID    string `json:"unique-id"`
-	Description string `json:"description"`
+	UniqueID    string         `json:"unique-id"`
+	Description string         `json:"description"`
+	Controls    map[string]any `json:"controls"`
 }
 
 type Flow struct {
-	UniqueID    string `json:"unique-id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	UniqueID    string         `json:"unique-id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Controls    map[string]any `json:"controls"`
 }
 
 func main() {
@@ -95,13 +100,16 @@ type ArchDiff struct {
 	NodesModified    []NodeChange
 	RelsAdded        []string
 	RelsRemoved      []string
+	RelsModified     []NodeChange
 	FlowsAdded       []string
 	FlowsRemoved     []string
+	FlowsModified    []NodeChange
 	ControlsAdded    []string
 	ControlsRemoved  []string
 	ControlsModified []string
 }
 
+// NodeChange lists the changes of a node, relationship or flow.
 type NodeChange struct {
 	ID      string
 	Changes []string
@@ -140,43 +148,53 @@ func compareArchitectures(old, new *Architecture) *ArchDiff {
 	}
 
 	// Compare relationships
-	oldRels := make(map[string]bool)
+	oldRels := make(map[string]Relationship)
 	for _, r := range old.Relationships {
-		oldRels[r.UniqueID] = true
+		oldRels[r.UniqueID] = r
 	}
-	newRels := make(map[string]bool)
+	newRels := make(map[string]Relationship)
 	for _, r := range new.Relationships {
-		newRels[r.UniqueID] = true
+		newRels[r.UniqueID] = r
 	}
 
-	for id := range newRels {
-		if !oldRels[id] {
+	for id, newRel := range newRels {
+		oldRel, exists := oldRels[id]
+		if !exists {
 			diff.RelsAdded = append(diff.RelsAdded, id)
+			continue
+		}
+		if changes := compareControls(oldRel.Controls, newRel.Controls); len(changes) > 0 {
+			diff.RelsModified = append(diff.RelsModified, NodeChange{ID: id, Changes: changes})
 		}
 	}
 	for id := range oldRels {
-		if !newRels[id] {
+		if _, exists := newRels[id]; !exists {
 			diff.RelsRemoved = append(diff.RelsRemoved, id)
 		}
 	}
 
 	// Compare flows
-	oldFlows := make(map[string]bool)
+	oldFlows := make(map[string]Flow)
 	for _, f := range old.Flows {
-		oldFlows[f.UniqueID] = true
+		oldFlows[f.UniqueID] = f
 	}
-	newFlows := make(map[string]bool)
+	newFlows := make(map[string]Flow)
 	for _, f := range new.Flows {
-		newFlows[f.UniqueID] = true
+		newFlows[f.UniqueID] = f
 	}
 
-	for id := range newFlows {
-		if !oldFlows[id] {
+	for id, newFlow := range newFlows {
+		oldFlow, exists := oldFlows[id]
+		if !exists {
 			diff.FlowsAdded = append(diff.FlowsAdded, id)
+			continue
+		}
+		if changes := compareControls(oldFlow.Controls, newFlow.Controls); len(changes) > 0 {
+			diff.FlowsModified = append(diff.FlowsModified, NodeChange{ID: id, Changes: changes})
 		}
 	}
 	for id := range oldFlows {
-		if !newFlows[id] {
+		if _, exists := newFlows[id]; !exists {
 			diff.FlowsRemoved = append(diff.FlowsRemoved, id)
 		}
 	}
@@ -200,6 +218,12 @@ func compareArchitectures(old, new *Architecture) *ArchDiff {
 	sort.Strings(diff.RelsRemoved)
 	sort.Strings(diff.FlowsAdded)
 	sort.Strings(diff.FlowsRemoved)
+	byID := func(changes []NodeChange) {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	}
+	byID(diff.NodesModified)
+	byID(diff.RelsModified)
+	byID(diff.FlowsModified)
 
 	return diff
 }
@@ -229,18 +253,27 @@ func compareNodes(old, new Node) []string {
 		}
 	}
 
-	// Compare controls
-	for k := range new.Controls {
-		if _, exists := old.Controls[k]; !exists {
+	return append(changes, compareControls(old.Controls, new.Controls)...)
+}
+
+// compareControls lists added (+), removed (-) and changed (~) controls.
+func compareControls(old, new map[string]any) []string {
+	var changes []string
+	for k, v := range new {
+		oldV, exists := old[k]
+		switch {
+		case !exists:
 			changes = append(changes, fmt.Sprintf("control +%s", k))
+		case !reflect.DeepEqual(oldV, v):
+			changes = append(changes, fmt.Sprintf("control ~%s", k))
 		}
 	}
-	for k := range old.Controls {
-		if _, exists := new.Controls[k]; !exists {
+	for k := range old {
+		if _, exists := new[k]; !exists {
 			changes = append(changes, fmt.Sprintf("control -%s", k))
 		}
 	}
-
+	sort.Strings(changes)
 	return changes
 }
 
@@ -266,12 +299,7 @@ func printDiff(diff *ArchDiff) {
 	if len(diff.NodesModified) > 0 {
 		hasChanges = true
 		fmt.Printf("\n%s%s📦 Nodes Modified:%s\n", colorBold, colorYellow, colorReset)
-		for _, nc := range diff.NodesModified {
-			fmt.Printf("  %s~ %s%s\n", colorYellow, nc.ID, colorReset)
-			for _, c := range nc.Changes {
-				fmt.Printf("      %s\n", c)
-			}
-		}
+		printChanges(diff.NodesModified)
 	}
 
 	if len(diff.RelsAdded) > 0 {
@@ -290,6 +318,12 @@ func printDiff(diff *ArchDiff) {
 		}
 	}
 
+	if len(diff.RelsModified) > 0 {
+		hasChanges = true
+		fmt.Printf("\n%s%s🔗 Relationships Modified:%s\n", colorBold, colorYellow, colorReset)
+		printChanges(diff.RelsModified)
+	}
+
 	if len(diff.FlowsAdded) > 0 {
 		hasChanges = true
 		fmt.Printf("\n%s%s🌊 Flows Added:%s\n", colorBold, colorGreen, colorReset)
@@ -306,6 +340,12 @@ func printDiff(diff *ArchDiff) {
 		}
 	}
 
+	if len(diff.FlowsModified) > 0 {
+		hasChanges = true
+		fmt.Printf("\n%s%s🌊 Flows Modified:%s\n", colorBold, colorYellow, colorReset)
+		printChanges(diff.FlowsModified)
+	}
+
 	if len(diff.ControlsAdded) > 0 {
 		hasChanges = true
 		fmt.Printf("\n%s%s🛡️ Controls Added:%s\n", colorBold, colorGreen, colorReset)
@@ -328,3 +368,12 @@ func printDiff(diff *ArchDiff) {
 		fmt.Println()
 	}
 }
+
+func printChanges(modified []NodeChange) {
+	for _, nc := range modified {
+		fmt.Printf("  %s~ %s%s\n", colorYellow, nc.ID, colorReset)
+		for _, c := range nc.Changes {
+			fmt.Printf("      %s\n", c)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/ast"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/repository"
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
 )
 
 func TestRegenerate_ReadsGoDSL(t *testing.T) {
@@ -47,3 +56,58 @@ func TestRegenerate_ReadsGoDSL(t *testing.T) {
 		t.Errorf("expected %s, got %s", expectedCode, string(data))
 	}
 }
+
+func TestRegenerateWithGoRun_LinksDetailsToDrillPage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	input := filepath.Join(t.TempDir(), "parent.json")
+	doc := `{"unique-id": "parent", "name": "Parent", "description": "d", "nodes": [
+		{"unique-id": "api", "node-type": "service", "name": "API", "description": "d",
+		 "details": {"detailed-architecture": "architectures/api detail.json"}}
+	], "relationships": []}`
+	if err := os.WriteFile(input, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldGoDir := goDir
+	defer func() { goDir = oldGoDir }()
+	var err error
+	if goDir, err = filepath.Abs("../.."); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := archGenCommand(append(richD2Args, "-input", input)...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("arch-gen failed: %v", err)
+	}
+	want := fmt.Sprintf("link: %q", drillLink("architectures/api detail.json"))
+	if !strings.Contains(string(out), want) {
+		t.Errorf("expected %s in\n%s", want, out)
+	}
+}
+
+func TestHandleLayout_UsesActiveLayoutOnlyWithoutID(t *testing.T) {
+	dir := t.TempDir()
+	oldSvc, oldArch := studioSvc, activeArch
+	studioSvc = usecase.NewStudioService(repository.NewFSLayoutRepository(dir), ast.GoASTSyncer{})
+	activeArch = usecase.WorkspaceArchitecture{ID: "shop", Layout: "shop-layout"}
+	defer func() { studioSvc, activeArch = oldSvc, oldArch }()
+
+	for _, tc := range []struct{ query, file string }{
+		{"?id=other", "other.layout.json"},
+		{"", "shop-layout.layout.json"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/layout"+tc.query, strings.NewReader(`{}`))
+		rec := httptest.NewRecorder()
+		handleLayout(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: unexpected status %d: %s", tc.query, rec.Code, rec.Body)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "layout", tc.file)); err != nil {
+			t.Errorf("%q: expected %s to be saved: %v", tc.query, tc.file, err)
+		}
+	}
+}
+
@@ -30,6 +30,8 @@ import { StudioUseCase } from './usecase/studio';
 import Sidebar from './components/Sidebar';
 import DiagramView from './components/DiagramView';
 import CodeEditor from './components/CodeEditor';
+import ValidationPanel from './components/ValidationPanel';
+import type { ValidationReport } from './domain/ports';
 
 type TabType = 'merged' | 'diagram' | 'go' | 'json' | 'd2-diagram' | 'd2-dsl';
 
@@ -49,6 +51,8 @@ function App() {
   const [goCode, setGoCode] = useState('');
   const [d2Code, setD2Code] = useState('');
   const [jsonCode, setJsonCode] = useState('');
+  const [schemaErrors, setSchemaErrors] = useState<string[]>([]);
+  const [validation, setValidation] = useState<ValidationReport | null>(null);
   const [svgCode, setSvgCode] = useState('');
   const [archId, setArchId] = useState('');
   const [showDiff, setShowDiff] = useState(false);
@@ -119,10 +123,12 @@ function App() {
   const fetchData = useCallback(async (isWSUpdate = false) => {
     if (isUpdating.current && !isWSUpdate) return;
     try {
-      const { goCode: remoteGo, d2Code: remoteD2, json, svg } = await studio.fetchContent();
+      const { goCode: remoteGo, d2Code: remoteD2, json, svg, schemaErrors: violations, validation: report } = await studio.fetchContent();
       
       setGoCode(remoteGo);
       setD2Code(remoteD2);
+      setSchemaErrors(violations ?? []);
+      setValidation(report ?? null);
       if (svg) {
         setSvgCode(svg);
       }
@@ -384,6 +390,15 @@ function App() {
                 <Save size={12} /> Apply to Go DSL
               </button>
             </div>
+            {schemaErrors.length > 0 && (
+              <div className="bg-red-950/60 px-4 py-2 border-b border-red-900 text-xs text-red-200 max-h-40 overflow-auto">
+                <div className="font-semibold mb-1">{schemaErrors.length} CALM schema violation(s)</div>
+                <ul className="list-disc pl-4 space-y-0.5 font-mono">
+                  {schemaErrors.map((e) => <li key={e}>{e}</li>)}
+                </ul>
+              </div>
+            )}
+            <ValidationPanel report={validation} />
             <div className="flex-1">
               <CodeEditor value={jsonCode} language="json" onChange={(val) => setJsonCode(val || '')} />
             </div>
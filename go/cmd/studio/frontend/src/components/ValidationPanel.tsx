@@ -0,0 +1,30 @@
+import type { ValidationReport } from '../domain/ports';
+
+interface ValidationPanelProps {
+  report: ValidationReport | null;
+}
+
+const ValidationPanel = ({ report }: ValidationPanelProps) => {
+  if (!report || report.results.length === 0) return null;
+
+  const failing = report.results.filter((r) => r.failing).length;
+  const title = report.passed
+    ? `Validation passed with ${report.results.length} finding(s) below ${report.failOn}`
+    : `Validation failed with ${failing} error(s)`;
+
+  return (
+    <div className={`px-4 py-2 border-b text-xs max-h-40 overflow-auto ${report.passed ? 'bg-amber-950/60 border-amber-900 text-amber-200' : 'bg-red-950/60 border-red-900 text-red-200'}`}>
+      <div className="font-semibold mb-1">{title}</div>
+      <ul className="list-disc pl-4 space-y-0.5 font-mono">
+        {report.results.map((r, i) => (
+          <li key={`${r.ruleId}-${r.elementId ?? ''}-${i}`} className={r.failing ? '' : 'opacity-80'}>
+            [{r.ruleId} {r.severity}]{r.elementId && ` ${r.elementId}:`} {r.message}
+            {r.location && <span className="text-slate-400"> ({r.location.file}:{r.location.line})</span>}
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+};
+
+export default ValidationPanel;
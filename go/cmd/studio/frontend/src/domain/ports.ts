@@ -5,6 +5,30 @@ export interface ContentSnapshot {
   d2Code: string;
   svg: string;
   json: string;
+  schemaErrors?: string[];
+  validation?: ValidationReport;
+}
+
+// ValidationReport mirrors usecase.ValidationReport, the output of
+// arch-gen -validate -output json.
+export interface ValidationReport {
+  architecture: string;
+  failOn: ValidationSeverity;
+  passed: boolean;
+  results: ValidationResult[];
+}
+
+export type ValidationSeverity = 'error' | 'warning' | 'info';
+
+export interface ValidationResult {
+  ruleId: string;
+  rule: string;
+  severity: ValidationSeverity;
+  failing: boolean;
+  kind?: 'node' | 'relationship' | 'flow';
+  elementId?: string;
+  message: string;
+  location?: { file: string; line: number };
 }
 
 export interface SyncASTRequest {
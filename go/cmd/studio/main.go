@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"html"
 	"io/fs"
 	"log"
 	"net/http"
@@ -20,6 +21,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/sokoide/advent-of-calm-2025/internal/infra/generator"
 	"github.com/sokoide/advent-of-calm-2025/internal/infra/ast"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/render"
 	"github.com/sokoide/advent-of-calm-2025/internal/infra/repository"
 	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
 )
@@ -31,24 +33,33 @@ var (
 		CheckOrigin: func(r *http.Request) bool { return true },
 	}
 	goDir       string
+	workspace   usecase.Workspace
+	activeArch  usecase.WorkspaceArchitecture
 	studioSvc   usecase.StudioService
 	lastContent struct {
-		GoCode string `json:"goCode"`
-		D2Code string `json:"d2Code"`
-		SVG    string `json:"svg"`
-		JSON   string `json:"json"`
+		GoCode       string   `json:"goCode"`
+		D2Code       string   `json:"d2Code"`
+		SVG          string   `json:"svg"`
+		JSON         string   `json:"json"`
+		SchemaErrors []string `json:"schemaErrors"`
+		// Validation is the result of the validation rules, as printed by
+		// arch-gen -validate -output json.
+		Validation *usecase.ValidationReport `json:"validation,omitempty"`
 	}
 	contentMu sync.RWMutex
 	modeHint  sync.Once
+	// dslRelativePath is the DSL file of the active architecture, relative to goDir.
+	dslRelativePath = usecase.DefaultWorkspace().Architectures[0].DSL
 )
 
 const (
-	dslRelativePath   = "internal/usecase/ecommerce_architecture.go"
 	generateModeEnv   = "STUDIO_GENERATE_MODE"
 	generateModeGoRun = "gorun"
 )
 
 func main() {
+	archID := flag.String("arch", "", "Architecture to edit (default: the workspace default)")
+	listArchs := flag.Bool("list", false, "List the workspace architectures and exit")
 	flag.Parse()
 
 	// Ensure we have an absolute path for goDir
@@ -65,7 +76,23 @@ func main() {
 		}
 	}
 
-	log.Printf("🚀 Starting Studio in: %s", goDir)
+	workspace, err = repository.LoadWorkspace(goDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *listArchs {
+		for _, a := range workspace.Architectures {
+			fmt.Printf("%-24s %s\n", a.ID, a.DSL)
+		}
+		return
+	}
+	activeArch, err = workspace.Select(*archID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dslRelativePath = activeArch.DSL
+
+	log.Printf("🚀 Starting Studio in: %s (architecture: %s)", goDir, activeArch.ID)
 	layoutRepo := repository.NewFSLayoutRepository(filepath.Join(goDir, "architectures"))
 	studioSvc = usecase.NewStudioService(layoutRepo, ast.GoASTSyncer{})
 
@@ -101,9 +128,11 @@ func main() {
 	http.HandleFunc("/update", withCORS(handleUpdate))
 	http.HandleFunc("/d2-to-go", withCORS(handleD2ToGo))
 	http.HandleFunc("/layout", withCORS(handleLayout))
+	http.HandleFunc("/architectures", withCORS(serveArchitectures))
 	http.HandleFunc("/sync-ast", withCORS(handleASTSync))
 	http.HandleFunc("/preview-json-sync", withCORS(handlePreviewJSONSync))
 	http.HandleFunc("/svg", withCORS(serveSVG))
+	http.HandleFunc("/drill", handleDrill)
 
 	port := "3000"
 	fmt.Printf("🎨 CALM Studio running at http://localhost:%s\n", port)
@@ -214,13 +243,23 @@ func regenerate() bool {
 }
 
 func regenerateInProcess() bool {
-	gen := generator.DefaultGenerator()
+	gen, err := generator.ForArchitecture(activeArch.ID)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return false
+	}
+	gen.Renderers[usecase.FormatRichD2] = render.RichD2Renderer{DetailsLink: drillLink}
+	// Preview non-conforming documents too; their violations are shown instead.
+	checker := gen
+	gen.Documents = nil
 
 	jsonOutput, _, err := gen.Generate(usecase.FormatJSON, false)
 	if err != nil {
 		log.Printf("❌ JSON generation error: %v", err)
 		return false
 	}
+	schemaErrors := checker.DocumentMessages(jsonOutput)
+	logSchemaErrors(schemaErrors)
 
 	d2Output, _, err := gen.Generate(usecase.FormatRichD2, false)
 	if err != nil {
@@ -229,11 +268,14 @@ func regenerateInProcess() bool {
 	}
 
 	svg := generateSVGFromD2(d2Output)
+	validation := validateInProcess(gen)
 
 	contentMu.Lock()
 	lastContent.D2Code = d2Output
 	lastContent.SVG = svg
 	lastContent.JSON = jsonOutput
+	lastContent.SchemaErrors = schemaErrors
+	lastContent.Validation = validation
 	contentMu.Unlock()
 
 	log.Println("✅ Content updated (in-process)")
@@ -242,8 +284,7 @@ func regenerateInProcess() bool {
 
 func regenerateWithGoRun() bool {
 	// 2. Get JSON output
-	cmdJSON := exec.Command("go", "run", "./cmd/arch-gen")
-	cmdJSON.Dir = goDir
+	cmdJSON := archGenCommand("-schema=false")
 	var jsonOut, jsonErr bytes.Buffer
 	cmdJSON.Stdout = &jsonOut
 	cmdJSON.Stderr = &jsonErr
@@ -254,8 +295,7 @@ func regenerateWithGoRun() bool {
 	}
 
 	// 3. Get Rich D2 output
-	cmdD2 := exec.Command("go", "run", "./cmd/arch-gen", "-format", "rich-d2")
-	cmdD2.Dir = goDir
+	cmdD2 := archGenCommand(richD2Args...)
 	var d2Out, d2Err bytes.Buffer
 	cmdD2.Stdout = &d2Out
 	cmdD2.Stderr = &d2Err
@@ -265,17 +305,82 @@ func regenerateWithGoRun() bool {
 	}
 
 	svg := generateSVGFromD2(d2Out.String())
+	validation := validateWithGoRun()
+	schemaErrors := generator.DefaultGenerator().DocumentMessages(jsonOut.String())
+	logSchemaErrors(schemaErrors)
 
 	contentMu.Lock()
 	lastContent.D2Code = d2Out.String()
 	lastContent.SVG = svg
 	lastContent.JSON = jsonOut.String()
+	lastContent.SchemaErrors = schemaErrors
+	lastContent.Validation = validation
 	contentMu.Unlock()
 
 	log.Println("✅ Content updated (go run)")
 	return true
 }
 
+// archGenCommand runs arch-gen on the active architecture from the DSL
+// sources in goDir.
+func archGenCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("go", append([]string{"run", "./cmd/arch-gen", "-arch", activeArch.ID}, args...)...)
+	cmd.Dir = goDir
+	return cmd
+}
+
+// richD2Args makes arch-gen point details links at the drill-down page, as
+// drillLink does in-process.
+var richD2Args = []string{"-format", "rich-d2", "-details-link", drillLinkPrefix}
+
+// logSchemaErrors logs how many schema violations the generated JSON has.
+func logSchemaErrors(messages []string) {
+	if len(messages) > 0 {
+		log.Printf("⚠️ %d CALM schema violation(s)", len(messages))
+	}
+}
+
+// validateInProcess runs the validation rules of gen and of the workspace
+// policy, if any, over the compiled Go DSL.
+func validateInProcess(gen usecase.Generator) *usecase.ValidationReport {
+	if path, err := repository.FindPolicy(goDir); err == nil && path != "" {
+		p, err := repository.LoadPolicy(path)
+		if err == nil {
+			var withPolicy usecase.Generator
+			if withPolicy, err = generator.WithPolicy(gen, p); err == nil {
+				gen = withPolicy
+			}
+		}
+		if err != nil {
+			log.Printf("⚠️ Ignoring policy %s: %v", path, err)
+		}
+	}
+	errs, err := gen.Validate()
+	if err != nil {
+		log.Printf("❌ Validation error: %v", err)
+		return nil
+	}
+	report := usecase.NewValidationReport(activeArch.ID, errs, gen.FailOn, goDir)
+	return &report
+}
+
+// validateWithGoRun runs arch-gen -validate -output json; it exits with 1 when
+// validation fails, so only output that is not a report counts as an error.
+func validateWithGoRun() *usecase.ValidationReport {
+	cmd := archGenCommand("-validate", "-output", "json")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var report usecase.ValidationReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		log.Printf("❌ Validation error: %v\n%s", runErr, stderr.String())
+		return nil
+	}
+	return &report
+}
+
 func generateSVGFromD2(d2Source string) string {
 	if strings.TrimSpace(d2Source) == "" {
 		return ""
@@ -517,8 +622,62 @@ func handleASTSync(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// drillLinkPrefix is the studio drill-down page; the details reference is
+// appended as a query parameter.
+const drillLinkPrefix = "/drill?ref="
+
+// drillLink points a node's details link at the studio drill-down page.
+var drillLink = render.QueryLink(drillLinkPrefix)
+
+// handleDrill renders the architecture a node's details link refers to, so
+// clicking a node in the SVG drills down into its detailed architecture.
+func handleDrill(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		http.Error(w, "ref is required", http.StatusBadRequest)
+		return
+	}
+
+	loader := generator.NewDetailsLoader(goDir)
+	arch, err := loader.Load(ref)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// Links of a drilled-down file are relative to that file.
+	link := func(child string) string { return drillLink(loader.ResolveRef(ref, child)) }
+	d2Output, err := render.RichD2Renderer{DetailsLink: link}.Render(arch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	svg := generateSVGFromD2(d2Output)
+	if svg == "" {
+		http.Error(w, "failed to render SVG for "+ref, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><html><head><title>%s</title></head><body>", html.EscapeString(arch.Name))
+	fmt.Fprintf(w, "<p><a href=\"javascript:history.back()\">&larr; Back</a> %s</p>", html.EscapeString(ref))
+	fmt.Fprint(w, svg)
+	fmt.Fprint(w, "</body></html>")
+}
+
+// serveArchitectures lists the workspace architectures and the one being edited.
+func serveArchitectures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"active":        activeArch.ID,
+		"architectures": workspace.Architectures,
+	})
+}
+
 func handleLayout(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
+	if id == "" {
+		id = activeArch.Layout
+	}
 	if id == "" {
 		http.Error(w, "Missing architecture id", http.StatusBadRequest)
 		return
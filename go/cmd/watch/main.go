@@ -16,6 +16,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/gorilla/websocket"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/repository"
 )
 
 var (
@@ -27,10 +28,13 @@ var (
 	lastContent   string
 	lastContentMu sync.RWMutex
 	d2Mode        bool
+	archID        string
 )
 
 func main() {
 	d2Flag := flag.Bool("d2", false, "Use D2 format instead of Mermaid")
+	archFlag := flag.String("arch", "", "Architecture to watch (default: the workspace default)")
+	listArchs := flag.Bool("list", false, "List the workspace architectures and exit")
 	flag.Parse()
 	d2Mode = *d2Flag
 
@@ -39,6 +43,22 @@ func main() {
 		goDir = flag.Arg(0)
 	}
 
+	ws, err := repository.LoadWorkspace(goDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *listArchs {
+		for _, a := range ws.Architectures {
+			fmt.Printf("%-24s %s\n", a.ID, a.DSL)
+		}
+		return
+	}
+	arch, err := ws.Select(*archFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	archID = arch.ID
+
 	// Initial generation
 	regenerate(goDir)
 
@@ -55,7 +75,7 @@ func main() {
 	if d2Mode {
 		mode = "D2"
 	}
-	fmt.Printf("🚀 Live Server (%s) running at http://localhost:%s\n", mode, port)
+	fmt.Printf("🚀 Live Server (%s, %s) running at http://localhost:%s\n", mode, archID, port)
 	fmt.Printf("📁 Watching: %s/internal and %s/cmd/arch-gen\n", goDir, goDir)
 	fmt.Println("💡 Edit your Go files and see changes instantly!")
 
@@ -126,12 +146,11 @@ func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
 }
 
 func regenerate(dir string) bool {
-	var cmd *exec.Cmd
+	args := []string{"run", "./cmd/arch-gen", "-arch", archID}
 	if d2Mode {
-		cmd = exec.Command("go", "run", "./cmd/arch-gen", "-format", "d2")
-	} else {
-		cmd = exec.Command("go", "run", "./cmd/arch-gen")
+		args = append(args, "-format", "d2")
 	}
+	cmd := exec.Command("go", args...)
 	cmd.Dir = dir
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -5,7 +5,6 @@ go 1.25.5
 require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/gorilla/websocket v1.5.3
-	golang.org/x/text v0.32.0
 )
 
 require golang.org/x/sys v0.13.0 // indirect
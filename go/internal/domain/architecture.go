@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 type NodeType string
 
 const (
@@ -9,6 +14,10 @@ const (
 	System    NodeType = "system"
 	Queue     NodeType = "queue"
 	WebClient NodeType = "webclient"
+	Ecosystem NodeType = "ecosystem"
+	Network   NodeType = "network"
+	LDAP      NodeType = "ldap"
+	DataAsset NodeType = "data-asset"
 )
 
 type Architecture struct {
@@ -17,14 +26,19 @@ type Architecture struct {
 	UniqueID      string              `json:"unique-id"`
 	Name          string              `json:"name"`
 	Description   string              `json:"description"`
-	Metadata      map[string]any      `json:"metadata,omitempty"`
+	Metadata      Metadata            `json:"metadata,omitempty"`
 	Controls      map[string]*Control `json:"controls,omitempty"`
 	Flows         []*Flow             `json:"flows,omitempty"`
 	Nodes         []*Node             `json:"nodes"`
 	Relationships []*Relationship     `json:"relationships"`
-}
 
-type Metadata map[string]any
+	MetadataLayout MetadataLayout `json:"-"`
+
+	// errs and origins back Err and Origin: builder errors and where each
+	// node, relationship and flow was defined.
+	errs    []error
+	origins map[any]SourcePos
+}
 
 type Control struct {
 	Description  string        `json:"description"`
@@ -38,18 +52,21 @@ type Requirement struct {
 }
 
 type Flow struct {
-	UniqueID    string         `json:"unique-id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
-	Transitions []Transition   `json:"transitions"`
+	UniqueID    string              `json:"unique-id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Metadata    Metadata            `json:"metadata,omitempty"`
+	Controls    map[string]*Control `json:"controls,omitempty"`
+	Transitions []Transition        `json:"transitions"`
+
+	MetadataLayout MetadataLayout `json:"-"`
 }
 
 type Transition struct {
-	RelationshipID string `json:"relationship-unique-id"`
-	SequenceNumber int    `json:"sequence-number"`
-	Description    string `json:"description"`
-	Direction      string `json:"direction"`
+	RelationshipID string        `json:"relationship-unique-id"`
+	SequenceNumber int           `json:"sequence-number"`
+	Description    string        `json:"description"`
+	Direction      FlowDirection `json:"direction"`
 }
 
 type Node struct {
@@ -60,9 +77,19 @@ type Node struct {
 	Description string              `json:"description"`
 	CostCenter  string              `json:"costCenter,omitempty"`
 	Owner       string              `json:"owner,omitempty"`
-	Metadata    map[string]any      `json:"metadata,omitempty"`
+	Metadata    Metadata            `json:"metadata,omitempty"`
 	Controls    map[string]*Control `json:"controls,omitempty"`
 	Interfaces  []Interface         `json:"interfaces,omitempty"`
+	Details     *NodeDetails        `json:"details,omitempty"`
+
+	MetadataLayout MetadataLayout `json:"-"`
+}
+
+// NodeDetails links a node to the architecture that describes its internals
+// and, optionally, the pattern that architecture must conform to.
+type NodeDetails struct {
+	DetailedArchitecture string `json:"detailed-architecture,omitempty"`
+	RequiredPattern      string `json:"required-pattern,omitempty"`
 }
 
 // ConnectionBuilder helps construct relationships fluently
@@ -70,25 +97,36 @@ type ConnectionBuilder struct {
 	rel *Relationship
 }
 
+// Interface is either a flat interface (protocol, host, port, ...) or a CALM
+// interface definition: a definition-url naming the JSON schema of the
+// interface plus a config object that must conform to it.
 type Interface struct {
 	UniqueID    string `json:"unique-id"`
 	Name        string `json:"name,omitempty"`
-	Protocol    string `json:"protocol"`
+	Protocol    string `json:"protocol,omitempty"`
 	Port        int    `json:"port,omitempty"`
 	Host        string `json:"host,omitempty"`
 	Path        string `json:"path,omitempty"`
 	Description string `json:"description,omitempty"`
 	Database    string `json:"database,omitempty"`
+	// DefinitionURL points to the JSON schema of the interface definition.
+	DefinitionURL string `json:"definition-url,omitempty"`
+	// Config is the interface configuration; any JSON-encodable value, such as
+	// a map or a struct with json tags.
+	Config any `json:"config,omitempty"`
 }
 
 type Relationship struct {
-	UniqueID           string           `json:"unique-id"`
-	Description        string           `json:"description"`
-	DataClassification string           `json:"dataClassification,omitempty"`
-	Encrypted          *bool            `json:"encrypted,omitempty"`
-	Protocol           string           `json:"protocol,omitempty"`
-	Metadata           map[string]any   `json:"metadata,omitempty"`
-	RelationshipType   RelationshipType `json:"relationship-type"`
+	UniqueID           string              `json:"unique-id"`
+	Description        string              `json:"description"`
+	DataClassification string              `json:"dataClassification,omitempty"`
+	Encrypted          *bool               `json:"encrypted,omitempty"`
+	Protocol           string              `json:"protocol,omitempty"`
+	Metadata           Metadata            `json:"metadata,omitempty"`
+	Controls           map[string]*Control `json:"controls,omitempty"`
+	RelationshipType   RelationshipType    `json:"relationship-type"`
+
+	MetadataLayout MetadataLayout `json:"-"`
 }
 
 type NodeInterface struct {
@@ -101,8 +139,73 @@ type Connects struct {
 	Destination NodeInterface `json:"destination"`
 }
 
+// Interacts describes an actor interacting with one or more nodes.
+type Interacts struct {
+	Actor string   `json:"actor"`
+	Nodes []string `json:"nodes"`
+}
+
+// ComposedOf describes a container node made up of other nodes.
+type ComposedOf struct {
+	Container string   `json:"container"`
+	Nodes     []string `json:"nodes"`
+}
+
+// DeployedIn describes nodes deployed into a container such as a cluster.
+type DeployedIn struct {
+	Container string   `json:"container"`
+	Nodes     []string `json:"nodes"`
+}
+
+// Decision is one alternative of an options relationship: choosing it keeps the
+// listed nodes and relationships in the architecture.
+type Decision struct {
+	Description   string   `json:"description"`
+	Nodes         []string `json:"nodes"`
+	Relationships []string `json:"relationships"`
+}
+
 type RelationshipType struct {
-	Connects   *Connects      `json:"connects,omitempty"`
-	Interacts  map[string]any `json:"interacts,omitempty"`
-	ComposedOf map[string]any `json:"composed-of,omitempty"`
+	Connects   *Connects   `json:"connects,omitempty"`
+	Interacts  *Interacts  `json:"interacts,omitempty"`
+	ComposedOf *ComposedOf `json:"composed-of,omitempty"`
+	DeployedIn *DeployedIn `json:"deployed-in,omitempty"`
+	Options    []Decision  `json:"options,omitempty"`
+}
+
+// UnmarshalJSON rejects unknown keys so that a misspelled field fails to decode
+// instead of silently dropping the relationship from diagrams and rules.
+func (c *Connects) UnmarshalJSON(data []byte) error {
+	type plain Connects
+	return decodeStrict(data, (*plain)(c))
+}
+
+// UnmarshalJSON rejects unknown keys; see Connects.UnmarshalJSON.
+func (i *Interacts) UnmarshalJSON(data []byte) error {
+	type plain Interacts
+	return decodeStrict(data, (*plain)(i))
+}
+
+// UnmarshalJSON rejects unknown keys; see Connects.UnmarshalJSON.
+func (c *ComposedOf) UnmarshalJSON(data []byte) error {
+	type plain ComposedOf
+	return decodeStrict(data, (*plain)(c))
+}
+
+// UnmarshalJSON rejects unknown keys; see Connects.UnmarshalJSON.
+func (d *DeployedIn) UnmarshalJSON(data []byte) error {
+	type plain DeployedIn
+	return decodeStrict(data, (*plain)(d))
+}
+
+// UnmarshalJSON rejects unknown keys; see Connects.UnmarshalJSON.
+func (d *Decision) UnmarshalJSON(data []byte) error {
+	type plain Decision
+	return decodeStrict(data, (*plain)(d))
+}
+
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
 }
@@ -9,8 +9,12 @@ func NewArchitecture(id, name, desc string) *Architecture {
 		UniqueID:    id,
 		Name:        name,
 		Description: desc,
-		Controls:    make(map[string]*Control),
-		Metadata:    make(map[string]any),
+		// Empty rather than nil, so that an architecture without nodes or
+		// relationships still renders the arrays the schema requires.
+		Nodes:         []*Node{},
+		Relationships: []*Relationship{},
+		Controls:      make(map[string]*Control),
+		Metadata:      make(map[string]any),
 	}
 }
 
@@ -34,6 +38,7 @@ func (a *Architecture) Node(id string, ntype NodeType, name, desc string) *Node
 		Metadata:    make(map[string]any),
 		Controls:    make(map[string]*Control),
 	}
+	a.track(n)
 	a.Nodes = append(a.Nodes, n)
 	return n
 }
@@ -46,11 +51,20 @@ func (n *Node) Standard(cc, owner string) *Node {
 }
 
 func (n *Node) Interface(id, protocol string) *Interface {
-	i := Interface{UniqueID: id, Protocol: protocol}
-	n.Interfaces = append(n.Interfaces, i)
+	n.addInterface(Interface{UniqueID: id, Protocol: protocol})
 	return &n.Interfaces[len(n.Interfaces)-1]
 }
 
+// FindInterface returns the interface of n with the given ID.
+func (n *Node) FindInterface(id string) (Interface, bool) {
+	for _, intf := range n.Interfaces {
+		if intf.UniqueID == id {
+			return intf, true
+		}
+	}
+	return Interface{}, false
+}
+
 func (n *Node) AddMeta(k string, v any) *Node {
 	n.Metadata[k] = v
 	return n
@@ -69,6 +83,15 @@ func (i *Interface) SetName(n string) *Interface { i.Name = n; return i }
 func (i *Interface) SetDesc(d string) *Interface { i.Description = d; return i }
 func (i *Interface) SetDB(d string) *Interface   { i.Database = d; return i }
 
+// Definition turns the interface into a CALM interface definition: url names
+// the interface schema (resolved through url-mapping.json) and cfg is its
+// configuration.
+func (i *Interface) Definition(url string, cfg any) *Interface {
+	i.DefinitionURL = url
+	i.Config = cfg
+	return i
+}
+
 // --- Metadata ---
 func NewMetadata() Metadata {
 	return make(Metadata)
@@ -81,9 +104,10 @@ func (a *Architecture) Interacts(id, desc, actor, node string) *Relationship {
 		Description: desc,
 		Metadata:    make(map[string]any),
 		RelationshipType: RelationshipType{
-			Interacts: map[string]any{"actor": actor, "nodes": []string{node}},
+			Interacts: &Interacts{Actor: actor, Nodes: []string{node}},
 		},
 	}
+	a.track(r)
 	a.Relationships = append(a.Relationships, r)
 	return r
 }
@@ -100,6 +124,7 @@ func (a *Architecture) Connect(id, desc, src, dst string) *Relationship {
 			},
 		},
 	}
+	a.track(r)
 	a.Relationships = append(a.Relationships, r)
 	return r
 }
@@ -126,27 +151,92 @@ func (r *Relationship) AddMeta(k string, v any) *Relationship {
 	return r
 }
 
+// AddControl attaches a control to the relationship, e.g. an mTLS requirement
+// for the connection.
+func (r *Relationship) AddControl(id string, desc string, reqs ...Requirement) *Relationship {
+	if r.Controls == nil {
+		r.Controls = make(map[string]*Control)
+	}
+	r.Controls[id] = &Control{Description: desc, Requirements: reqs}
+	return r
+}
+
 // --- ComposedOf ---
 func (a *Architecture) ComposedOf(id, desc, container string, nodes []string) *Relationship {
 	r := &Relationship{
 		UniqueID:    id,
 		Description: desc,
 		RelationshipType: RelationshipType{
-			ComposedOf: map[string]any{"container": container, "nodes": nodes},
+			ComposedOf: &ComposedOf{Container: container, Nodes: nodes},
 		},
 	}
+	a.track(r)
 	a.Relationships = append(a.Relationships, r)
 	return r
 }
 
+// --- DeployedIn ---
+func (a *Architecture) DeployedIn(id, desc, container string, nodes []string) *Relationship {
+	r := &Relationship{
+		UniqueID:    id,
+		Description: desc,
+		Metadata:    make(map[string]any),
+		RelationshipType: RelationshipType{
+			DeployedIn: &DeployedIn{Container: container, Nodes: nodes},
+		},
+	}
+	a.track(r)
+	a.Relationships = append(a.Relationships, r)
+	return r
+}
+
+// --- Options ---
+
+// OptionsBuilder helps declare the alternatives of an options relationship.
+type OptionsBuilder struct {
+	rel *Relationship
+}
+
+// DefineOptions declares a decision point whose alternatives are added with Option.
+func (a *Architecture) DefineOptions(id, desc string) *OptionsBuilder {
+	r := &Relationship{
+		UniqueID:    id,
+		Description: desc,
+		Metadata:    make(map[string]any),
+		RelationshipType: RelationshipType{
+			Options: []Decision{},
+		},
+	}
+	a.track(r)
+	a.Relationships = append(a.Relationships, r)
+	return &OptionsBuilder{rel: r}
+}
+
+// Option adds an alternative made up of the given nodes and relationships.
+func (ob *OptionsBuilder) Option(desc string, nodes, rels []string) *OptionsBuilder {
+	ob.rel.RelationshipType.Options = append(ob.rel.RelationshipType.Options, Decision{
+		Description:   desc,
+		Nodes:         nodes,
+		Relationships: rels,
+	})
+	return ob
+}
+
+// GetID returns the options relationship ID.
+func (ob *OptionsBuilder) GetID() string {
+	return ob.rel.UniqueID
+}
+
 // AddRelationship appends a fully-defined relationship to the architecture.
 func (a *Architecture) AddRelationship(rel *Relationship) {
+	a.track(rel)
 	a.Relationships = append(a.Relationships, rel)
 }
 
 // --- Flows ---
 func (a *Architecture) Flow(id, name, desc string) *Flow {
 	f := &Flow{UniqueID: id, Name: name, Description: desc, Metadata: make(map[string]any)}
+	a.track(f)
 	a.Flows = append(a.Flows, f)
 	return f
 }
@@ -156,7 +246,16 @@ func (f *Flow) AddMeta(k string, v any) *Flow {
 	return f
 }
 
-func (f *Flow) Step(relID string, seq int, desc string, dir string) *Flow {
+// AddControl attaches a control to the flow, e.g. an audit requirement.
+func (f *Flow) AddControl(id string, desc string, reqs ...Requirement) *Flow {
+	if f.Controls == nil {
+		f.Controls = make(map[string]*Control)
+	}
+	f.Controls[id] = &Control{Description: desc, Requirements: reqs}
+	return f
+}
+
+func (f *Flow) Step(relID string, seq int, desc string, dir FlowDirection) *Flow {
 	f.Transitions = append(f.Transitions, Transition{
 		RelationshipID: relID, SequenceNumber: seq, Description: desc, Direction: dir,
 	})
@@ -181,6 +280,23 @@ func Merge(maps ...map[string]any) map[string]any {
 	return res
 }
 
+// Merge combines metadata maps like the package-level Merge, but records key
+// collisions as builder errors (see Err) instead of panicking; the first value
+// of a colliding key is kept.
+func (a *Architecture) Merge(maps ...map[string]any) map[string]any {
+	res := make(map[string]any)
+	for _, m := range maps {
+		for _, k := range sortedKeys(m) {
+			if _, exists := res[k]; exists {
+				a.fail("metadata collision: key %q defined multiple times", k)
+				continue
+			}
+			res[k] = m[k]
+		}
+	}
+	return res
+}
+
 func NewRequirement(url string, config any) Requirement {
 	return Requirement{RequirementURL: url, Config: config}
 }
@@ -260,12 +376,21 @@ func WithInterfaces(interfaces ...*Interface) NodeOption {
 	return func(n *Node) {
 		for _, iface := range interfaces {
 			if iface != nil {
-				n.Interfaces = append(n.Interfaces, *iface)
+				n.addInterface(*iface)
 			}
 		}
 	}
 }
 
+// WithDetails links the node to its detailed architecture and, optionally,
+// the pattern that architecture must follow. detailedArch may be a registered
+// builder ID, a URL listed in url-mapping.json or a local JSON file.
+func WithDetails(detailedArch, requiredPattern string) NodeOption {
+	return func(n *Node) {
+		n.Details = &NodeDetails{DetailedArchitecture: detailedArch, RequiredPattern: requiredPattern}
+	}
+}
+
 // WithTags adds tags to the metadata.
 func WithTags(tags ...string) NodeOption {
 	return func(n *Node) {
@@ -292,6 +417,7 @@ func (a *Architecture) DefineNode(id string, ntype NodeType, name, desc string,
 		Metadata:    make(map[string]any),
 		Controls:    make(map[string]*Control),
 	}
+	a.track(n)
 	for _, opt := range opts {
 		opt(n)
 	}
@@ -315,11 +441,30 @@ func (n *Node) ConnectTo(dest *Node, desc string) *ConnectionBuilder {
 	}
 	// Auto-register with the architecture
 	if n.Arch != nil {
+		n.Arch.track(rel)
 		n.Arch.Relationships = append(n.Arch.Relationships, rel)
 	}
 	return &ConnectionBuilder{rel: rel}
 }
 
+// DeployedIn records that this node runs inside the given container (e.g. a cluster).
+// The relationship ID defaults to "<node>-deployed-in-<container>".
+func (n *Node) DeployedIn(container *Node) *Relationship {
+	rel := &Relationship{
+		UniqueID:    fmt.Sprintf("%s-deployed-in-%s", n.UniqueID, container.UniqueID),
+		Description: fmt.Sprintf("%s is deployed in %s", n.Name, container.Name),
+		Metadata:    make(map[string]any),
+		RelationshipType: RelationshipType{
+			DeployedIn: &DeployedIn{Container: container.UniqueID, Nodes: []string{n.UniqueID}},
+		},
+	}
+	if n.Arch != nil {
+		n.Arch.track(rel)
+		n.Arch.Relationships = append(n.Arch.Relationships, rel)
+	}
+	return rel
+}
+
 // WithID overrides the auto-generated relationship ID.
 func (cb *ConnectionBuilder) WithID(id string) *ConnectionBuilder {
 	cb.rel.UniqueID = id
@@ -359,6 +504,12 @@ func (cb *ConnectionBuilder) Protocol(p string) *ConnectionBuilder {
 	return cb
 }
 
+// Control attaches a control to the connection.
+func (cb *ConnectionBuilder) Control(id, desc string, reqs ...Requirement) *ConnectionBuilder {
+	cb.rel.AddControl(id, desc, reqs...)
+	return cb
+}
+
 // Is sets the data classification.
 func (cb *ConnectionBuilder) Is(classification string) *ConnectionBuilder {
 	cb.rel.DataClassification = classification
@@ -378,9 +529,10 @@ func (a *Architecture) FlowFromIds(id, name, desc string, relIDs ...string) *Flo
 			RelationshipID: rid,
 			SequenceNumber: i + 1,
 			Description:    "Step " + rid,
-			Direction:      "source-to-destination",
+			Direction:      SourceToDestination,
 		})
 	}
+	a.track(f)
 	a.Flows = append(a.Flows, f)
 	return f
 }
@@ -392,6 +544,7 @@ type FlowBuilder struct {
 
 func (a *Architecture) DefineFlow(id, name, desc string) *FlowBuilder {
 	f := &Flow{UniqueID: id, Name: name, Description: desc, Metadata: make(map[string]any)}
+	a.track(f)
 	a.Flows = append(a.Flows, f)
 	return &FlowBuilder{flow: f}
 }
@@ -401,12 +554,12 @@ func (fb *FlowBuilder) Step(relID string, desc string) *FlowBuilder {
 		RelationshipID: relID,
 		SequenceNumber: len(fb.flow.Transitions) + 1,
 		Description:    desc,
-		Direction:      "source-to-destination",
+		Direction:      SourceToDestination,
 	})
 	return fb
 }
 
-func (fb *FlowBuilder) StepEx(relID, desc, dir string) *FlowBuilder {
+func (fb *FlowBuilder) StepEx(relID, desc string, dir FlowDirection) *FlowBuilder {
 	fb.flow.Transitions = append(fb.flow.Transitions, Transition{
 		RelationshipID: relID,
 		SequenceNumber: len(fb.flow.Transitions) + 1,
@@ -421,6 +574,12 @@ func (fb *FlowBuilder) Meta(k string, v any) *FlowBuilder {
 	return fb
 }
 
+// Control attaches a control to the flow.
+func (fb *FlowBuilder) Control(id, desc string, reqs ...Requirement) *FlowBuilder {
+	fb.flow.AddControl(id, desc, reqs...)
+	return fb
+}
+
 func (fb *FlowBuilder) MetaMap(m map[string]any) *FlowBuilder {
 	for k, v := range m {
 		fb.flow.Metadata[k] = v
@@ -432,14 +591,14 @@ func (fb *FlowBuilder) MetaMap(m map[string]any) *FlowBuilder {
 type StepSpec struct {
 	ID   string
 	Desc string
-	Dir  string // Optional: defaults to "source-to-destination"
+	Dir  FlowDirection // Optional: defaults to SourceToDestination
 }
 
 func (fb *FlowBuilder) Steps(specs ...StepSpec) *FlowBuilder {
 	for _, s := range specs {
 		dir := s.Dir
 		if dir == "" {
-			dir = "source-to-destination"
+			dir = SourceToDestination
 		}
 		fb.StepEx(s.ID, s.Desc, dir)
 	}
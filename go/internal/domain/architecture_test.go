@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -99,3 +100,30 @@ func TestNode_ConnectTo(t *testing.T) {
 		}
 	})
 }
+
+func TestRelationshipType_UnmarshalJSON(t *testing.T) {
+	t.Run("should decode typed variants", func(t *testing.T) {
+		var rt RelationshipType
+		data := `{"interacts": {"actor": "user", "nodes": ["svc"]}}`
+		if err := json.Unmarshal([]byte(data), &rt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rt.Interacts == nil || rt.Interacts.Actor != "user" || len(rt.Interacts.Nodes) != 1 {
+			t.Errorf("unexpected interacts %#v", rt.Interacts)
+		}
+	})
+
+	t.Run("should reject misspelled keys", func(t *testing.T) {
+		cases := []string{
+			`{"interacts": {"actor": "user", "nodez": ["svc"]}}`,
+			`{"composed-of": {"contianer": "sys", "nodes": ["svc"]}}`,
+			`{"connects": {"source": {"node": "a"}, "destination": {"node": "b"}, "protocol": "HTTPS"}}`,
+		}
+		for _, data := range cases {
+			var rt RelationshipType
+			if err := json.Unmarshal([]byte(data), &rt); err == nil {
+				t.Errorf("expected error for %s", data)
+			}
+		}
+	})
+}
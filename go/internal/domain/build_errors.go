@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// BuildError is a mistake made while building an architecture with the DSL,
+// reported at the DSL call that made it.
+type BuildError struct {
+	// Pos is the "file:line" of the DSL call; empty when unknown, e.g. for
+	// architectures parsed from JSON.
+	Pos string
+	Msg string
+}
+
+func (e BuildError) Error() string {
+	if e.Pos == "" {
+		return e.Msg
+	}
+	return e.Pos + ": " + e.Msg
+}
+
+// Err reports the builder errors collected so far: duplicate node,
+// relationship, flow and interface IDs and metadata key collisions. It returns
+// nil when the architecture was built cleanly; otherwise the errors are joined,
+// those found while building first and duplicate IDs last.
+func (a *Architecture) Err() error {
+	errs := append([]error(nil), a.errs...)
+	errs = append(errs, a.duplicateIDErrors()...)
+	return errors.Join(errs...)
+}
+
+// RecordError adds err to the errors reported by Err, for builders that detect
+// problems of their own, such as failed compositions.
+func (a *Architecture) RecordError(err error) {
+	a.errs = append(a.errs, err)
+}
+
+// fail records a builder error at the DSL call site.
+func (a *Architecture) fail(format string, args ...any) {
+	a.errs = append(a.errs, BuildError{Pos: callerPos().String(), Msg: fmt.Sprintf(format, args...)})
+}
+
+// track remembers where an element was defined, so duplicates found later can
+// point at both definitions.
+func (a *Architecture) track(elem any) {
+	if a.origins == nil {
+		a.origins = make(map[any]SourcePos)
+	}
+	a.origins[elem] = callerPos()
+}
+
+// duplicateIDErrors reports nodes, relationships and flows that reuse an
+// earlier unique-id. Relationships are checked here rather than on creation
+// because ConnectTo IDs can still be overridden with WithID.
+func (a *Architecture) duplicateIDErrors() []error {
+	var errs []error
+	check := func(kind string, ids []string, elems []any) {
+		first := make(map[string]any, len(ids))
+		for i, id := range ids {
+			prev, dup := first[id]
+			if !dup {
+				first[id] = elems[i]
+				continue
+			}
+			msg := fmt.Sprintf("duplicate %s ID %q", kind, id)
+			if pos := a.origins[prev].String(); pos != "" {
+				msg += " (first defined at " + pos + ")"
+			}
+			errs = append(errs, BuildError{Pos: a.origins[elems[i]].String(), Msg: msg})
+		}
+	}
+
+	ids, elems := make([]string, len(a.Nodes)), make([]any, len(a.Nodes))
+	for i, n := range a.Nodes {
+		ids[i], elems[i] = n.UniqueID, n
+	}
+	check("node", ids, elems)
+
+	ids, elems = make([]string, len(a.Relationships)), make([]any, len(a.Relationships))
+	for i, r := range a.Relationships {
+		ids[i], elems[i] = r.UniqueID, r
+	}
+	check("relationship", ids, elems)
+
+	ids, elems = make([]string, len(a.Flows)), make([]any, len(a.Flows))
+	for i, f := range a.Flows {
+		ids[i], elems[i] = f.UniqueID, f
+	}
+	check("flow", ids, elems)
+	return errs
+}
+
+// addInterface appends iface to n, reporting an ID already used by an
+// interface anywhere in the architecture.
+func (n *Node) addInterface(iface Interface) {
+	if n.Arch != nil {
+		if owner := n.Arch.interfaceOwner(iface.UniqueID, n); owner != "" {
+			n.Arch.fail("duplicate interface ID %q on node %q (already defined on node %q)",
+				iface.UniqueID, n.UniqueID, owner)
+		}
+	}
+	n.Interfaces = append(n.Interfaces, iface)
+}
+
+// interfaceOwner returns the node that already defines interface id, looking
+// at n too since it may not have been added to the architecture yet.
+func (a *Architecture) interfaceOwner(id string, n *Node) string {
+	nodes := a.Nodes
+	if !containsNode(nodes, n) {
+		nodes = append(nodes[:len(nodes):len(nodes)], n)
+	}
+	for _, node := range nodes {
+		for _, iface := range node.Interfaces {
+			if iface.UniqueID == id {
+				return node.UniqueID
+			}
+		}
+	}
+	return ""
+}
+
+func containsNode(nodes []*Node, n *Node) bool {
+	for _, node := range nodes {
+		if node == n {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order, so errors are reported
+// deterministically.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SourcePos is a position in the Go source of a DSL builder.
+type SourcePos struct {
+	File string // absolute path, as recorded by the Go runtime
+	Line int
+}
+
+// String returns "file:line" with the base name of the file, or "" for the
+// zero SourcePos.
+func (p SourcePos) String() string {
+	if p.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(p.File), p.Line)
+}
+
+// Origin returns where the node, relationship or flow with the given kind and
+// ID was defined in the Go DSL. It reports false for architectures parsed from
+// JSON and for elements it cannot find.
+func (a *Architecture) Origin(kind ElementKind, id string) (SourcePos, bool) {
+	var elem any
+	switch kind {
+	case KindNode:
+		for _, n := range a.Nodes {
+			if n.UniqueID == id {
+				elem = n
+				break
+			}
+		}
+	case KindRelationship:
+		for _, r := range a.Relationships {
+			if r.UniqueID == id {
+				elem = r
+				break
+			}
+		}
+	case KindFlow:
+		for _, f := range a.Flows {
+			if f.UniqueID == id {
+				elem = f
+				break
+			}
+		}
+	}
+	pos, ok := a.origins[elem]
+	return pos, ok && pos.File != ""
+}
+
+// domainPkg is the prefix of this package's function names in stack traces.
+var domainPkg = reflect.TypeOf(BuildError{}).PkgPath() + "."
+
+// callerPos returns the position of the first caller outside the DSL itself,
+// i.e. the line of the builder that made the call.
+func callerPos() SourcePos {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		inDSL := strings.HasPrefix(f.Function, domainPkg) && !strings.HasSuffix(f.File, "_test.go")
+		if !inDSL {
+			return SourcePos{File: f.File, Line: f.Line}
+		}
+		if !more {
+			return SourcePos{}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// nextLine returns "<file>:<n>" for the line after the call.
+func nextLine() string {
+	_, file, n, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", filepath.Base(file), n+1)
+}
+
+func TestArchitecture_Err(t *testing.T) {
+	t.Run("should be nil for a clean build", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		api := arch.DefineNode("api", Service, "API", "desc", WithInterfaces(&Interface{UniqueID: "http"}))
+		db := arch.DefineNode("db", Database, "DB", "desc")
+		api.ConnectTo(db, "reads")
+		api.ConnectTo(db, "writes").WithID("api-writes-db")
+		arch.DefineFlow("f", "F", "desc")
+		if err := arch.Err(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should report duplicate IDs at the caller", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		first := nextLine()
+		api := arch.DefineNode("api", Service, "API", "desc")
+		dupNode := nextLine()
+		arch.DefineNode("api", Service, "API again", "desc")
+		db := arch.DefineNode("db", Database, "DB", "desc")
+		api.ConnectTo(db, "reads")
+		dupRel := nextLine()
+		api.ConnectTo(db, "writes")
+		arch.DefineFlow("f", "F", "desc")
+		arch.Flow("f", "F", "desc")
+		api.Interface("http", "HTTP")
+		dupIntf := nextLine()
+		db.Interface("http", "HTTP")
+
+		err := arch.Err()
+		if err == nil {
+			t.Fatalf("expected errors")
+		}
+		for _, want := range []string{
+			dupNode + `: duplicate node ID "api" (first defined at ` + first + ")",
+			dupRel + `: duplicate relationship ID "api-connects-db"`,
+			`duplicate flow ID "f"`,
+			dupIntf + `: duplicate interface ID "http" on node "db" (already defined on node "api")`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("expected %q in:\n%v", want, err)
+			}
+		}
+		var be BuildError
+		if !errors.As(err, &be) || be.Pos == "" {
+			t.Errorf("expected BuildError with a position, got %#v", be)
+		}
+	})
+
+	t.Run("should record metadata collisions instead of panicking", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		got := arch.Merge(map[string]any{"tier": "1"}, map[string]any{"tier": "2", "owner": "x"})
+		if got["tier"] != "1" || got["owner"] != "x" {
+			t.Errorf("unexpected merge result %v", got)
+		}
+		if err := arch.Err(); err == nil || !strings.Contains(err.Error(), `metadata collision: key "tier"`) {
+			t.Errorf("expected collision error, got %v", err)
+		}
+	})
+}
+
+func TestArchitecture_Origin(t *testing.T) {
+	arch := NewArchitecture("a", "A", "desc")
+	nodeLine := nextLine()
+	api := arch.DefineNode("api", Service, "API", "desc")
+	db := arch.DefineNode("db", Database, "DB", "desc")
+	relLine := nextLine()
+	api.ConnectTo(db, "reads").WithID("reads")
+
+	t.Run("should locate nodes and relationships", func(t *testing.T) {
+		if pos, ok := arch.Origin(KindNode, "api"); !ok || pos.String() != nodeLine {
+			t.Errorf("expected %s, got %v %v", nodeLine, pos, ok)
+		}
+		if pos, ok := arch.Origin(KindRelationship, "reads"); !ok || pos.String() != relLine {
+			t.Errorf("expected %s, got %v %v", relLine, pos, ok)
+		}
+		if _, ok := arch.Origin(KindFlow, "api"); ok {
+			t.Errorf("expected no flow origin")
+		}
+	})
+
+	t.Run("should add the origin to validation errors", func(t *testing.T) {
+		errs := arch.Validate(AllNodesHaveOwner())
+		if len(errs) != 2 || errs[0].Source.String() != nodeLine {
+			t.Errorf("unexpected errors %#v", errs)
+		}
+	})
+}
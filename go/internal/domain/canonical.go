@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"sort"
+)
+
+// Canonicalize returns a copy of a in canonical form, so that architectures
+// describing the same model render to identical bytes whatever order they
+// were built in:
+//
+//   - nodes, relationships and flows are sorted by unique-id, and interfaces
+//     by unique-id within each node;
+//   - node lists of interacts, composed-of and deployed-in relationships and of
+//     each options decision are sorted (decisions keep their order, since
+//     choices may refer to them by position);
+//   - flow transitions are sorted by sequence number;
+//   - nil metadata and controls maps become empty maps.
+//
+// Metadata values, controls and configs are shared with a, not copied.
+func Canonicalize(a *Architecture) *Architecture {
+	c := *a
+	c.Metadata = orEmptyMeta(a.Metadata)
+	c.Controls = orEmptyControls(a.Controls)
+
+	c.Nodes = make([]*Node, len(a.Nodes))
+	for i, n := range a.Nodes {
+		cn := *n
+		cn.Arch = &c
+		cn.Metadata = orEmptyMeta(n.Metadata)
+		cn.Controls = orEmptyControls(n.Controls)
+		cn.Interfaces = append([]Interface(nil), n.Interfaces...)
+		sort.SliceStable(cn.Interfaces, func(i, j int) bool {
+			return cn.Interfaces[i].UniqueID < cn.Interfaces[j].UniqueID
+		})
+		c.Nodes[i] = &cn
+	}
+	sort.SliceStable(c.Nodes, func(i, j int) bool { return c.Nodes[i].UniqueID < c.Nodes[j].UniqueID })
+
+	c.Relationships = make([]*Relationship, len(a.Relationships))
+	for i, r := range a.Relationships {
+		c.Relationships[i] = canonicalRelationship(r)
+	}
+	sort.SliceStable(c.Relationships, func(i, j int) bool {
+		return c.Relationships[i].UniqueID < c.Relationships[j].UniqueID
+	})
+
+	if a.Flows != nil {
+		c.Flows = make([]*Flow, len(a.Flows))
+		for i, f := range a.Flows {
+			cf := *f
+			cf.Metadata = orEmptyMeta(f.Metadata)
+			cf.Transitions = append([]Transition(nil), f.Transitions...)
+			sort.SliceStable(cf.Transitions, func(i, j int) bool {
+				return cf.Transitions[i].SequenceNumber < cf.Transitions[j].SequenceNumber
+			})
+			c.Flows[i] = &cf
+		}
+		sort.SliceStable(c.Flows, func(i, j int) bool { return c.Flows[i].UniqueID < c.Flows[j].UniqueID })
+	}
+
+	return &c
+}
+
+func canonicalRelationship(r *Relationship) *Relationship {
+	cr := *r
+	cr.Metadata = orEmptyMeta(r.Metadata)
+
+	rt := r.RelationshipType
+	if rt.Interacts != nil {
+		cr.RelationshipType.Interacts = &Interacts{Actor: rt.Interacts.Actor, Nodes: sortedCopy(rt.Interacts.Nodes)}
+	}
+	if rt.ComposedOf != nil {
+		cr.RelationshipType.ComposedOf = &ComposedOf{
+			Container: rt.ComposedOf.Container,
+			Nodes:     sortedCopy(rt.ComposedOf.Nodes),
+		}
+	}
+	if rt.DeployedIn != nil {
+		cr.RelationshipType.DeployedIn = &DeployedIn{
+			Container: rt.DeployedIn.Container,
+			Nodes:     sortedCopy(rt.DeployedIn.Nodes),
+		}
+	}
+	if rt.Options != nil {
+		cr.RelationshipType.Options = make([]Decision, len(rt.Options))
+		for i, d := range rt.Options {
+			cr.RelationshipType.Options[i] = Decision{
+				Description:   d.Description,
+				Nodes:         sortedCopy(d.Nodes),
+				Relationships: sortedCopy(d.Relationships),
+			}
+		}
+	}
+	return &cr
+}
+
+func sortedCopy(ids []string) []string {
+	if ids == nil {
+		return nil
+	}
+	out := append([]string(nil), ids...)
+	sort.Strings(out)
+	return out
+}
+
+func orEmptyMeta(m map[string]any) map[string]any {
+	if m == nil {
+		return make(map[string]any)
+	}
+	return m
+}
+
+func orEmptyControls(m map[string]*Control) map[string]*Control {
+	if m == nil {
+		return make(map[string]*Control)
+	}
+	return m
+}
+
+// SortedControlIDs returns the keys of a controls map in ascending order, for
+// code that must emit controls deterministically.
+func SortedControlIDs(controls map[string]*Control) []string {
+	ids := make([]string, 0, len(controls))
+	for id := range controls {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// controlRequirement is one requirement of a control on the architecture
+// (elementID "") or on one of its nodes, relationships or flows.
+type controlRequirement struct {
+	elementID string
+	control   string
+	req       Requirement
+}
+
+func (c controlRequirement) String() string {
+	return fmt.Sprintf("control %q requirement %s", c.control, c.req.RequirementURL)
+}
+
+// controlRequirements lists every control requirement of a, architecture
+// controls first, each group ordered by control ID.
+func (a *Architecture) controlRequirements() []controlRequirement {
+	var reqs []controlRequirement
+	add := func(id string, controls map[string]*Control) {
+		ids := make([]string, 0, len(controls))
+		for cid, c := range controls {
+			if c != nil {
+				ids = append(ids, cid)
+			}
+		}
+		sort.Strings(ids)
+		for _, cid := range ids {
+			for _, req := range controls[cid].Requirements {
+				reqs = append(reqs, controlRequirement{elementID: id, control: cid, req: req})
+			}
+		}
+	}
+	add("", a.Controls)
+	for _, n := range a.Nodes {
+		add(n.UniqueID, n.Controls)
+	}
+	for _, r := range a.Relationships {
+		add(r.UniqueID, r.Controls)
+	}
+	for _, f := range a.Flows {
+		add(f.UniqueID, f.Controls)
+	}
+	return reqs
+}
+
+// controlCheck is the outcome of checking one control requirement.
+type controlCheck struct {
+	controlRequirement
+	unresolved error    // the requirement schema cannot be resolved or loaded
+	problems   []string // problems with the config
+}
+
+// checkControls validates the config of every requirement, inline or loaded
+// from its config-url, against the schema at its requirement-url. A config
+// that cannot be loaded is reported as such and not checked.
+func checkControls(a *Architecture, schemas SchemaValidator, configs ConfigLoader) []controlCheck {
+	var checks []controlCheck
+	for _, cr := range a.controlRequirements() {
+		c := controlCheck{controlRequirement: cr}
+		config, hasConfig := cr.req.Config, cr.req.Config != nil
+		switch {
+		case hasConfig:
+		case cr.req.ConfigURL == "":
+			c.problems = append(c.problems, "has neither config nor config-url")
+		case configs == nil:
+		default:
+			loaded, err := configs.LoadConfig(cr.req.ConfigURL)
+			if err != nil {
+				c.problems = append(c.problems, fmt.Sprintf("config-url: %v", err))
+			} else {
+				config, hasConfig = loaded, true
+			}
+		}
+		violations, err := schemas.ValidateURL(cr.req.RequirementURL, config)
+		if err != nil {
+			c.unresolved = err
+		} else if hasConfig {
+			for _, v := range violations {
+				c.problems = append(c.problems, "config: "+v)
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// controlChecks shares the checkControls result for one architecture between
+// the rules of ControlRules, so that every requirement is checked once per
+// validation. The result is dropped once each rule has used it; a rule asking
+// again starts a new validation.
+type controlChecks struct {
+	schemas SchemaValidator
+	configs ConfigLoader
+
+	mu     sync.Mutex
+	arch   *Architecture
+	checks []controlCheck
+	served [2]bool
+}
+
+const (
+	resolveRule = iota
+	conformRule
+)
+
+func (m *controlChecks) of(a *Architecture, rule int) []controlCheck {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.arch != a || m.served[rule] {
+		m.arch, m.checks, m.served = a, checkControls(a, m.schemas, m.configs), [2]bool{}
+	}
+	m.served[rule] = true
+	checks := m.checks
+	if m.served[resolveRule] && m.served[conformRule] {
+		m.arch, m.checks = nil, nil
+	}
+	return checks
+}
+
+// ControlRules returns ControlRequirementsResolve and ControlConfigsConform,
+// sharing the checks of the control requirements.
+func ControlRules(schemas SchemaValidator, configs ConfigLoader) []ValidationRule {
+	m := &controlChecks{schemas: schemas, configs: configs}
+	return []ValidationRule{controlRequirementsResolve{checks: m}, controlConfigsConform{checks: m}}
+}
+
+// controlRequirementsResolve checks that the schema of every control
+// requirement can be resolved, e.g. through url-mapping.json
+type controlRequirementsResolve struct {
+	checks *controlChecks
+}
+
+func ControlRequirementsResolve(schemas SchemaValidator) ValidationRule {
+	return controlRequirementsResolve{checks: &controlChecks{schemas: schemas}}
+}
+
+func (r controlRequirementsResolve) Name() string { return "ControlRequirementsResolve" }
+
+func (r controlRequirementsResolve) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, c := range r.checks.of(a, resolveRule) {
+		if c.unresolved != nil {
+			errors = append(errors, ValidationError{
+				Rule:    r.Name(),
+				NodeID:  c.elementID,
+				Message: fmt.Sprintf("%s cannot be resolved: %v", c, c.unresolved),
+			})
+		}
+	}
+	return errors
+}
+
+// controlConfigsConform checks that every control requirement has a config,
+// inline or at its config-url, that conforms to the requirement's schema.
+// Requirements whose schema cannot be resolved are left to
+// ControlRequirementsResolve
+type controlConfigsConform struct {
+	checks *controlChecks
+}
+
+func ControlConfigsConform(schemas SchemaValidator, configs ConfigLoader) ValidationRule {
+	return controlConfigsConform{checks: &controlChecks{schemas: schemas, configs: configs}}
+}
+
+func (r controlConfigsConform) Name() string { return "ControlConfigsConform" }
+
+func (r controlConfigsConform) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, c := range r.checks.of(a, conformRule) {
+		for _, p := range c.problems {
+			errors = append(errors, ValidationError{
+				Rule:    r.Name(),
+				NodeID:  c.elementID,
+				Message: fmt.Sprintf("%s %s", c, p),
+			})
+		}
+	}
+	return errors
+}
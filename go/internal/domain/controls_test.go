@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+)
+
+type stubConfigs map[string]any
+
+func (s stubConfigs) LoadConfig(url string) (any, error) {
+	config, ok := s[url]
+	if !ok {
+		return nil, fmt.Errorf("%s is not in url-mapping.json", url)
+	}
+	return config, nil
+}
+
+func TestControlConfigsConform(t *testing.T) {
+	schemas := stubSchemas{
+		"https://policy.example.com/encryption": {"algorithm: must be one of [\"AES-256\"]"},
+		"https://policy.example.com/tls":        nil,
+		"https://policy.example.com/dr":         nil,
+		"https://policy.example.com/audit":      nil,
+	}
+	configs := stubConfigs{"https://configs.example.com/tls.yaml": map[string]any{"min-version": "TLS1.3"}}
+
+	a := NewArchitecture("a", "A", "desc")
+	a.AddControl("security", "desc",
+		NewRequirement("https://policy.example.com/encryption", NewSecurityConfig("DES", "all")),
+		NewRequirementURL("https://policy.example.com/tls", "https://configs.example.com/tls.yaml"),
+	)
+	a.DefineNode("db", Database, "DB", "desc",
+		WithControl("failover", "desc", NewRequirement("https://policy.example.com/dr", NewFailoverConfig(15, 5, true))),
+		WithControl("backup", "desc", NewRequirementURL("https://policy.example.com/backup", "")),
+		WithControl("audit", "desc", NewRequirementURL("https://policy.example.com/audit", "https://configs.example.com/x")),
+	)
+
+	errs := a.Validate(ControlConfigsConform(schemas, configs))
+	want := []struct{ id, msg string }{
+		{"", `control "security" requirement https://policy.example.com/encryption config: ` +
+			`algorithm: must be one of ["AES-256"]`},
+		{"db", `control "audit" requirement https://policy.example.com/audit config-url: ` +
+			`https://configs.example.com/x is not in url-mapping.json`},
+		{"db", `control "backup" requirement https://policy.example.com/backup has neither config nor config-url`},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, e := range errs {
+		if e.NodeID != want[i].id || e.Message != want[i].msg || e.Code != "CALM026" {
+			t.Errorf("error %d: got %v, want %q", i, e, want[i].msg)
+		}
+	}
+}
+
+func TestControlRequirementsResolve(t *testing.T) {
+	schemas := stubSchemas{"https://policy.example.com/encryption": nil, "https://policy.example.com/tls": nil}
+
+	a := NewArchitecture("a", "A", "desc")
+	a.AddControl("security", "desc",
+		NewRequirementURL("https://policy.example.com/encryption", ""),
+		NewRequirementURL("https://policy.example.com/tls", ""),
+	)
+	a.DefineNode("db", Database, "DB", "desc",
+		WithControl("failover", "desc", NewRequirementURL("https://policy.example.com/dr", "")),
+		WithControl("backup", "desc", NewRequirementURL("https://policy.example.com/backup", "")),
+		WithControl("audit", "desc", NewRequirementURL("https://policy.example.com/audit", "")),
+	)
+
+	errs := a.Validate(ControlRequirementsResolve(schemas))
+	if len(errs) != 3 {
+		t.Fatalf("expected the three node requirements, got %v", errs)
+	}
+	want := `control "audit" requirement https://policy.example.com/audit cannot be resolved: ` +
+		`https://policy.example.com/audit is not in url-mapping.json`
+	if errs[0].NodeID != "db" || errs[0].Message != want || errs[0].Severity != SeverityWarning {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
+
+type countingSchemas struct {
+	stubSchemas
+	calls int
+}
+
+func (s *countingSchemas) ValidateURL(url string, doc any) ([]string, error) {
+	s.calls++
+	return s.stubSchemas.ValidateURL(url, doc)
+}
+
+func TestControlRules(t *testing.T) {
+	t.Run("should check each requirement once per validation", func(t *testing.T) {
+		schemas := &countingSchemas{stubSchemas: stubSchemas{"https://policy.example.com/dr": nil}}
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("db", Database, "DB", "desc",
+			WithControl("failover", "desc", NewRequirement("https://policy.example.com/dr", NewFailoverConfig(15, 5, true))),
+			WithControl("backup", "desc", NewRequirementURL("https://policy.example.com/backup", "")),
+		)
+
+		rules := ControlRules(schemas, stubConfigs{})
+		for pass := 1; pass <= 2; pass++ {
+			errs := a.Validate(rules...)
+			if len(errs) != 2 {
+				t.Errorf("pass %d: expected an unresolved and a missing config error, got %v", pass, errs)
+			}
+			if schemas.calls != 2*pass {
+				t.Errorf("pass %d: expected %d schema checks, got %d", pass, 2*pass, schemas.calls)
+			}
+		}
+	})
+
+	t.Run("should check a loaded null config against the schema", func(t *testing.T) {
+		schemas := stubSchemas{"https://policy.example.com/dr": {"must be object"}}
+		configs := stubConfigs{"https://configs.example.com/dr.json": nil}
+		a := NewArchitecture("a", "A", "desc")
+		a.AddControl("failover", "desc",
+			NewRequirementURL("https://policy.example.com/dr", "https://configs.example.com/dr.json"))
+
+		errs := a.Validate(ControlRules(schemas, configs)...)
+		want := `control "failover" requirement https://policy.example.com/dr config: must be object`
+		if len(errs) != 1 || errs[0].Message != want {
+			t.Errorf("expected %q, got %v", want, errs)
+		}
+	})
+}
@@ -176,6 +176,25 @@ func TestValidationRulesPositiveAndNegative(t *testing.T) {
 		}
 	})
 
+	t.Run("NoDanglingRelationships and NoUnusedNodes for deployed-in", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		cluster := arch.DefineNode("k8s", System, "Cluster", "desc", WithOwner("team", "cc"))
+		svc := arch.DefineNode("svc", Service, "svc", "desc", WithOwner("team", "cc"))
+		rel := svc.DeployedIn(cluster)
+		if rel.UniqueID != "svc-deployed-in-k8s" {
+			t.Fatalf("unexpected relationship id %s", rel.UniqueID)
+		}
+		if errs := NoUnusedNodes().Validate(arch); len(errs) != 0 {
+			t.Fatalf("expected deployed-in to mark nodes as used, got %v", errs)
+		}
+
+		arch.DeployedIn("d2", "desc", "ghost-cluster", []string{"svc", "ghost"})
+		errs := NoDanglingRelationships().Validate(arch)
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+	})
+
 	t.Run("NoUnusedNodes detects unused", func(t *testing.T) {
 		arch := NewArchitecture("a", "A", "desc")
 		arch.DefineNode("n1", Service, "svc", "desc", WithOwner("team", "cc"))
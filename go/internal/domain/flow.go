@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FlowDirection is the direction in which a flow transition travels along its
+// relationship.
+type FlowDirection string
+
+const (
+	SourceToDestination FlowDirection = "source-to-destination"
+	DestinationToSource FlowDirection = "destination-to-source"
+)
+
+// Valid reports whether d is a CALM transition direction. An empty direction
+// is valid and means SourceToDestination.
+func (d FlowDirection) Valid() bool {
+	switch d {
+	case "", SourceToDestination, DestinationToSource:
+		return true
+	}
+	return false
+}
+
+// Hop is a flow transition resolved to the nodes it travels between. From and
+// To are empty when the transition cannot be resolved: its relationship does
+// not exist, is not a connects or single-target interacts relationship, or its
+// direction is invalid.
+type Hop struct {
+	Transition   Transition
+	Relationship *Relationship
+	From         string
+	To           string
+}
+
+// Resolved reports whether the hop has a source and a destination node.
+func (h Hop) Resolved() bool { return h.From != "" && h.To != "" }
+
+// FlowHops resolves the transitions of f, sorted by sequence number, to the
+// nodes each one travels between.
+func (g *Graph) FlowHops(f *Flow) []Hop {
+	transitions := append([]Transition(nil), f.Transitions...)
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].SequenceNumber < transitions[j].SequenceNumber
+	})
+
+	hops := make([]Hop, len(transitions))
+	for i, t := range transitions {
+		hops[i].Transition = t
+		rel, ok := g.Relationship(t.RelationshipID)
+		if !ok {
+			continue
+		}
+		hops[i].Relationship = rel
+		src, dst := relationshipEnds(rel)
+		switch t.Direction {
+		case "", SourceToDestination:
+			hops[i].From, hops[i].To = src, dst
+		case DestinationToSource:
+			hops[i].From, hops[i].To = dst, src
+		}
+	}
+	return hops
+}
+
+// relationshipEnds returns the source and destination nodes of a connects
+// relationship, or the actor and node of an interacts relationship with a
+// single node.
+func relationshipEnds(r *Relationship) (string, string) {
+	rt := r.RelationshipType
+	switch {
+	case rt.Connects != nil:
+		return rt.Connects.Source.Node, rt.Connects.Destination.Node
+	case rt.Interacts != nil && len(rt.Interacts.Nodes) == 1:
+		return rt.Interacts.Actor, rt.Interacts.Nodes[0]
+	}
+	return "", ""
+}
+
+// FlowRules returns the rules checking flow semantics: sequence numbers,
+// directions and chaining of consecutive steps.
+func FlowRules() []ValidationRule {
+	return []ValidationRule{FlowSequenceIsContiguous(), FlowDirectionsAreValid(), FlowStepsChain()}
+}
+
+// flowSequenceIsContiguous checks that transitions are numbered 1..n without
+// gaps or duplicates
+type flowSequenceIsContiguous struct{}
+
+func FlowSequenceIsContiguous() ValidationRule { return flowSequenceIsContiguous{} }
+
+func (r flowSequenceIsContiguous) Name() string { return "FlowSequenceIsContiguous" }
+
+func (r flowSequenceIsContiguous) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, flow := range a.Flows {
+		seen := make(map[int]bool, len(flow.Transitions))
+		var seqs []int
+		for _, t := range flow.Transitions {
+			if seen[t.SequenceNumber] {
+				errors = append(errors, ValidationError{
+					Rule:    r.Name(),
+					NodeID:  flow.UniqueID,
+					Message: fmt.Sprintf("duplicate sequence number %d", t.SequenceNumber),
+				})
+				continue
+			}
+			seen[t.SequenceNumber] = true
+			seqs = append(seqs, t.SequenceNumber)
+		}
+		sort.Ints(seqs)
+		for i, seq := range seqs {
+			if want := i + 1; seq != want {
+				errors = append(errors, ValidationError{
+					Rule:   r.Name(),
+					NodeID: flow.UniqueID,
+					Message: fmt.Sprintf("sequence numbers must run from 1 without gaps: expected %d, got %d",
+						want, seq),
+				})
+				break
+			}
+		}
+	}
+	return errors
+}
+
+// flowDirectionsAreValid checks that transition directions are CALM directions
+type flowDirectionsAreValid struct{}
+
+func FlowDirectionsAreValid() ValidationRule { return flowDirectionsAreValid{} }
+
+func (r flowDirectionsAreValid) Name() string { return "FlowDirectionsAreValid" }
+
+func (r flowDirectionsAreValid) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, flow := range a.Flows {
+		for _, t := range flow.Transitions {
+			if !t.Direction.Valid() {
+				errors = append(errors, ValidationError{
+					Rule:   r.Name(),
+					NodeID: flow.UniqueID,
+					Message: fmt.Sprintf("step %d has invalid direction %q (want %q or %q)",
+						t.SequenceNumber, t.Direction, SourceToDestination, DestinationToSource),
+				})
+			}
+		}
+	}
+	return errors
+}
+
+// flowStepsChain checks that each step starts where the previous one ended
+type flowStepsChain struct{}
+
+func FlowStepsChain() ValidationRule { return flowStepsChain{} }
+
+func (r flowStepsChain) Name() string { return "FlowStepsChain" }
+
+func (r flowStepsChain) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, flow := range a.Flows {
+		hops := g.FlowHops(flow)
+		for i, h := range hops {
+			if h.Relationship != nil && h.Transition.Direction.Valid() && !h.Resolved() {
+				errors = append(errors, ValidationError{
+					Rule:   r.Name(),
+					NodeID: flow.UniqueID,
+					Message: fmt.Sprintf("step %d: relationship %q has no single source and destination",
+						h.Transition.SequenceNumber, h.Transition.RelationshipID),
+				})
+			}
+			if i == 0 || !h.Resolved() || !hops[i-1].Resolved() {
+				continue
+			}
+			if prev := hops[i-1]; prev.To != h.From {
+				errors = append(errors, ValidationError{
+					Rule:   r.Name(),
+					NodeID: flow.UniqueID,
+					Message: fmt.Sprintf("step %d ends at %q but step %d starts at %q",
+						prev.Transition.SequenceNumber, prev.To, h.Transition.SequenceNumber, h.From),
+				})
+			}
+		}
+	}
+	return errors
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraph_FlowHops(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	user := a.DefineNode("user", Actor, "User", "desc")
+	api := a.DefineNode("api", Service, "API", "desc")
+	db := a.DefineNode("db", Database, "DB", "desc")
+	a.Interacts("user-api", "uses", user.UniqueID, api.UniqueID)
+	api.ConnectTo(db, "reads")
+	f := a.Flow("f", "F", "desc").
+		Step("api-connects-db", 3, "reply", DestinationToSource).
+		Step("user-api", 1, "request", SourceToDestination).
+		Step("api-connects-db", 2, "query", "").
+		Step("ghost", 4, "missing", SourceToDestination)
+
+	hops := NewGraph(a).FlowHops(f)
+	var got []string
+	for _, h := range hops {
+		got = append(got, h.From+">"+h.To)
+	}
+	if want := "user>api,api>db,db>api,>"; strings.Join(got, ",") != want {
+		t.Errorf("expected hops %s, got %s", want, strings.Join(got, ","))
+	}
+	if hops[3].Resolved() || hops[3].Relationship != nil {
+		t.Errorf("expected unresolved hop for a missing relationship, got %#v", hops[3])
+	}
+}
+
+func TestFlowRules(t *testing.T) {
+	t.Run("should accept a well-formed flow", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		user := a.DefineNode("user", Actor, "User", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		a.Interacts("user-api", "uses", user.UniqueID, api.UniqueID)
+		api.ConnectTo(db, "reads")
+		a.DefineFlow("f", "F", "desc").Step("user-api", "request").Step("api-connects-db", "query").
+			StepEx("api-connects-db", "reply", DestinationToSource)
+		if errs := a.Validate(FlowRules()...); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("should report gaps and duplicate sequence numbers", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		user := a.DefineNode("user", Actor, "User", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		a.Interacts("user-api", "uses", user.UniqueID, api.UniqueID)
+		api.ConnectTo(db, "reads")
+		a.Flow("gap", "Gap", "desc").Step("user-api", 1, "a", "").Step("api-connects-db", 3, "b", "")
+		a.Flow("dup", "Dup", "desc").Step("user-api", 1, "a", "").Step("user-api", 1, "b", "")
+		errs := FlowSequenceIsContiguous().Validate(a)
+		if len(errs) != 2 || !strings.Contains(errs[0].Message, "expected 2, got 3") ||
+			!strings.Contains(errs[1].Message, "duplicate sequence number 1") {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("should report invalid directions", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		user := a.DefineNode("user", Actor, "User", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		a.Interacts("user-api", "uses", user.UniqueID, api.UniqueID)
+		a.Flow("f", "F", "desc").Step("user-api", 1, "a", "sideways")
+		errs := FlowDirectionsAreValid().Validate(a)
+		if len(errs) != 1 || errs[0].NodeID != "f" || !strings.Contains(errs[0].Message, `"sideways"`) {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("should report steps that do not chain", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		user := a.DefineNode("user", Actor, "User", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		a.Interacts("user-api", "uses", user.UniqueID, api.UniqueID)
+		api.ConnectTo(db, "reads")
+		a.ComposedOf("sys", "system", "api", []string{"db"})
+		a.DefineFlow("f", "F", "desc").Step("user-api", "request").
+			StepEx("api-connects-db", "reply", DestinationToSource).Step("sys", "contain")
+		errs := FlowStepsChain().Validate(a)
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if !strings.Contains(errs[0].Message, `step 1 ends at "api" but step 2 starts at "db"`) {
+			t.Errorf("unexpected chain error %q", errs[0].Message)
+		}
+		if !strings.Contains(errs[1].Message, `step 3: relationship "sys" has no single source and destination`) {
+			t.Errorf("unexpected resolution error %q", errs[1].Message)
+		}
+	})
+}
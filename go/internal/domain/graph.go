@@ -0,0 +1,404 @@
+package domain
+
+import "sort"
+
+// Edge is a directed link between two nodes derived from a connects or
+// interacts relationship.
+type Edge struct {
+	From         string
+	To           string
+	Relationship *Relationship
+}
+
+// NodeRef is a node reference held by a relationship, labelled with the role
+// the node plays in it (e.g. "source node", "container node").
+type NodeRef struct {
+	Role string
+	ID   string
+}
+
+// NodeRefs lists every node referenced by the relationship, including the
+// nodes of options decisions.
+func (r *Relationship) NodeRefs() []NodeRef {
+	var refs []NodeRef
+	rt := r.RelationshipType
+	if rt.Connects != nil {
+		refs = append(refs,
+			NodeRef{Role: "source node", ID: rt.Connects.Source.Node},
+			NodeRef{Role: "destination node", ID: rt.Connects.Destination.Node},
+		)
+	}
+	if rt.Interacts != nil {
+		refs = append(refs, NodeRef{Role: "actor", ID: rt.Interacts.Actor})
+		for _, n := range rt.Interacts.Nodes {
+			refs = append(refs, NodeRef{Role: "target node", ID: n})
+		}
+	}
+	if rt.ComposedOf != nil {
+		refs = append(refs, NodeRef{Role: "container node", ID: rt.ComposedOf.Container})
+		for _, n := range rt.ComposedOf.Nodes {
+			refs = append(refs, NodeRef{Role: "contained node", ID: n})
+		}
+	}
+	if rt.DeployedIn != nil {
+		refs = append(refs, NodeRef{Role: "deployment container", ID: rt.DeployedIn.Container})
+		for _, n := range rt.DeployedIn.Nodes {
+			refs = append(refs, NodeRef{Role: "deployed node", ID: n})
+		}
+	}
+	for _, d := range rt.Options {
+		for _, n := range d.Nodes {
+			refs = append(refs, NodeRef{Role: "option node", ID: n})
+		}
+	}
+	return refs
+}
+
+// Graph is a read-only index over an architecture. Build it once with NewGraph
+// and share it between rules, renderers and analyses; it does not observe later
+// changes to the architecture.
+type Graph struct {
+	nodes      map[string]*Node
+	rels       map[string]*Relationship
+	out        map[string][]Edge
+	in         map[string][]Edge
+	parent     map[string]string
+	children   map[string][]string
+	containers map[string][]string
+	referenced map[string]bool
+	order      []string
+}
+
+// NewGraph indexes the nodes and relationships of a.
+// Containment follows composed-of relationships; when a node is listed by
+// several containers, the first one declared is its parent.
+func NewGraph(a *Architecture) *Graph {
+	g := &Graph{
+		nodes:      make(map[string]*Node, len(a.Nodes)),
+		rels:       make(map[string]*Relationship, len(a.Relationships)),
+		out:        make(map[string][]Edge),
+		in:         make(map[string][]Edge),
+		parent:     make(map[string]string),
+		children:   make(map[string][]string),
+		containers: make(map[string][]string),
+		referenced: make(map[string]bool),
+	}
+
+	for _, n := range a.Nodes {
+		if _, dup := g.nodes[n.UniqueID]; !dup {
+			g.order = append(g.order, n.UniqueID)
+		}
+		g.nodes[n.UniqueID] = n
+	}
+
+	for _, rel := range a.Relationships {
+		g.rels[rel.UniqueID] = rel
+		for _, ref := range rel.NodeRefs() {
+			g.referenced[ref.ID] = true
+		}
+
+		rt := rel.RelationshipType
+		if rt.Connects != nil {
+			g.addEdge(Edge{From: rt.Connects.Source.Node, To: rt.Connects.Destination.Node, Relationship: rel})
+		}
+		if rt.Interacts != nil {
+			for _, n := range rt.Interacts.Nodes {
+				g.addEdge(Edge{From: rt.Interacts.Actor, To: n, Relationship: rel})
+			}
+		}
+		if comp := rt.ComposedOf; comp != nil {
+			for _, n := range comp.Nodes {
+				g.containers[n] = append(g.containers[n], comp.Container)
+				if _, exists := g.parent[n]; exists {
+					continue
+				}
+				g.parent[n] = comp.Container
+				g.children[comp.Container] = append(g.children[comp.Container], n)
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *Graph) addEdge(e Edge) {
+	g.out[e.From] = append(g.out[e.From], e)
+	g.in[e.To] = append(g.in[e.To], e)
+}
+
+// Node returns the node with the given ID.
+func (g *Graph) Node(id string) (*Node, bool) {
+	n, ok := g.nodes[id]
+	return n, ok
+}
+
+// HasNode reports whether a node with the given ID exists.
+func (g *Graph) HasNode(id string) bool {
+	_, ok := g.nodes[id]
+	return ok
+}
+
+// Relationship returns the relationship with the given ID.
+func (g *Graph) Relationship(id string) (*Relationship, bool) {
+	r, ok := g.rels[id]
+	return r, ok
+}
+
+// HasRelationship reports whether a relationship with the given ID exists.
+func (g *Graph) HasRelationship(id string) bool {
+	_, ok := g.rels[id]
+	return ok
+}
+
+// IsReferenced reports whether any relationship refers to the node.
+func (g *Graph) IsReferenced(id string) bool {
+	return g.referenced[id]
+}
+
+// Outbound returns the edges leaving the node, in relationship order.
+func (g *Graph) Outbound(id string) []Edge {
+	return g.out[id]
+}
+
+// Inbound returns the edges entering the node, in relationship order.
+func (g *Graph) Inbound(id string) []Edge {
+	return g.in[id]
+}
+
+// Neighbors returns the distinct nodes connected to id in either direction,
+// outbound targets first.
+func (g *Graph) Neighbors(id string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(n string) {
+		if n != id && !seen[n] {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+	for _, e := range g.out[id] {
+		add(e.To)
+	}
+	for _, e := range g.in[id] {
+		add(e.From)
+	}
+	return result
+}
+
+// Parent returns the composed-of container of the node.
+func (g *Graph) Parent(id string) (string, bool) {
+	p, ok := g.parent[id]
+	return p, ok
+}
+
+// Children returns the nodes whose parent is id, in declaration order.
+func (g *Graph) Children(id string) []string {
+	return g.children[id]
+}
+
+// Ancestors returns the container chain of the node, nearest first.
+// It stops if the chain loops back on itself.
+func (g *Graph) Ancestors(id string) []string {
+	var result []string
+	seen := map[string]bool{id: true}
+	for {
+		p, ok := g.parent[id]
+		if !ok || seen[p] {
+			return result
+		}
+		seen[p] = true
+		result = append(result, p)
+		id = p
+	}
+}
+
+// Roots returns the nodes without a parent, in declaration order.
+func (g *Graph) Roots() []string {
+	var result []string
+	for _, id := range g.order {
+		if _, ok := g.parent[id]; !ok {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// Paths returns every simple path of edges from one node to another, each as
+// the sequence of node IDs visited. Parallel edges yield a single path.
+func (g *Graph) Paths(from, to string) [][]string {
+	if from == to {
+		return nil
+	}
+
+	var paths [][]string
+	seen := make(map[string]bool)
+	onPath := map[string]bool{from: true}
+	path := []string{from}
+
+	var walk func(cur string)
+	walk = func(cur string) {
+		for _, e := range g.out[cur] {
+			if onPath[e.To] {
+				continue
+			}
+			path = append(path, e.To)
+			if e.To == to {
+				key := pathKey(path)
+				if !seen[key] {
+					seen[key] = true
+					paths = append(paths, append([]string(nil), path...))
+				}
+			} else {
+				onPath[e.To] = true
+				walk(e.To)
+				delete(onPath, e.To)
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	walk(from)
+	return paths
+}
+
+// Reachable returns the nodes reachable from id by following edges, sorted.
+func (g *Graph) Reachable(id string) []string {
+	seen := map[string]bool{}
+	stack := []string{id}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, e := range g.out[cur] {
+			if !seen[e.To] {
+				seen[e.To] = true
+				stack = append(stack, e.To)
+			}
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+	for n := range seen {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Cycles returns cycles formed by edges. Every group of mutually reachable
+// nodes yields at least one cycle; each cycle lists its nodes once, starting
+// from the smallest ID.
+func (g *Graph) Cycles() [][]string {
+	return g.CyclesFunc(func(Edge) bool { return true })
+}
+
+// CyclesFunc is like Cycles but only follows edges accepted by keep.
+func (g *Graph) CyclesFunc(keep func(Edge) bool) [][]string {
+	adj := make(map[string][]string)
+	for _, id := range g.edgeSources() {
+		for _, e := range g.out[id] {
+			if keep(e) {
+				adj[id] = append(adj[id], e.To)
+			}
+		}
+	}
+	return findCycles(g.edgeSources(), adj)
+}
+
+// ContainmentCycles returns cycles in composed-of containment, e.g. a system
+// that (indirectly) contains itself.
+func (g *Graph) ContainmentCycles() [][]string {
+	adj := make(map[string][]string)
+	var order []string
+	for _, id := range g.order {
+		if len(g.containers[id]) > 0 {
+			order = append(order, id)
+		}
+		adj[id] = append(adj[id], g.containers[id]...)
+	}
+	return findCycles(order, adj)
+}
+
+// edgeSources returns every node that has outbound edges, declared nodes first.
+func (g *Graph) edgeSources() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, id := range g.order {
+		seen[id] = true
+		if len(g.out[id]) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	var extra []string
+	for id := range g.out {
+		if !seen[id] {
+			extra = append(extra, id)
+		}
+	}
+	sort.Strings(extra)
+	return append(ids, extra...)
+}
+
+// findCycles runs a depth-first search from each start node and records the
+// cycle closed by every back edge, normalised and de-duplicated.
+func findCycles(starts []string, adj map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var visit func(n string)
+	visit = func(n string) {
+		state[n] = active
+		stack = append(stack, n)
+		for _, next := range adj[n] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case active:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := normaliseCycle(stack[start:])
+				if key := pathKey(cycle); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = done
+	}
+
+	for _, n := range starts {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	return cycles
+}
+
+func pathKey(ids []string) string {
+	key := ""
+	for _, id := range ids {
+		key += id + "\x00"
+	}
+	return key
+}
+
+// normaliseCycle rotates a cycle so that it starts at its smallest node ID.
+func normaliseCycle(cycle []string) []string {
+	minIdx := 0
+	for i, id := range cycle {
+		if id < cycle[minIdx] {
+			minIdx = i
+		}
+	}
+	out := make([]string, 0, len(cycle))
+	out = append(out, cycle[minIdx:]...)
+	return append(out, cycle[:minIdx]...)
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraph_Edges(t *testing.T) {
+	arch := NewArchitecture("g", "Graph", "desc")
+	user := arch.DefineNode("user", Actor, "User", "desc")
+	sys := arch.DefineNode("sys", System, "System", "desc")
+	web := arch.DefineNode("web", Service, "Web", "desc")
+	api := arch.DefineNode("api", Service, "API", "desc")
+	cache := arch.DefineNode("cache", Database, "Cache", "desc")
+	db := arch.DefineNode("db", Database, "DB", "desc")
+	arch.DefineNode("orphan", Service, "Orphan", "desc")
+	arch.ComposedOf("sys-comp", "desc", sys.UniqueID, []string{web.UniqueID})
+	arch.Interacts("user-web", "desc", user.UniqueID, web.UniqueID)
+	web.ConnectTo(api, "calls")
+	web.ConnectTo(cache, "reads")
+	db.ConnectTo(web, "notifies")
+	g := NewGraph(arch)
+
+	if out := g.Outbound("web"); len(out) != 2 || out[0].To != "api" || out[1].To != "cache" {
+		t.Errorf("unexpected outbound edges %v", out)
+	}
+	if in := g.Inbound("web"); len(in) != 2 || in[0].From != "user" || in[1].From != "db" {
+		t.Errorf("unexpected inbound edges %v", in)
+	}
+	if in := g.Inbound("web"); in[0].Relationship.UniqueID != "user-web" {
+		t.Errorf("expected edge to carry its relationship, got %v", in[0].Relationship)
+	}
+	if got := g.Neighbors("web"); !reflect.DeepEqual(got, []string{"api", "cache", "user", "db"}) {
+		t.Errorf("unexpected neighbors %v", got)
+	}
+	if !g.IsReferenced("sys") || g.IsReferenced("orphan") {
+		t.Errorf("unexpected referenced state")
+	}
+	if !g.HasNode("db") || g.HasNode("ghost") || !g.HasRelationship("web-connects-api") {
+		t.Errorf("unexpected lookups")
+	}
+}
+
+func TestGraph_Containment(t *testing.T) {
+	arch := NewArchitecture("g", "Graph", "desc")
+	sys := arch.DefineNode("sys", System, "System", "desc")
+	web := arch.DefineNode("web", Service, "Web", "desc")
+	api := arch.DefineNode("api", Service, "API", "desc")
+	db := arch.DefineNode("db", Database, "DB", "desc")
+	arch.DefineNode("cache", Database, "Cache", "desc")
+	arch.ComposedOf("sys-comp", "desc", sys.UniqueID, []string{web.UniqueID, api.UniqueID})
+	arch.ComposedOf("api-comp", "desc", api.UniqueID, []string{db.UniqueID})
+	g := NewGraph(arch)
+
+	if p, ok := g.Parent("db"); !ok || p != "api" {
+		t.Errorf("expected db parent api, got %q", p)
+	}
+	if got := g.Ancestors("db"); !reflect.DeepEqual(got, []string{"api", "sys"}) {
+		t.Errorf("unexpected ancestors %v", got)
+	}
+	if got := g.Children("sys"); !reflect.DeepEqual(got, []string{"web", "api"}) {
+		t.Errorf("unexpected children %v", got)
+	}
+	if got := g.Roots(); !reflect.DeepEqual(got, []string{"sys", "cache"}) {
+		t.Errorf("unexpected roots %v", got)
+	}
+
+	t.Run("first container wins", func(t *testing.T) {
+		arch := NewArchitecture("g", "Graph", "desc")
+		arch.DefineNode("sys", System, "System", "desc")
+		arch.DefineNode("other", System, "Other", "desc")
+		arch.DefineNode("web", Service, "Web", "desc")
+		arch.ComposedOf("sys-comp", "desc", "sys", []string{"web"})
+		arch.ComposedOf("other-comp", "desc", "other", []string{"web"})
+		g := NewGraph(arch)
+		if p, _ := g.Parent("web"); p != "sys" {
+			t.Errorf("expected first declared parent sys, got %q", p)
+		}
+	})
+
+	t.Run("containment cycles", func(t *testing.T) {
+		arch := NewArchitecture("g", "Graph", "desc")
+		arch.DefineNode("sys", System, "System", "desc")
+		arch.DefineNode("api", Service, "API", "desc")
+		arch.DefineNode("db", Database, "DB", "desc")
+		arch.ComposedOf("sys-comp", "desc", "sys", []string{"api"})
+		arch.ComposedOf("api-comp", "desc", "api", []string{"db"})
+		if got := NewGraph(arch).ContainmentCycles(); len(got) != 0 {
+			t.Fatalf("expected no containment cycles, got %v", got)
+		}
+		arch.ComposedOf("loop", "desc", "db", []string{"sys"})
+		g := NewGraph(arch)
+		if got := g.ContainmentCycles(); !reflect.DeepEqual(got, [][]string{{"api", "sys", "db"}}) {
+			t.Errorf("unexpected containment cycles %v", got)
+		}
+		if got := g.Ancestors("db"); !reflect.DeepEqual(got, []string{"api", "sys"}) {
+			t.Errorf("expected ancestors to stop at the loop, got %v", got)
+		}
+	})
+}
+
+func TestGraph_Traversal(t *testing.T) {
+	arch := NewArchitecture("g", "Graph", "desc")
+	user := arch.DefineNode("user", Actor, "User", "desc")
+	web := arch.DefineNode("web", Service, "Web", "desc")
+	api := arch.DefineNode("api", Service, "API", "desc")
+	db := arch.DefineNode("db", Database, "DB", "desc")
+	cache := arch.DefineNode("cache", Database, "Cache", "desc")
+	arch.DefineNode("orphan", Service, "Orphan", "desc")
+	arch.Interacts("user-web", "desc", user.UniqueID, web.UniqueID)
+	web.ConnectTo(api, "calls")
+	web.ConnectTo(cache, "reads")
+	cache.ConnectTo(api, "refresh")
+	api.ConnectTo(db, "queries")
+	db.ConnectTo(web, "notifies")
+	g := NewGraph(arch)
+
+	want := [][]string{{"user", "web", "api", "db"}, {"user", "web", "cache", "api", "db"}}
+	if got := g.Paths("user", "db"); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected paths %v", got)
+	}
+	if got := g.Paths("db", "user"); len(got) != 0 {
+		t.Errorf("expected no path against edge direction, got %v", got)
+	}
+	if got := g.Reachable("user"); !reflect.DeepEqual(got, []string{"api", "cache", "db", "web"}) {
+		t.Errorf("unexpected reachable set %v", got)
+	}
+	if got := g.Reachable("orphan"); len(got) != 0 {
+		t.Errorf("expected nothing reachable from orphan, got %v", got)
+	}
+
+	// web->cache->api->db->web shares nodes with the reported cycle, so one cycle
+	// is enough to flag the group.
+	wantCycles := [][]string{{"api", "db", "web"}}
+	if got := g.Cycles(); !reflect.DeepEqual(got, wantCycles) {
+		t.Errorf("unexpected cycles %v", got)
+	}
+	noDB := func(e Edge) bool { return e.To != "db" }
+	if got := g.CyclesFunc(noDB); len(got) != 0 {
+		t.Errorf("expected filtered graph to be acyclic, got %v", got)
+	}
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ImportOptions controls how Import merges a sub-architecture.
+type ImportOptions struct {
+	// Prefix is prepended verbatim to the IDs of imported nodes, interfaces,
+	// relationships, flows and architecture-level controls, e.g. "payments-".
+	// References inside the imported model are rewritten to match.
+	Prefix string
+}
+
+// ID returns the ID an imported element gets, for wiring cross-architecture
+// relationships such as a.Connect(..., opts.ID("gateway")).
+func (o ImportOptions) ID(id string) string {
+	return o.Prefix + id
+}
+
+// Import merges the nodes, relationships, flows and architecture-level
+// controls of other into a. other is not modified: imported elements are
+// copies, including their metadata, controls and details, and keep where they were
+// defined in the Go DSL. Relationships and flows of
+// other keep pointing at the imported (possibly prefixed) elements, and
+// relationships already in a may refer to them via opts.ID.
+// When an imported ID collides with one already in a, nothing is merged and
+// the error lists every collision.
+func (a *Architecture) Import(other *Architecture, opts ImportOptions) error {
+	id := opts.ID
+
+	existing := make(map[string]string)
+	for _, n := range a.Nodes {
+		existing["node "+n.UniqueID] = n.UniqueID
+		for _, intf := range n.Interfaces {
+			existing["interface "+intf.UniqueID] = intf.UniqueID
+		}
+	}
+	for _, r := range a.Relationships {
+		existing["relationship "+r.UniqueID] = r.UniqueID
+	}
+	for _, f := range a.Flows {
+		existing["flow "+f.UniqueID] = f.UniqueID
+	}
+	for cid := range a.Controls {
+		existing["control "+cid] = cid
+	}
+
+	var collisions []string
+	check := func(kind, newID string) {
+		key := kind + " " + newID
+		if _, ok := existing[key]; ok {
+			collisions = append(collisions, fmt.Sprintf("%s %q", kind, newID))
+		}
+		existing[key] = newID
+	}
+	for _, n := range other.Nodes {
+		check("node", id(n.UniqueID))
+		for _, intf := range n.Interfaces {
+			check("interface", id(intf.UniqueID))
+		}
+	}
+	for _, r := range other.Relationships {
+		check("relationship", id(r.UniqueID))
+	}
+	for _, f := range other.Flows {
+		check("flow", id(f.UniqueID))
+	}
+	for _, cid := range SortedControlIDs(other.Controls) {
+		check("control", id(cid))
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("import of %q: ID collision: %s", other.UniqueID, strings.Join(collisions, ", "))
+	}
+
+	for _, n := range other.Nodes {
+		cn := *n
+		cn.Arch = a
+		cn.UniqueID = id(n.UniqueID)
+		cn.Metadata = cloneMetadata(n.Metadata)
+		cn.Controls = cloneControls(n.Controls)
+		if n.Details != nil {
+			details := *n.Details
+			cn.Details = &details
+		}
+		cn.Interfaces = make([]Interface, len(n.Interfaces))
+		for i, intf := range n.Interfaces {
+			intf.UniqueID = id(intf.UniqueID)
+			intf.Config = cloneValue(intf.Config)
+			cn.Interfaces[i] = intf
+		}
+		a.Nodes = append(a.Nodes, &cn)
+		a.adoptOrigin(other, n, &cn)
+	}
+	for _, r := range other.Relationships {
+		cr := prefixRelationship(r, id)
+		cr.Metadata = cloneMetadata(r.Metadata)
+		cr.Controls = cloneControls(r.Controls)
+		a.Relationships = append(a.Relationships, cr)
+		a.adoptOrigin(other, r, cr)
+	}
+	for _, f := range other.Flows {
+		cf := *f
+		cf.UniqueID = id(f.UniqueID)
+		cf.Metadata = cloneMetadata(f.Metadata)
+		cf.Controls = cloneControls(f.Controls)
+		cf.Transitions = make([]Transition, len(f.Transitions))
+		for i, t := range f.Transitions {
+			t.RelationshipID = id(t.RelationshipID)
+			cf.Transitions[i] = t
+		}
+		a.Flows = append(a.Flows, &cf)
+		a.adoptOrigin(other, f, &cf)
+	}
+	if len(other.Controls) > 0 && a.Controls == nil {
+		a.Controls = make(map[string]*Control, len(other.Controls))
+	}
+	for cid, c := range cloneControls(other.Controls) {
+		a.Controls[id(cid)] = c
+	}
+	return nil
+}
+
+// adoptOrigin records that the copy of an element of other was defined where
+// the element was.
+func (a *Architecture) adoptOrigin(other *Architecture, elem, copied any) {
+	pos, ok := other.origins[elem]
+	if !ok {
+		return
+	}
+	if a.origins == nil {
+		a.origins = make(map[any]SourcePos)
+	}
+	a.origins[copied] = pos
+}
+
+// cloneMetadata deep-copies m, so that the copy can be edited independently.
+func cloneMetadata(m Metadata) Metadata {
+	if m == nil {
+		return nil
+	}
+	return Metadata(cloneValue(map[string]any(m)).(map[string]any))
+}
+
+// cloneControls deep-copies controls, including their requirements and
+// inline configs.
+func cloneControls(controls map[string]*Control) map[string]*Control {
+	if controls == nil {
+		return nil
+	}
+	out := make(map[string]*Control, len(controls))
+	for id, c := range controls {
+		cc := *c
+		if c.Requirements != nil {
+			cc.Requirements = make([]Requirement, len(c.Requirements))
+			for i, req := range c.Requirements {
+				req.Config = cloneValue(req.Config)
+				cc.Requirements[i] = req
+			}
+		}
+		out[id] = &cc
+	}
+	return out
+}
+
+// cloneValue deep-copies the maps, slices, pointers and structs of a
+// JSON-like value, whatever their element types, e.g. []string or
+// []map[string]any. Scalars are returned as they are.
+func cloneValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return cloneReflect(reflect.ValueOf(v)).Interface()
+}
+
+func cloneReflect(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			out.SetMapIndex(it.Key(), cloneReflect(it.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(cloneReflect(v.Index(i)))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(cloneReflect(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(cloneReflect(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(cloneReflect(v.Field(i)))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// prefixRelationship copies r with every ID it holds passed through id.
+func prefixRelationship(r *Relationship, id func(string) string) *Relationship {
+	ids := func(in []string) []string {
+		if in == nil {
+			return nil
+		}
+		out := make([]string, len(in))
+		for i, s := range in {
+			out[i] = id(s)
+		}
+		return out
+	}
+
+	cr := *r
+	cr.UniqueID = id(r.UniqueID)
+	rt := r.RelationshipType
+	if c := rt.Connects; c != nil {
+		cr.RelationshipType.Connects = &Connects{
+			Source:      NodeInterface{Node: id(c.Source.Node), Interfaces: ids(c.Source.Interfaces)},
+			Destination: NodeInterface{Node: id(c.Destination.Node), Interfaces: ids(c.Destination.Interfaces)},
+		}
+	}
+	if i := rt.Interacts; i != nil {
+		cr.RelationshipType.Interacts = &Interacts{Actor: id(i.Actor), Nodes: ids(i.Nodes)}
+	}
+	if c := rt.ComposedOf; c != nil {
+		cr.RelationshipType.ComposedOf = &ComposedOf{Container: id(c.Container), Nodes: ids(c.Nodes)}
+	}
+	if d := rt.DeployedIn; d != nil {
+		cr.RelationshipType.DeployedIn = &DeployedIn{Container: id(d.Container), Nodes: ids(d.Nodes)}
+	}
+	if rt.Options != nil {
+		cr.RelationshipType.Options = make([]Decision, len(rt.Options))
+		for i, d := range rt.Options {
+			cr.RelationshipType.Options[i] = Decision{
+				Description:   d.Description,
+				Nodes:         ids(d.Nodes),
+				Relationships: ids(d.Relationships),
+			}
+		}
+	}
+	return &cr
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func newPaymentsArch() *Architecture {
+	arch := NewArchitecture("payments", "Payments", "desc")
+	arch.AddControl("pci", "PCI DSS", NewRequirement("https://example.com/pci.json", nil))
+	gw := arch.DefineNode("gateway", Service, "Gateway", "desc",
+		WithInterfaces(&Interface{UniqueID: "gateway-api", Protocol: "HTTPS"}))
+	db := arch.DefineNode("ledger", Database, "Ledger", "desc")
+	rel := gw.ConnectTo(db, "records").GetID()
+	arch.Connect("gw-ledger-api", "desc", "gateway", "ledger").SrcIntf("gateway-api")
+	arch.DefineFlow("charge", "Charge", "desc").Step(rel, "record charge")
+	return arch
+}
+
+func TestArchitecture_Import(t *testing.T) {
+	t.Run("should prefix IDs and rewrite references", func(t *testing.T) {
+		platform := NewArchitecture("platform", "Platform", "desc")
+		orders := platform.DefineNode("orders", Service, "Orders", "desc")
+		opts := ImportOptions{Prefix: "payments-"}
+		platform.Connect("orders-pay", "desc", orders.UniqueID, opts.ID("gateway"))
+
+		payments := newPaymentsArch()
+		if err := platform.Import(payments, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(platform.Nodes) != 3 || platform.Nodes[1].UniqueID != "payments-gateway" {
+			t.Fatalf("unexpected nodes %v", platform.Nodes)
+		}
+		if platform.Nodes[1].Arch != platform || platform.Nodes[1].Interfaces[0].UniqueID != "payments-gateway-api" {
+			t.Errorf("expected imported node to belong to the platform with prefixed interfaces")
+		}
+		conn := platform.Relationships[1].RelationshipType.Connects
+		if platform.Relationships[1].UniqueID != "payments-gateway-connects-ledger" ||
+			conn.Source.Node != "payments-gateway" || conn.Destination.Node != "payments-ledger" {
+			t.Errorf("unexpected imported relationship %+v", platform.Relationships[1])
+		}
+		got := platform.Relationships[2].RelationshipType.Connects.Source.Interfaces
+		if got[0] != "payments-gateway-api" {
+			t.Errorf("expected interface reference to be prefixed, got %v", got)
+		}
+		if tr := platform.Flows[0].Transitions[0]; platform.Flows[0].UniqueID != "payments-charge" ||
+			tr.RelationshipID != "payments-gateway-connects-ledger" {
+			t.Errorf("unexpected imported flow %+v", platform.Flows[0])
+		}
+		if _, ok := platform.Controls["payments-pci"]; !ok {
+			t.Errorf("expected prefixed control, got %v", platform.Controls)
+		}
+		if errs := platform.Validate(NoDanglingRelationships(), AllFlowsHaveValidTransitions()); len(errs) != 0 {
+			t.Errorf("expected merged architecture to be valid, got %v", errs)
+		}
+		if payments.Nodes[0].UniqueID != "gateway" || payments.Relationships[0].UniqueID != "gateway-connects-ledger" {
+			t.Errorf("expected the imported architecture to be unchanged")
+		}
+	})
+
+	t.Run("should report every collision and leave the architecture unchanged", func(t *testing.T) {
+		platform := NewArchitecture("platform", "Platform", "desc")
+		platform.DefineNode("gateway", Service, "Gateway", "desc")
+		platform.AddControl("pci", "desc")
+
+		err := platform.Import(newPaymentsArch(), ImportOptions{})
+		if err == nil {
+			t.Fatalf("expected collision error")
+		}
+		for _, want := range []string{`node "gateway"`, `control "pci"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("expected %s in %v", want, err)
+			}
+		}
+		if len(platform.Nodes) != 1 || len(platform.Relationships) != 0 {
+			t.Errorf("expected no partial import")
+		}
+	})
+
+	t.Run("should keep where imported elements were defined", func(t *testing.T) {
+		payments := NewArchitecture("payments", "Payments", "desc")
+		nodeLine := nextLine()
+		gw := payments.DefineNode("gateway", Service, "Gateway", "desc")
+		db := payments.DefineNode("ledger", Database, "Ledger", "desc")
+		relLine := nextLine()
+		gw.ConnectTo(db, "records").WithID("records")
+
+		platform := NewArchitecture("platform", "Platform", "desc")
+		if err := platform.Import(payments, ImportOptions{Prefix: "payments-"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pos, ok := platform.Origin(KindNode, "payments-gateway"); !ok || pos.String() != nodeLine {
+			t.Errorf("expected %s, got %v %v", nodeLine, pos, ok)
+		}
+		if pos, ok := platform.Origin(KindRelationship, "payments-records"); !ok || pos.String() != relLine {
+			t.Errorf("expected %s, got %v %v", relLine, pos, ok)
+		}
+	})
+
+	t.Run("should not share metadata and controls with the source", func(t *testing.T) {
+		payments := NewArchitecture("payments", "Payments", "desc")
+		payments.AddControl("pci", "PCI DSS", NewRequirement("https://example.com/pci.json", map[string]any{"level": 1}))
+		payments.DefineNode("gateway", Service, "Gateway", "desc",
+			WithMeta(map[string]any{"tier": "tier-1", "tags": []any{"pci"}}))
+
+		platform := NewArchitecture("platform", "Platform", "desc")
+		if err := platform.Import(payments, ImportOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		imported := platform.Nodes[0]
+		imported.Metadata["tier"] = "tier-3"
+		imported.Metadata["tags"].([]any)[0] = "none"
+		platform.Controls["pci"].Requirements[0].Config.(map[string]any)["level"] = 4
+
+		src := payments.Nodes[0].Metadata
+		if src["tier"] != "tier-1" || src["tags"].([]any)[0] != "pci" {
+			t.Errorf("expected source metadata to be unchanged, got %v", src)
+		}
+		if cfg := payments.Controls["pci"].Requirements[0].Config.(map[string]any); cfg["level"] != 1 {
+			t.Errorf("expected source control config to be unchanged, got %v", cfg)
+		}
+	})
+
+	t.Run("should not share typed metadata values and details with the source", func(t *testing.T) {
+		payments := NewArchitecture("payments", "Payments", "desc")
+		payments.DefineNode("gateway", Service, "Gateway", "desc",
+			WithMeta(map[string]any{
+				"dependencies":  []string{"ledger"},
+				"failure-modes": []map[string]any{{"mode": "timeout"}},
+			}),
+			WithDetails("payments-gateway.json", ""))
+
+		platform := NewArchitecture("platform", "Platform", "desc")
+		if err := platform.Import(payments, ImportOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		imported := platform.Nodes[0]
+		imported.Metadata["dependencies"].([]string)[0] = "none"
+		imported.Metadata["failure-modes"].([]map[string]any)[0]["mode"] = "none"
+		imported.Details.DetailedArchitecture = "other.json"
+
+		src := payments.Nodes[0]
+		if deps := src.Metadata["dependencies"].([]string); deps[0] != "ledger" {
+			t.Errorf("expected source dependencies to be unchanged, got %v", deps)
+		}
+		if modes := src.Metadata["failure-modes"].([]map[string]any); modes[0]["mode"] != "timeout" {
+			t.Errorf("expected source failure modes to be unchanged, got %v", modes)
+		}
+		if src.Details.DetailedArchitecture != "payments-gateway.json" {
+			t.Errorf("expected source details to be unchanged, got %v", src.Details)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// interfaceReferencesResolve checks that every interface a connects
+// relationship names exists on the node at that end
+type interfaceReferencesResolve struct{}
+
+func InterfaceReferencesResolve() ValidationRule { return interfaceReferencesResolve{} }
+
+func (r interfaceReferencesResolve) Name() string { return "InterfaceReferencesResolve" }
+
+func (r interfaceReferencesResolve) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, rel := range a.Relationships {
+		c := rel.RelationshipType.Connects
+		if c == nil {
+			continue
+		}
+		for _, end := range []struct {
+			role string
+			ref  NodeInterface
+		}{{"source", c.Source}, {"destination", c.Destination}} {
+			n, ok := g.Node(end.ref.Node)
+			if !ok {
+				continue // reported by NoDanglingRelationships
+			}
+			for _, id := range end.ref.Interfaces {
+				if _, ok := n.FindInterface(id); ok {
+					continue
+				}
+				msg := fmt.Sprintf("%s interface %q does not exist on node %q", end.role, id, n.UniqueID)
+				if owner := a.interfaceOwner(id, n); owner != "" {
+					msg += fmt.Sprintf(" (it is defined on node %q)", owner)
+				}
+				if s := closestID(id, interfaceIDs(n)); s != "" {
+					msg += fmt.Sprintf("; did you mean %q?", s)
+				}
+				errors = append(errors, ValidationError{Rule: r.Name(), NodeID: rel.UniqueID, Message: msg})
+			}
+		}
+	}
+	return errors
+}
+
+// noUnusedInterfaces checks that every interface is used by a relationship.
+// Interfaces serving the node's health-endpoint are probed rather than
+// connected to, and nodes no relationship uses are left to NoUnusedNodes
+type noUnusedInterfaces struct{}
+
+func NoUnusedInterfaces() ValidationRule { return noUnusedInterfaces{} }
+
+func (r noUnusedInterfaces) Name() string { return "NoUnusedInterfaces" }
+
+func (r noUnusedInterfaces) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+	used := make(map[string]map[string]bool) // node ID -> interface IDs
+	for _, rel := range a.Relationships {
+		if c := rel.RelationshipType.Connects; c != nil {
+			for _, end := range []NodeInterface{c.Source, c.Destination} {
+				if used[end.Node] == nil {
+					used[end.Node] = make(map[string]bool)
+				}
+				for _, id := range end.Interfaces {
+					used[end.Node][id] = true
+				}
+			}
+		}
+	}
+
+	var errors []ValidationError
+	for _, node := range a.Nodes {
+		if !g.IsReferenced(node.UniqueID) {
+			continue
+		}
+		health, _ := node.Metadata[MetaHealthEndpoint].(string)
+		for _, intf := range node.Interfaces {
+			if used[node.UniqueID][intf.UniqueID] || (health != "" && intf.Path == health) {
+				continue
+			}
+			errors = append(errors, ValidationError{
+				Rule:    r.Name(),
+				NodeID:  node.UniqueID,
+				Message: fmt.Sprintf("interface %q is not used by any relationship", intf.UniqueID),
+			})
+		}
+	}
+	return errors
+}
+
+// noInterfaceAddressCollisions checks that no two interfaces claim the same
+// host and port. Interfaces without a host are only compared within their node
+type noInterfaceAddressCollisions struct{}
+
+func NoInterfaceAddressCollisions() ValidationRule { return noInterfaceAddressCollisions{} }
+
+func (r noInterfaceAddressCollisions) Name() string { return "NoInterfaceAddressCollisions" }
+
+func (r noInterfaceAddressCollisions) Validate(a *Architecture) []ValidationError {
+	type claim struct{ node, intf string }
+	claims := make(map[string]claim)
+
+	var errors []ValidationError
+	for _, node := range a.Nodes {
+		for _, intf := range node.Interfaces {
+			if intf.Port == 0 {
+				continue
+			}
+			addr := fmt.Sprintf("%s:%d", intf.Host, intf.Port)
+			key := strings.ToLower(addr)
+			if intf.Host == "" {
+				addr = fmt.Sprintf("port %d", intf.Port)
+				key = node.UniqueID + " " + addr
+			}
+			prev, ok := claims[key]
+			if !ok {
+				claims[key] = claim{node.UniqueID, intf.UniqueID}
+				continue
+			}
+			errors = append(errors, ValidationError{
+				Rule:   r.Name(),
+				NodeID: node.UniqueID,
+				Message: fmt.Sprintf("interface %q claims %s, already claimed by interface %q on node %q",
+					intf.UniqueID, addr, prev.intf, prev.node),
+			})
+		}
+	}
+	return errors
+}
+
+func interfaceIDs(n *Node) []string {
+	ids := make([]string, len(n.Interfaces))
+	for i, intf := range n.Interfaces {
+		ids[i] = intf.UniqueID
+	}
+	return ids
+}
+
+// closestID returns the candidate with the smallest edit distance to id, or ""
+// when no candidate is within max(2, len(id)/3) edits, so that unrelated IDs
+// are not suggested. Ties go to the earlier candidate.
+func closestID(id string, candidates []string) string {
+	limit := max(2, len([]rune(id))/3)
+	best, bestDist := "", limit+1
+	for _, c := range candidates {
+		if d := editDistance(id, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
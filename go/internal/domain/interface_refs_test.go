@@ -0,0 +1,84 @@
+package domain
+
+import "testing"
+
+func TestInterfaceReferencesResolve(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	lb := a.DefineNode("lb", Service, "LB", "desc")
+	lb.Interface("lb-to-gateway", "HTTP")
+	gw := a.DefineNode("gw", Service, "Gateway", "desc")
+	gw.Interface("gw-http", "HTTP")
+	lb.ConnectTo(gw, "ok").WithID("ok").Via("lb-to-gateway", "gw-http")
+	lb.ConnectTo(gw, "typo").WithID("typo").Via("lb-to-gateway", "gw-htp")
+	lb.ConnectTo(gw, "wrong node").WithID("swapped").Via("gw-http", "")
+
+	errs := a.Validate(InterfaceReferencesResolve())
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	want := `destination interface "gw-htp" does not exist on node "gw"; did you mean "gw-http"?`
+	if errs[0].NodeID != "typo" || errs[0].Message != want {
+		t.Errorf("expected %q, got %v", want, errs[0])
+	}
+	want = `source interface "gw-http" does not exist on node "lb" (it is defined on node "gw")`
+	if errs[1].Message != want || errs[1].Code != "CALM019" {
+		t.Errorf("expected %q, got %v", want, errs[1])
+	}
+}
+
+func TestNoUnusedInterfaces(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	lb := a.DefineNode("lb", Service, "LB", "desc")
+	lb.Interface("lb-to-gateway", "HTTP")
+	gw := a.DefineNode("gw", Service, "Gateway", "desc", WithMeta(map[string]any{MetaHealthEndpoint: "/health"}))
+	gw.Interface("gw-http", "HTTP")
+	gw.Interface("gw-health", "HTTP").SetPath("/health")
+	lb.ConnectTo(gw, "calls").Via("lb-to-gateway", "")
+	a.DefineNode("idle", Service, "Idle", "desc").Interface("idle-http", "HTTP")
+
+	errs := a.Validate(NoUnusedInterfaces())
+	if len(errs) != 1 || errs[0].NodeID != "gw" || errs[0].Severity != SeverityWarning ||
+		errs[0].Message != `interface "gw-http" is not used by any relationship` {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestNoInterfaceAddressCollisions(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	gw := a.DefineNode("gw", Service, "Gateway", "desc")
+	gw.Interface("gw-http", "HTTP").SetPort(80)
+	gw.Interface("gw-admin", "HTTP").SetPort(80)
+	db1 := a.DefineNode("db1", Database, "DB1", "desc")
+	db1.Interface("db1-sql", "JDBC").SetHost("db.example.com").SetPort(5432)
+	db2 := a.DefineNode("db2", Database, "DB2", "desc")
+	db2.Interface("db2-sql", "JDBC").SetHost("DB.example.com").SetPort(5432)
+	a.DefineNode("other", Service, "Other", "desc").Interface("other-http", "HTTP").SetPort(80)
+
+	errs := a.Validate(NoInterfaceAddressCollisions())
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].NodeID != "gw" ||
+		errs[0].Message != `interface "gw-admin" claims port 80, already claimed by interface "gw-http" on node "gw"` {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+	want := `interface "db2-sql" claims DB.example.com:5432, already claimed by interface "db1-sql" on node "db1"`
+	if errs[1].NodeID != "db2" || errs[1].Message != want {
+		t.Errorf("unexpected error %v", errs[1])
+	}
+}
+
+func TestClosestID(t *testing.T) {
+	if got := closestID("order-apii", []string{"inventory-api", "order-api", "order-health"}); got != "order-api" {
+		t.Errorf("expected order-api, got %q", got)
+	}
+	if got := closestID("x", nil); got != "" {
+		t.Errorf("expected no suggestion, got %q", got)
+	}
+	if got := closestID("xyz", []string{"payment-api"}); got != "" {
+		t.Errorf("expected no suggestion for a distant ID, got %q", got)
+	}
+	if got := closestID("ab", []string{"xy"}); got != "xy" {
+		t.Errorf("expected short IDs to allow 2 edits, got %q", got)
+	}
+}
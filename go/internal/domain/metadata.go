@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Metadata is CALM metadata. CALM allows it to be an object or an array of
+// objects; both decode into the same map, and the MetadataLayout of the owning
+// architecture, node, relationship or flow remembers the array form so that it
+// is written back the way it was read.
+type Metadata map[string]any
+
+// MetadataLayout records metadata read in CALM's array form: the keys of each
+// array element. A nil layout is the object form.
+type MetadataLayout [][]string
+
+// UnmarshalJSON accepts the object and the array form. Like encoding/json for
+// plain maps, the decoded keys are added to an existing map.
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	meta, _, err := DecodeMetadata(data)
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(Metadata, len(meta))
+	}
+	for k, v := range meta {
+		(*m)[k] = v
+	}
+	return nil
+}
+
+// DecodeMetadata decodes metadata in either form and returns its layout, which
+// is nil for the object form. When a key appears in several array elements the
+// last value wins. Empty input and null decode to nil metadata.
+func DecodeMetadata(data []byte) (Metadata, MetadataLayout, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil, nil
+	}
+	if data[0] != '[' {
+		var meta Metadata
+		if err := json.Unmarshal(data, (*map[string]any)(&meta)); err != nil {
+			return nil, nil, fmt.Errorf("expected an object or an array of objects: %w", err)
+		}
+		return meta, nil, nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return nil, nil, err
+	}
+	meta := make(Metadata)
+	elemOf := make(map[string]int)
+	for i, raw := range elems {
+		var obj map[string]any
+		if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+			return nil, nil, fmt.Errorf("element %d: expected an object, got %s", i, raw)
+		}
+		for k, v := range obj {
+			meta[k] = v
+			elemOf[k] = i
+		}
+	}
+	layout := make(MetadataLayout, len(elems))
+	for _, k := range sortedKeys(meta) {
+		layout[elemOf[k]] = append(layout[elemOf[k]], k)
+	}
+	return meta, layout, nil
+}
+
+// Shaped returns the metadata in the form given by layout: the map itself for
+// the object form, or one object per layout element for the array form. Keys
+// missing from the layout, such as ones added after parsing, go into a final
+// element, and elements left without keys are dropped. Empty object-form
+// metadata is nil.
+func (m Metadata) Shaped(layout MetadataLayout) any {
+	if layout == nil {
+		if len(m) == 0 {
+			return nil
+		}
+		return map[string]any(m)
+	}
+
+	elems := []map[string]any{}
+	placed := make(map[string]bool, len(m))
+	for _, keys := range layout {
+		elem := make(map[string]any)
+		for _, k := range keys {
+			if v, ok := m[k]; ok && !placed[k] {
+				elem[k] = v
+				placed[k] = true
+			}
+		}
+		if len(elem) > 0 {
+			elems = append(elems, elem)
+		}
+	}
+	rest := make(map[string]any)
+	for k, v := range m {
+		if !placed[k] {
+			rest[k] = v
+		}
+	}
+	if len(rest) > 0 {
+		elems = append(elems, rest)
+	}
+	return elems
+}
+
+// The JSON methods below keep the metadata layout of parsed documents. The
+// object form marshals exactly like the plain struct; the array form is
+// written after the other fields.
+
+// UnmarshalJSON accepts metadata in either form and records its layout.
+func (a *Architecture) UnmarshalJSON(data []byte) error {
+	type plain Architecture
+	doc := struct {
+		*plain
+		Metadata json.RawMessage `json:"metadata"`
+	}{plain: (*plain)(a)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var err error
+	a.Metadata, a.MetadataLayout, err = DecodeMetadata(doc.Metadata)
+	return err
+}
+
+// MarshalJSON writes metadata in the form it was read in.
+func (a Architecture) MarshalJSON() ([]byte, error) {
+	type plain Architecture
+	if a.MetadataLayout == nil {
+		return json.Marshal(plain(a))
+	}
+	return json.Marshal(struct {
+		plain
+		Metadata any `json:"metadata"`
+	}{plain(a), a.Metadata.Shaped(a.MetadataLayout)})
+}
+
+// UnmarshalJSON accepts metadata in either form and records its layout.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	type plain Node
+	doc := struct {
+		*plain
+		Metadata json.RawMessage `json:"metadata"`
+	}{plain: (*plain)(n)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var err error
+	n.Metadata, n.MetadataLayout, err = DecodeMetadata(doc.Metadata)
+	return err
+}
+
+// MarshalJSON writes metadata in the form it was read in.
+func (n Node) MarshalJSON() ([]byte, error) {
+	type plain Node
+	if n.MetadataLayout == nil {
+		return json.Marshal(plain(n))
+	}
+	return json.Marshal(struct {
+		plain
+		Metadata any `json:"metadata"`
+	}{plain(n), n.Metadata.Shaped(n.MetadataLayout)})
+}
+
+// UnmarshalJSON accepts metadata in either form and records its layout.
+func (r *Relationship) UnmarshalJSON(data []byte) error {
+	type plain Relationship
+	doc := struct {
+		*plain
+		Metadata json.RawMessage `json:"metadata"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var err error
+	r.Metadata, r.MetadataLayout, err = DecodeMetadata(doc.Metadata)
+	return err
+}
+
+// MarshalJSON writes metadata in the form it was read in.
+func (r Relationship) MarshalJSON() ([]byte, error) {
+	type plain Relationship
+	if r.MetadataLayout == nil {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		Metadata any `json:"metadata"`
+	}{plain(r), r.Metadata.Shaped(r.MetadataLayout)})
+}
+
+// UnmarshalJSON accepts metadata in either form and records its layout.
+func (f *Flow) UnmarshalJSON(data []byte) error {
+	type plain Flow
+	doc := struct {
+		*plain
+		Metadata json.RawMessage `json:"metadata"`
+	}{plain: (*plain)(f)}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	var err error
+	f.Metadata, f.MetadataLayout, err = DecodeMetadata(doc.Metadata)
+	return err
+}
+
+// MarshalJSON writes metadata in the form it was read in.
+func (f Flow) MarshalJSON() ([]byte, error) {
+	type plain Flow
+	if f.MetadataLayout == nil {
+		return json.Marshal(plain(f))
+	}
+	return json.Marshal(struct {
+		plain
+		Metadata any `json:"metadata"`
+	}{plain(f), f.Metadata.Shaped(f.MetadataLayout)})
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMetadata(t *testing.T) {
+	t.Run("should decode the object form without a layout", func(t *testing.T) {
+		meta, layout, err := DecodeMetadata([]byte(`{"tier": "tier-1"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if meta["tier"] != "tier-1" || layout != nil {
+			t.Errorf("unexpected result %v %v", meta, layout)
+		}
+	})
+
+	t.Run("should merge the array form and record its layout", func(t *testing.T) {
+		meta, layout, err := DecodeMetadata([]byte(`[{"tier": "tier-1", "owner": "a"}, {"runbook": "r"}]`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(meta) != 3 || meta["runbook"] != "r" {
+			t.Errorf("unexpected metadata %v", meta)
+		}
+		if len(layout) != 2 || strings.Join(layout[0], ",") != "owner,tier" || layout[1][0] != "runbook" {
+			t.Errorf("unexpected layout %v", layout)
+		}
+	})
+
+	t.Run("should reject array elements that are not objects", func(t *testing.T) {
+		_, _, err := DecodeMetadata([]byte(`[{"tier": "tier-1"}, "oops"]`))
+		if err == nil || !strings.Contains(err.Error(), "element 1") {
+			t.Errorf("expected element error, got %v", err)
+		}
+	})
+}
+
+func TestMetadata_Shaped(t *testing.T) {
+	meta := Metadata{"tier": "tier-1", "owner": "a", "added": true}
+	got, err := json.Marshal(meta.Shaped(MetadataLayout{{"owner", "tier"}, {"removed"}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `[{"owner":"a","tier":"tier-1"},{"added":true}]`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if Metadata(nil).Shaped(nil) != nil {
+		t.Errorf("expected empty object-form metadata to be nil")
+	}
+}
+
+func TestMetadataLayout_JSONRoundTrip(t *testing.T) {
+	for _, doc := range []string{
+		`{"unique-id":"n","node-type":"service","name":"N","description":"d","metadata":{"tier":"tier-1"}}`,
+		`{"unique-id":"n","node-type":"service","name":"N","description":"d",` +
+			`"metadata":[{"tier":"tier-1"},{"owner":"a"}]}`,
+	} {
+		var n Node
+		if err := json.Unmarshal([]byte(doc), &n); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n.Metadata["tier"] != "tier-1" {
+			t.Errorf("metadata not decoded from %s", doc)
+		}
+		out, err := json.Marshal(&n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(out) != doc {
+			t.Errorf("expected %s, got %s", doc, out)
+		}
+	}
+}
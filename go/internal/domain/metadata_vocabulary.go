@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetaType is the type of a metadata value, named after its JSON Schema type.
+type MetaType string
+
+const (
+	MetaTypeString     MetaType = "string"
+	MetaTypeInteger    MetaType = "integer"
+	MetaTypeNumber     MetaType = "number"
+	MetaTypeBoolean    MetaType = "boolean"
+	MetaTypeObject     MetaType = "object"
+	MetaTypeList       MetaType = "array"
+	MetaTypeStringList MetaType = "string-list"
+)
+
+// Well-known node metadata keys.
+const (
+	MetaTier                = "tier"
+	MetaBusinessCriticality = "business-criticality"
+	MetaOwner               = "owner"
+	MetaOncallSlack         = "oncall-slack"
+	MetaHealthEndpoint      = "health-endpoint"
+	MetaRunbook             = "runbook"
+	MetaDashboard           = "dashboard"
+	MetaBackupSchedule      = "backup-schedule"
+	MetaRestoreTime         = "restore-time"
+	MetaDeploymentType      = "deployment-type"
+	MetaDataClassification  = "data-classification"
+)
+
+// Tier is the service tier of a node (metadata "tier").
+type Tier string
+
+const (
+	Tier1 Tier = "tier-1"
+	Tier2 Tier = "tier-2"
+	Tier3 Tier = "tier-3"
+)
+
+// Criticality is the business criticality of a node (metadata "business-criticality").
+type Criticality string
+
+const (
+	CriticalityLow      Criticality = "low"
+	CriticalityMedium   Criticality = "medium"
+	CriticalityHigh     Criticality = "high"
+	CriticalityCritical Criticality = "critical"
+)
+
+// DeploymentType is how a node is deployed (metadata "deployment-type").
+type DeploymentType string
+
+const (
+	DeployContainer      DeploymentType = "container"
+	DeployManagedService DeploymentType = "managed-service"
+	DeployServerless     DeploymentType = "serverless"
+	DeployVirtualMachine DeploymentType = "virtual-machine"
+)
+
+// MetaKey declares a node metadata key: its value type, the allowed values
+// (strings only) and the node types it applies to (all when empty).
+type MetaKey struct {
+	Name        string
+	Type        MetaType
+	Description string
+	Enum        []string
+	NodeTypes   []NodeType
+}
+
+// AppliesTo reports whether the key may be used on nodes of type t.
+func (k MetaKey) AppliesTo(t NodeType) bool {
+	if len(k.NodeTypes) == 0 {
+		return true
+	}
+	for _, nt := range k.NodeTypes {
+		if nt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports why value is not a valid value for the key, or nil. Values are
+// compared in their JSON form, so []string and []any are both lists.
+func (k MetaKey) Check(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if !metaTypeMatches(k.Type, v) {
+		return fmt.Errorf("must be of type %s, got %s", k.Type, string(data))
+	}
+	if s, ok := v.(string); ok && len(k.Enum) > 0 {
+		for _, e := range k.Enum {
+			if e == s {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %q, got %q", k.Enum, s)
+	}
+	return nil
+}
+
+func metaTypeMatches(t MetaType, v any) bool {
+	switch t {
+	case MetaTypeString:
+		_, ok := v.(string)
+		return ok
+	case MetaTypeInteger:
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case MetaTypeNumber:
+		_, ok := v.(float64)
+		return ok
+	case MetaTypeBoolean:
+		_, ok := v.(bool)
+		return ok
+	case MetaTypeObject:
+		_, ok := v.(map[string]any)
+		return ok
+	case MetaTypeList:
+		_, ok := v.([]any)
+		return ok
+	case MetaTypeStringList:
+		list, ok := v.([]any)
+		for _, item := range list {
+			if _, isString := item.(string); !isString {
+				return false
+			}
+		}
+		return ok
+	}
+	return true
+}
+
+var (
+	metaKeysMu sync.RWMutex
+	metaKeys   = make(map[string]MetaKey)
+)
+
+func init() {
+	tiers := []string{string(Tier1), string(Tier2), string(Tier3)}
+	RegisterMetaKey(
+		MetaKey{Name: MetaTier, Type: MetaTypeString, Description: "Service tier", Enum: tiers},
+		MetaKey{Name: "sla-tier", Type: MetaTypeString, Description: "Tier of the SLA offered", Enum: tiers},
+		MetaKey{
+			Name: MetaBusinessCriticality, Type: MetaTypeString, Description: "Business criticality",
+			Enum: []string{
+				string(CriticalityLow), string(CriticalityMedium), string(CriticalityHigh), string(CriticalityCritical),
+			},
+		},
+		MetaKey{Name: MetaOwner, Type: MetaTypeString, Description: "Owning team"},
+		MetaKey{Name: "tech-owner", Type: MetaTypeString, Description: "Team owning the technology"},
+		MetaKey{Name: MetaOncallSlack, Type: MetaTypeString, Description: "On-call Slack channel"},
+		MetaKey{
+			Name: MetaHealthEndpoint, Type: MetaTypeString, Description: "Health check path",
+			NodeTypes: []NodeType{Service, System},
+		},
+		MetaKey{Name: MetaRunbook, Type: MetaTypeString, Description: "Runbook URL"},
+		MetaKey{Name: MetaDashboard, Type: MetaTypeString, Description: "Monitoring dashboard URL"},
+		MetaKey{Name: "log-query", Type: MetaTypeString, Description: "Query locating the node's logs"},
+		MetaKey{Name: "alerts", Type: MetaTypeStringList, Description: "Alerts defined for the node"},
+		MetaKey{Name: "failure-modes", Type: MetaTypeList, Description: "Known failure modes and their checks"},
+		MetaKey{Name: "dependencies", Type: MetaTypeStringList, Description: "IDs of nodes this node depends on"},
+		MetaKey{Name: "repository", Type: MetaTypeString, Description: "Source repository URL"},
+		MetaKey{Name: "adr", Type: MetaTypeString, Description: "Architecture decision record"},
+		MetaKey{Name: "tags", Type: MetaTypeStringList, Description: "Free-form tags"},
+		MetaKey{Name: "ha-enabled", Type: MetaTypeBoolean, Description: "Whether the node runs highly available"},
+		MetaKey{
+			Name: MetaDeploymentType, Type: MetaTypeString, Description: "How the node is deployed",
+			Enum: []string{
+				string(DeployContainer), string(DeployManagedService),
+				string(DeployServerless), string(DeployVirtualMachine),
+			},
+		},
+		MetaKey{
+			Name: MetaDataClassification, Type: MetaTypeString, Description: "Classification of the data held",
+			Enum: []string{"public", "internal", "confidential", "PII", "PCI"},
+		},
+		MetaKey{
+			Name: MetaBackupSchedule, Type: MetaTypeString, Description: "When backups run",
+			NodeTypes: []NodeType{Database},
+		},
+		MetaKey{
+			Name: MetaRestoreTime, Type: MetaTypeString, Description: "Time to restore from backup",
+			NodeTypes: []NodeType{Database},
+		},
+		MetaKey{
+			Name: "replication-mode", Type: MetaTypeString, Description: "Database replication mode",
+			Enum: []string{"sync", "async"}, NodeTypes: []NodeType{Database},
+		},
+		MetaKey{
+			Name: "role", Type: MetaTypeString, Description: "Role of a database instance",
+			Enum: []string{"primary", "replica"}, NodeTypes: []NodeType{Database},
+		},
+		MetaKey{
+			Name: "dba-contact", Type: MetaTypeString, Description: "DBA contact address",
+			NodeTypes: []NodeType{Database},
+		},
+	)
+}
+
+// RegisterMetaKey declares node metadata keys so that MetadataMatchesVocabulary
+// accepts them. A key registered again replaces the earlier declaration.
+func RegisterMetaKey(keys ...MetaKey) {
+	metaKeysMu.Lock()
+	defer metaKeysMu.Unlock()
+	for _, k := range keys {
+		metaKeys[k.Name] = k
+	}
+}
+
+// UnregisterMetaKey removes metadata key declarations, e.g. in test cleanup.
+func UnregisterMetaKey(names ...string) {
+	metaKeysMu.Lock()
+	defer metaKeysMu.Unlock()
+	for _, name := range names {
+		delete(metaKeys, name)
+	}
+}
+
+// LookupMetaKey returns the declaration of a metadata key.
+func LookupMetaKey(name string) (MetaKey, bool) {
+	metaKeysMu.RLock()
+	defer metaKeysMu.RUnlock()
+	k, ok := metaKeys[name]
+	return k, ok
+}
+
+// MetaKeys returns every declared metadata key, sorted by name.
+func MetaKeys() []MetaKey {
+	metaKeysMu.RLock()
+	keys := make([]MetaKey, 0, len(metaKeys))
+	for _, k := range metaKeys {
+		keys = append(keys, k)
+	}
+	metaKeysMu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys
+}
+
+// --- Typed metadata options ---
+
+// WithTier sets the "tier" metadata.
+func WithTier(t Tier) NodeOption {
+	return func(n *Node) { n.Metadata[MetaTier] = string(t) }
+}
+
+// WithCriticality sets the "business-criticality" metadata.
+func WithCriticality(c Criticality) NodeOption {
+	return func(n *Node) { n.Metadata[MetaBusinessCriticality] = string(c) }
+}
+
+// WithDeploymentType sets the "deployment-type" metadata.
+func WithDeploymentType(d DeploymentType) NodeOption {
+	return func(n *Node) { n.Metadata[MetaDeploymentType] = string(d) }
+}
+
+// WithHealthEndpoint sets the "health-endpoint" metadata, e.g. "/health".
+func WithHealthEndpoint(path string) NodeOption {
+	return func(n *Node) { n.Metadata[MetaHealthEndpoint] = path }
+}
+
+// WithRunbook sets the "runbook" metadata.
+func WithRunbook(url string) NodeOption {
+	return func(n *Node) { n.Metadata[MetaRunbook] = url }
+}
+
+// WithOncall sets the "oncall-slack" metadata.
+func WithOncall(slackChannel string) NodeOption {
+	return func(n *Node) { n.Metadata[MetaOncallSlack] = slackChannel }
+}
+
+// WithBackupSchedule sets the "backup-schedule" and "restore-time" metadata of
+// a database; an empty restoreTime is left unset.
+func WithBackupSchedule(schedule, restoreTime string) NodeOption {
+	return func(n *Node) {
+		n.Metadata[MetaBackupSchedule] = schedule
+		if restoreTime != "" {
+			n.Metadata[MetaRestoreTime] = restoreTime
+		}
+	}
+}
+
+// metadataMatchesVocabulary checks node metadata against the declared keys
+type metadataMatchesVocabulary struct{}
+
+func MetadataMatchesVocabulary() ValidationRule { return metadataMatchesVocabulary{} }
+
+func (r metadataMatchesVocabulary) Name() string { return "MetadataMatchesVocabulary" }
+
+func (r metadataMatchesVocabulary) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, node := range a.Nodes {
+		for _, name := range sortedKeys(node.Metadata) {
+			var msg string
+			key, ok := LookupMetaKey(name)
+			switch {
+			case !ok:
+				msg = fmt.Sprintf("unknown metadata key %q (use RegisterMetaKey to declare it)", name)
+			case !key.AppliesTo(node.NodeType):
+				msg = fmt.Sprintf("metadata %q does not apply to %s nodes (only %s)",
+					name, node.NodeType, joinNodeTypes(key.NodeTypes))
+			default:
+				if err := key.Check(node.Metadata[name]); err != nil {
+					msg = fmt.Sprintf("metadata %q %v", name, err)
+				}
+			}
+			if msg != "" {
+				errors = append(errors, ValidationError{Rule: r.Name(), NodeID: node.UniqueID, Message: msg})
+			}
+		}
+	}
+	return errors
+}
+
+func joinNodeTypes(types []NodeType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
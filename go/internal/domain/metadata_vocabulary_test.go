@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetadataMatchesVocabulary(t *testing.T) {
+	t.Run("should accept typed helpers and declared keys", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		arch.DefineNode("api", Service, "API", "desc",
+			WithTier(Tier1), WithCriticality(CriticalityHigh), WithDeploymentType(DeployContainer),
+			WithHealthEndpoint("/health"), WithRunbook("https://runbooks"), WithOncall("#oncall"),
+			WithTags("edge"), WithMeta(map[string]any{"alerts": []any{"5xx"}, "ha-enabled": true}))
+		arch.DefineNode("db", Database, "DB", "desc", WithBackupSchedule("daily", "1h"))
+
+		if errs := arch.Validate(MetadataMatchesVocabulary()); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("should flag unknown, mistyped and misplaced keys", func(t *testing.T) {
+		arch := NewArchitecture("a", "A", "desc")
+		arch.DefineNode("api", Service, "API", "desc", WithMeta(map[string]any{
+			"tier":            "gold",
+			"ha-enabled":      "yes",
+			"alerts":          []any{"5xx", 3},
+			"backup-schedule": "daily",
+			"colour":          "blue",
+		}))
+
+		errs := arch.Validate(MetadataMatchesVocabulary())
+		want := []string{
+			`metadata "alerts" must be of type string-list`,
+			`metadata "backup-schedule" does not apply to service nodes (only database)`,
+			`unknown metadata key "colour"`,
+			`metadata "ha-enabled" must be of type boolean, got "yes"`,
+			`metadata "tier" must be one of ["tier-1" "tier-2" "tier-3"], got "gold"`,
+		}
+		if len(errs) != len(want) {
+			t.Fatalf("expected %d errors, got %v", len(want), errs)
+		}
+		for i, w := range want {
+			if !strings.Contains(errs[i].Message, w) || errs[i].NodeID != "api" {
+				t.Errorf("expected %q, got %v", w, errs[i])
+			}
+		}
+	})
+
+	t.Run("should accept registered keys", func(t *testing.T) {
+		RegisterMetaKey(MetaKey{Name: "test-cost-code", Type: MetaTypeInteger, NodeTypes: []NodeType{Service}})
+		t.Cleanup(func() { UnregisterMetaKey("test-cost-code") })
+		arch := NewArchitecture("a", "A", "desc")
+		arch.DefineNode("api", Service, "API", "desc", WithMeta(map[string]any{"test-cost-code": 42}))
+		arch.DefineNode("q", Queue, "Q", "desc", WithMeta(map[string]any{"test-cost-code": 4.2}))
+
+		errs := arch.Validate(MetadataMatchesVocabulary())
+		if len(errs) != 1 || errs[0].NodeID != "q" {
+			t.Errorf("expected only the queue to be flagged, got %v", errs)
+		}
+	})
+}
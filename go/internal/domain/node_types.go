@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"sort"
+	"sync"
+)
+
+// builtinNodeTypes maps the CALM node types to their Go constant names.
+var builtinNodeTypes = map[NodeType]string{
+	Actor:     "Actor",
+	Service:   "Service",
+	Database:  "Database",
+	System:    "System",
+	Queue:     "Queue",
+	WebClient: "WebClient",
+	Ecosystem: "Ecosystem",
+	Network:   "Network",
+	LDAP:      "LDAP",
+	DataAsset: "DataAsset",
+}
+
+var (
+	customNodeTypesMu sync.RWMutex
+	customNodeTypes   = make(map[NodeType]bool)
+)
+
+// RegisterNodeType registers a company-specific node type (e.g. "mainframe")
+// so that it is accepted by NoUnknownNodeTypes.
+func RegisterNodeType(t NodeType) {
+	customNodeTypesMu.Lock()
+	defer customNodeTypesMu.Unlock()
+	customNodeTypes[t] = true
+}
+
+// UnregisterNodeType removes a node type added with RegisterNodeType, e.g. in
+// test cleanup. Built-in node types cannot be removed.
+func UnregisterNodeType(t NodeType) {
+	customNodeTypesMu.Lock()
+	defer customNodeTypesMu.Unlock()
+	delete(customNodeTypes, t)
+}
+
+// IsKnownNodeType reports whether t is a built-in or registered node type.
+func IsKnownNodeType(t NodeType) bool {
+	if _, ok := builtinNodeTypes[t]; ok {
+		return true
+	}
+	customNodeTypesMu.RLock()
+	defer customNodeTypesMu.RUnlock()
+	return customNodeTypes[t]
+}
+
+// NodeTypes returns all built-in and registered node types in sorted order.
+func NodeTypes() []NodeType {
+	customNodeTypesMu.RLock()
+	types := make([]NodeType, 0, len(builtinNodeTypes)+len(customNodeTypes))
+	for t := range customNodeTypes {
+		types = append(types, t)
+	}
+	customNodeTypesMu.RUnlock()
+
+	for t := range builtinNodeTypes {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// NodeTypeConstName returns the Go constant name of a built-in node type
+// (e.g. DataAsset for "data-asset"). Custom types have no constant.
+func NodeTypeConstName(t NodeType) (string, bool) {
+	name, ok := builtinNodeTypes[t]
+	return name, ok
+}
+
+// NodeTypeFromConstName is the inverse of NodeTypeConstName.
+func NodeTypeFromConstName(name string) (NodeType, bool) {
+	for t, n := range builtinNodeTypes {
+		if n == name {
+			return t, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,363 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Policy is a set of declarative validation rules, usually loaded from a
+// policy file so that a team can add a check without changing Go code.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+	// Severities overrides the default severity of other rules by rule name or
+	// code, e.g. {"CALM017": "warning"}. Findings a rule reports with a
+	// severity of its own keep it.
+	Severities map[string]Severity `json:"severities,omitempty"`
+}
+
+// PolicyRule selects architecture elements and asserts properties of them.
+//
+// Fields are addressed by their CALM JSON name ("unique-id", "owner",
+// "protocol", "dataClassification", "encrypted", "relationship-type",
+// "source", "destination", ...) or as "metadata.<key>". Patterns are regular
+// expressions that must match the whole value; a list value matches a where
+// pattern when any item does, and a required pattern when every item does.
+type PolicyRule struct {
+	Name        string         `json:"name"`
+	Code        string         `json:"code,omitempty"` // stable code reported with findings
+	Description string         `json:"description,omitempty"`
+	Severity    Severity       `json:"severity,omitempty"`
+	Select      PolicySelector `json:"select"`
+	// Required lists the fields every selected element must have.
+	Required []string `json:"required,omitempty"`
+	// Patterns maps fields to the pattern their value must match when present.
+	Patterns map[string]string `json:"patterns,omitempty"`
+}
+
+// PolicySelector chooses the elements a rule applies to. Empty criteria match
+// every element of the kind.
+type PolicySelector struct {
+	Kind              ElementKind `json:"kind,omitempty"` // defaults to KindNode
+	NodeTypes         []NodeType  `json:"node-types,omitempty"`
+	RelationshipTypes []string    `json:"relationship-types,omitempty"`
+	// Where maps fields to the pattern their value must match.
+	Where map[string]string `json:"where,omitempty"`
+}
+
+// ParsePolicy decodes a JSON policy document. Unknown keys are rejected so
+// that a misspelled selector does not silently match every element.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := decodeStrict(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ValidationRules compiles the policy into validation rules that run next to
+// the built-in ones.
+func (p *Policy) ValidationRules() ([]ValidationRule, error) {
+	rules := make([]ValidationRule, 0, len(p.Rules))
+	seen := make(map[string]bool, len(p.Rules))
+	for i, def := range p.Rules {
+		if def.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i)
+		}
+		if seen[def.Name] {
+			return nil, fmt.Errorf("rule %q: defined more than once", def.Name)
+		}
+		seen[def.Name] = true
+		rule, err := compilePolicyRule(def)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", def.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ApplySeverities returns rules with the severity overrides of the policy
+// applied. Every overridden name or code must belong to one of rules.
+func (p *Policy) ApplySeverities(rules []ValidationRule) ([]ValidationRule, error) {
+	if len(p.Severities) == 0 {
+		return rules, nil
+	}
+	out := append([]ValidationRule(nil), rules...)
+	for _, key := range slices.Sorted(maps.Keys(p.Severities)) {
+		severity := p.Severities[key]
+		if !severity.Valid() {
+			return nil, fmt.Errorf("severities: %q: unknown severity %q (want error, warning or info)", key, severity)
+		}
+		found := false
+		for i, rule := range out {
+			info, _ := LookupRuleInfo(rule.Name())
+			if rule.Name() != key && (info.Code == "" || info.Code != key) {
+				continue
+			}
+			out[i] = severityOverride{ValidationRule: rule, severity: severity}
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("severities: no rule named %q", key)
+		}
+	}
+	return out, nil
+}
+
+// severityOverride reports the findings of a rule with another default
+// severity.
+type severityOverride struct {
+	ValidationRule
+	severity Severity
+}
+
+func (r severityOverride) Validate(a *Architecture) []ValidationError {
+	errs := r.ValidationRule.Validate(a)
+	for i := range errs {
+		if errs[i].Severity == "" {
+			errs[i].Severity = r.severity
+		}
+	}
+	return errs
+}
+
+var relationshipTypeNames = []string{"connects", "interacts", "composed-of", "deployed-in", "options"}
+
+type policyRule struct {
+	def      PolicyRule
+	kind     ElementKind
+	where    map[string]*regexp.Regexp
+	patterns map[string]*regexp.Regexp
+}
+
+func compilePolicyRule(def PolicyRule) (policyRule, error) {
+	r := policyRule{def: def, kind: def.Select.Kind}
+	if r.kind == "" {
+		r.kind = KindNode
+	}
+	switch r.kind {
+	case KindNode, KindRelationship, KindFlow:
+	default:
+		return r, fmt.Errorf("unknown kind %q (want node, relationship or flow)", r.kind)
+	}
+	if !def.Severity.Valid() {
+		return r, fmt.Errorf("unknown severity %q (want error, warning or info)", def.Severity)
+	}
+	if len(def.Select.NodeTypes) > 0 && r.kind != KindNode {
+		return r, fmt.Errorf("node-types only applies to nodes")
+	}
+	if len(def.Select.RelationshipTypes) > 0 && r.kind != KindRelationship {
+		return r, fmt.Errorf("relationship-types only applies to relationships")
+	}
+	for _, t := range def.Select.RelationshipTypes {
+		if !containsString(relationshipTypeNames, t) {
+			return r, fmt.Errorf("unknown relationship type %q", t)
+		}
+	}
+
+	var err error
+	if r.where, err = compilePatterns(def.Select.Where); err != nil {
+		return r, fmt.Errorf("where %w", err)
+	}
+	if r.patterns, err = compilePatterns(def.Patterns); err != nil {
+		return r, fmt.Errorf("patterns %w", err)
+	}
+	return r, nil
+}
+
+func compilePatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(patterns))
+	for field, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", field, err)
+		}
+		compiled[field] = re
+	}
+	return compiled, nil
+}
+
+func (r policyRule) Name() string { return r.def.Name }
+
+func (r policyRule) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, e := range r.elements(a) {
+		if !r.selects(e) {
+			continue
+		}
+		for _, msg := range r.check(e) {
+			if r.def.Description != "" {
+				msg = r.def.Description + ": " + msg
+			}
+			severity := r.def.Severity
+			if severity == "" {
+				severity = SeverityError
+			}
+			errors = append(errors, ValidationError{
+				Rule: r.Name(), Code: r.def.Code, NodeID: e.id, Kind: r.kind, Message: msg, Severity: severity,
+			})
+		}
+	}
+	return errors
+}
+
+// policyElement is the view of a node, relationship or flow that policy rules
+// inspect: its ID, type and fields by name.
+type policyElement struct {
+	id       string
+	typeName string
+	fields   map[string]any
+}
+
+func (r policyRule) elements(a *Architecture) []policyElement {
+	var elems []policyElement
+	switch r.kind {
+	case KindNode:
+		for _, n := range a.Nodes {
+			fields := map[string]any{
+				"unique-id": n.UniqueID, "node-type": string(n.NodeType), "name": n.Name,
+				"description": n.Description, "owner": n.Owner, "costCenter": n.CostCenter,
+			}
+			addMetadataFields(fields, n.Metadata)
+			elems = append(elems, policyElement{id: n.UniqueID, typeName: string(n.NodeType), fields: fields})
+		}
+	case KindRelationship:
+		for _, rel := range a.Relationships {
+			typeName := relationshipTypeName(rel)
+			fields := map[string]any{
+				"unique-id": rel.UniqueID, "description": rel.Description, "protocol": rel.Protocol,
+				"dataClassification": rel.DataClassification, "relationship-type": typeName,
+			}
+			if rel.Encrypted != nil {
+				fields["encrypted"] = *rel.Encrypted
+			}
+			if src, dst := relationshipEnds(rel); src != "" {
+				fields["source"], fields["destination"] = src, dst
+			}
+			addMetadataFields(fields, rel.Metadata)
+			elems = append(elems, policyElement{id: rel.UniqueID, typeName: typeName, fields: fields})
+		}
+	case KindFlow:
+		for _, f := range a.Flows {
+			fields := map[string]any{"unique-id": f.UniqueID, "name": f.Name, "description": f.Description}
+			addMetadataFields(fields, f.Metadata)
+			elems = append(elems, policyElement{id: f.UniqueID, fields: fields})
+		}
+	}
+	return elems
+}
+
+func addMetadataFields(fields map[string]any, meta Metadata) {
+	for k, v := range meta {
+		fields["metadata."+k] = v
+	}
+}
+
+func relationshipTypeName(r *Relationship) string {
+	rt := r.RelationshipType
+	switch {
+	case rt.Connects != nil:
+		return "connects"
+	case rt.Interacts != nil:
+		return "interacts"
+	case rt.ComposedOf != nil:
+		return "composed-of"
+	case rt.DeployedIn != nil:
+		return "deployed-in"
+	case rt.Options != nil:
+		return "options"
+	}
+	return ""
+}
+
+func (r policyRule) selects(e policyElement) bool {
+	sel := r.def.Select
+	if len(sel.NodeTypes) > 0 {
+		types := make([]string, len(sel.NodeTypes))
+		for i, t := range sel.NodeTypes {
+			types[i] = string(t)
+		}
+		if !containsString(types, e.typeName) {
+			return false
+		}
+	}
+	if len(sel.RelationshipTypes) > 0 && !containsString(sel.RelationshipTypes, e.typeName) {
+		return false
+	}
+	for field, re := range r.where {
+		values, ok := fieldValues(e.fields, field)
+		if !ok || !anyMatch(re, values) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r policyRule) check(e policyElement) []string {
+	var msgs []string
+	for _, field := range r.def.Required {
+		if _, ok := fieldValues(e.fields, field); !ok {
+			msgs = append(msgs, fmt.Sprintf("missing required %s", field))
+		}
+	}
+	fields := make([]string, 0, len(r.patterns))
+	for field := range r.patterns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		values, ok := fieldValues(e.fields, field)
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			if !r.patterns[field].MatchString(v) {
+				msgs = append(msgs, fmt.Sprintf("%s %q does not match %q", field, v, r.def.Patterns[field]))
+				break
+			}
+		}
+	}
+	return msgs
+}
+
+// fieldValues returns the value of a field as strings, one per list item.
+// Missing fields and empty strings are absent.
+func fieldValues(fields map[string]any, name string) ([]string, bool) {
+	v, ok := fields[name]
+	if !ok || v == nil || v == "" {
+		return nil, false
+	}
+	switch val := v.(type) {
+	case []string:
+		return val, true
+	case []any:
+		values := make([]string, len(val))
+		for i, item := range val {
+			values[i] = fmt.Sprint(item)
+		}
+		return values, true
+	}
+	return []string{fmt.Sprint(v)}, true
+}
+
+func anyMatch(re *regexp.Regexp, values []string) bool {
+	for _, v := range values {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
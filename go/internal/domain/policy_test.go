@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func compilePolicy(t *testing.T, doc string) []ValidationRule {
+	t.Helper()
+	p, err := ParsePolicy([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	rules, err := p.ValidationRules()
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	return rules
+}
+
+func TestPolicy_ValidationRules(t *testing.T) {
+	t.Run("should check required fields and patterns of selected nodes", func(t *testing.T) {
+		rules := compilePolicy(t, `{"rules": [{
+			"name": "tier-1-runbook", "description": "tier-1 needs a runbook", "severity": "warning",
+			"select": {"node-types": ["service"], "where": {"metadata.tier": "tier-1"}},
+			"required": ["metadata.runbook"], "patterns": {"metadata.runbook": "https://.+"}
+		}]}`)
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("api", Service, "API", "desc", WithTier(Tier1), WithRunbook("http://runbooks/api"))
+		a.DefineNode("worker", Service, "Worker", "desc", WithTier(Tier1))
+		a.DefineNode("batch", Service, "Batch", "desc", WithMeta(map[string]any{"tier": "tier-3"}))
+		a.DefineNode("db", Database, "DB", "desc", WithTier(Tier1))
+		errs := a.Validate(rules...)
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].NodeID != "api" || errs[0].Message !=
+			`tier-1 needs a runbook: metadata.runbook "http://runbooks/api" does not match "https://.+"` {
+			t.Errorf("unexpected pattern error %v", errs[0])
+		}
+		if errs[1].NodeID != "worker" || !strings.Contains(errs[1].Message, "missing required metadata.runbook") {
+			t.Errorf("unexpected required error %v", errs[1])
+		}
+		if errs[0].Rule != "tier-1-runbook" || errs[0].Severity != SeverityWarning {
+			t.Errorf("expected rule name and severity on %v", errs[0])
+		}
+	})
+
+	t.Run("should select relationships by attributes", func(t *testing.T) {
+		rules := compilePolicy(t, `{"rules": [{
+			"name": "encrypt-pii",
+			"select": {"kind": "relationship", "relationship-types": ["connects"],
+				"where": {"dataClassification": "PII|PCI"}},
+			"patterns": {"encrypted": "true"}
+		}]}`)
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		worker := a.DefineNode("worker", Service, "Worker", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		api.ConnectTo(db, "reads").Is("PII").Encrypted(false)
+		api.ConnectTo(worker, "calls").Is("internal")
+		errs := a.Validate(rules...)
+		if len(errs) != 1 || errs[0].NodeID != "api-connects-db" || errs[0].Severity != SeverityError {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		for _, tc := range []struct{ doc, want string }{
+			{`{"rules": [{"select": {}}]}`, "name is required"},
+			{`{"rules": [{"name": "a"}, {"name": "a"}]}`, "defined more than once"},
+			{`{"rules": [{"name": "a", "select": {"kind": "port"}}]}`, `unknown kind "port"`},
+			{`{"rules": [{"name": "a", "severity": "fatal"}]}`, `unknown severity "fatal"`},
+			{`{"rules": [{"name": "a", "select": {"kind": "flow", "node-types": ["service"]}}]}`,
+				"only applies to nodes"},
+			{`{"rules": [{"name": "a", "select": {"kind": "relationship", "relationship-types": ["uses"]}}]}`,
+				`unknown relationship type "uses"`},
+			{`{"rules": [{"name": "a", "patterns": {"owner": "("}}]}`, `patterns "owner"`},
+		} {
+			p, err := ParsePolicy([]byte(tc.doc))
+			if err == nil {
+				_, err = p.ValidationRules()
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("%s: expected error containing %q, got %v", tc.doc, tc.want, err)
+			}
+		}
+	})
+
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		if _, err := ParsePolicy([]byte(`{"rules": [{"name": "a", "selector": {}}]}`)); err == nil {
+			t.Errorf("expected an error for a misspelled key")
+		}
+	})
+}
+
+func TestPolicy_ApplySeverities(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	api := a.DefineNode("api", Service, "API", "desc")
+	api.Interface("api-jdbc", "JDBC")
+	db := a.DefineNode("db", Database, "DB", "desc")
+	db.Interface("db-sql", "JDBC")
+	api.ConnectTo(db, "reads").WithID("plain").Via("api-jdbc", "db-sql")
+
+	t.Run("should lower the default severity by code", func(t *testing.T) {
+		p, err := ParsePolicy([]byte(`{"rules": [], "severities": {"CALM017": "warning"}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules, err := p.ApplySeverities([]ValidationRule{NoPlaintextProtocols(), AllNodesHaveOwner()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		errs := a.Validate(rules...)
+		if len(errs) != 3 || errs[0].Rule != "NoPlaintextProtocols" || errs[0].Severity != SeverityWarning {
+			t.Errorf("expected a CALM017 warning, got %v", errs)
+		}
+		if errs[1].Severity != SeverityError {
+			t.Errorf("expected other rules to keep their severity, got %v", errs[1])
+		}
+	})
+
+	t.Run("should keep severities the rule sets itself", func(t *testing.T) {
+		tls := NewArchitecture("a", "A", "desc")
+		tls.AddControl("security", "TLS", NewRequirementURL("https://policy.example.com/tls", ""))
+		api := tls.DefineNode("api", Service, "API", "desc")
+		api.ConnectTo(tls.DefineNode("db", Database, "DB", "desc"), "reads").Protocol("JDBC")
+
+		p := &Policy{Severities: map[string]Severity{"NoPlaintextProtocols": SeverityInfo}}
+		rules, err := p.ApplySeverities([]ValidationRule{NoPlaintextProtocols()})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if errs := tls.Validate(rules...); len(errs) != 1 || errs[0].Severity != SeverityError {
+			t.Errorf("expected the TLS-mandated finding to stay an error, got %v", errs)
+		}
+	})
+
+	t.Run("should reject unknown rules and severities", func(t *testing.T) {
+		for _, tc := range []struct {
+			severities map[string]Severity
+			want       string
+		}{
+			{map[string]Severity{"CALM999": SeverityWarning}, `no rule named "CALM999"`},
+			{map[string]Severity{"CALM017": "fatal"}, `unknown severity "fatal"`},
+		} {
+			p := &Policy{Severities: tc.severities}
+			_, err := p.ApplySeverities([]ValidationRule{NoPlaintextProtocols()})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("%v: expected error containing %q, got %v", tc.severities, tc.want, err)
+			}
+		}
+	})
+}
@@ -23,3 +23,16 @@ type LayoutRepository interface {
 	Load(id string) (*ArchitectureLayout, error)
 	Save(id string, layout *ArchitectureLayout) error
 }
+
+// SchemaValidator checks documents against JSON schemas referenced by URL.
+// It returns the schema violations found in doc, or an error when the schema
+// cannot be resolved or loaded.
+type SchemaValidator interface {
+	ValidateURL(url string, doc any) ([]string, error)
+}
+
+// ConfigLoader loads the configuration document a control's config-url points
+// to.
+type ConfigLoader interface {
+	LoadConfig(url string) (any, error)
+}
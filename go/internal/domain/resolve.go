@@ -0,0 +1,229 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// Resolve turns options relationships into a concrete architecture.
+// choices maps an options relationship ID to the chosen decision, given either
+// by its description or by its 1-based position. For every resolved options
+// relationship, nodes and relationships that belong only to the rejected
+// decisions are removed, together with anything that references them, and the
+// options relationship itself is dropped. Options without a choice are kept.
+// The architecture is left untouched when any choice is invalid.
+//
+// Resolve replaces the node, relationship and flow lists of a but does not
+// modify the lists or elements it had before; see Resolved to keep a as is.
+func (a *Architecture) Resolve(choices map[string]string) error {
+	r, err := a.Resolved(choices)
+	if err != nil {
+		return err
+	}
+	a.Nodes, a.Relationships, a.Flows, a.origins = r.Nodes, r.Relationships, r.Flows, r.origins
+	return nil
+}
+
+// Resolved returns a copy of the architecture with choices applied as
+// described for Resolve, leaving a untouched so that it can be resolved again,
+// e.g. when a builder returns the same architecture every time. Elements that
+// are not pruned are shared with a.
+func (a *Architecture) Resolved(choices map[string]string) (*Architecture, error) {
+	optionsByID := make(map[string]*Relationship)
+	for _, rel := range a.Relationships {
+		if rel.RelationshipType.Options != nil {
+			optionsByID[rel.UniqueID] = rel
+		}
+	}
+
+	chosen := make(map[string]int, len(choices))
+	for id, choice := range choices {
+		rel, ok := optionsByID[id]
+		if !ok {
+			return nil, fmt.Errorf("options relationship %q not found", id)
+		}
+		idx, err := pickDecision(rel, choice)
+		if err != nil {
+			return nil, err
+		}
+		chosen[id] = idx
+	}
+
+	keptNodes := make(map[string]bool)
+	keptRels := make(map[string]bool)
+	for id, idx := range chosen {
+		d := optionsByID[id].RelationshipType.Options[idx]
+		for _, n := range d.Nodes {
+			keptNodes[n] = true
+		}
+		for _, r := range d.Relationships {
+			keptRels[r] = true
+		}
+	}
+
+	dropNodes := make(map[string]bool)
+	dropRels := make(map[string]bool)
+	for id, idx := range chosen {
+		dropRels[id] = true
+		for i, alt := range optionsByID[id].RelationshipType.Options {
+			if i == idx {
+				continue
+			}
+			for _, n := range alt.Nodes {
+				if !keptNodes[n] {
+					dropNodes[n] = true
+				}
+			}
+			for _, r := range alt.Relationships {
+				if !keptRels[r] {
+					dropRels[r] = true
+				}
+			}
+		}
+	}
+
+	out := *a
+	out.origins = maps.Clone(a.origins)
+	out.Nodes = nil
+	for _, n := range a.Nodes {
+		if !dropNodes[n.UniqueID] {
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+
+	// Relationships are pruned first and options last, so that decisions
+	// also lose the relationships dropped for referencing removed nodes.
+	var pruned []*Relationship
+	for _, rel := range a.Relationships {
+		if dropRels[rel.UniqueID] {
+			continue
+		}
+		if rel.RelationshipType.Options != nil {
+			pruned = append(pruned, rel)
+			continue
+		}
+		p, ok := pruneRelationship(rel, dropNodes, nil)
+		if !ok {
+			dropRels[rel.UniqueID] = true
+			continue
+		}
+		pruned = append(pruned, out.adopt(rel, p))
+	}
+	out.Relationships = nil
+	for _, rel := range pruned {
+		if rel.RelationshipType.Options != nil {
+			p, _ := pruneRelationship(rel, dropNodes, dropRels)
+			rel = out.adopt(rel, p)
+		}
+		out.Relationships = append(out.Relationships, rel)
+	}
+
+	out.Flows = make([]*Flow, len(a.Flows))
+	for i, f := range a.Flows {
+		transitions := make([]Transition, 0, len(f.Transitions))
+		for _, t := range f.Transitions {
+			if !dropRels[t.RelationshipID] {
+				transitions = append(transitions, t)
+			}
+		}
+		if len(transitions) == len(f.Transitions) {
+			out.Flows[i] = f
+			continue
+		}
+		c := *f
+		c.Transitions = transitions
+		out.Flows[i] = &c
+		if pos, ok := out.origins[f]; ok {
+			out.origins[&c] = pos
+		}
+	}
+
+	return &out, nil
+}
+
+// adopt returns the pruned copy of rel, or rel when nothing was pruned,
+// keeping where it was defined.
+func (a *Architecture) adopt(rel, pruned *Relationship) *Relationship {
+	if pruned == rel {
+		return rel
+	}
+	if pos, ok := a.origins[rel]; ok {
+		a.origins[pruned] = pos
+	}
+	return pruned
+}
+
+// pickDecision returns the index of the decision matching choice.
+func pickDecision(rel *Relationship, choice string) (int, error) {
+	opts := rel.RelationshipType.Options
+	for i, d := range opts {
+		if d.Description == choice {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(choice); err == nil && i >= 1 && i <= len(opts) {
+		return i - 1, nil
+	}
+
+	descs := make([]string, len(opts))
+	for i, d := range opts {
+		descs[i] = fmt.Sprintf("%d=%q", i+1, d.Description)
+	}
+	return 0, fmt.Errorf("options relationship %q has no option %q (available: %s)",
+		rel.UniqueID, choice, strings.Join(descs, ", "))
+}
+
+// pruneRelationship removes dropped nodes, and from options also dropped
+// relationships, and reports whether the relationship still makes sense
+// afterwards. It returns a copy when anything was removed and rel otherwise.
+func pruneRelationship(rel *Relationship, droppedNodes, droppedRels map[string]bool) (*Relationship, bool) {
+	changed := false
+	keep := func(ids []string, dropped map[string]bool) []string {
+		out := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if dropped[id] {
+				changed = true
+			} else {
+				out = append(out, id)
+			}
+		}
+		return out
+	}
+
+	c := *rel
+	rt := &c.RelationshipType
+	ok := true
+	switch {
+	case rt.Connects != nil:
+		return rel, !droppedNodes[rt.Connects.Source.Node] && !droppedNodes[rt.Connects.Destination.Node]
+	case rt.Interacts != nil:
+		in := *rt.Interacts
+		in.Nodes = keep(in.Nodes, droppedNodes)
+		rt.Interacts = &in
+		ok = !droppedNodes[in.Actor] && len(in.Nodes) > 0
+	case rt.ComposedOf != nil:
+		co := *rt.ComposedOf
+		co.Nodes = keep(co.Nodes, droppedNodes)
+		rt.ComposedOf = &co
+		ok = !droppedNodes[co.Container] && len(co.Nodes) > 0
+	case rt.DeployedIn != nil:
+		di := *rt.DeployedIn
+		di.Nodes = keep(di.Nodes, droppedNodes)
+		rt.DeployedIn = &di
+		ok = !droppedNodes[di.Container] && len(di.Nodes) > 0
+	case rt.Options != nil:
+		opts := make([]Decision, len(rt.Options))
+		for i, d := range rt.Options {
+			d.Nodes = keep(d.Nodes, droppedNodes)
+			d.Relationships = keep(d.Relationships, droppedRels)
+			opts[i] = d
+		}
+		rt.Options = opts
+	}
+	if !changed {
+		return rel, ok
+	}
+	return &c, ok
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func newQueueChoiceArch() *Architecture {
+	arch := NewArchitecture("a", "A", "desc")
+	svc := arch.DefineNode("order-svc", Service, "Order Service", "desc")
+	kafka := arch.DefineNode("kafka", Queue, "Kafka", "desc")
+	rabbit := arch.DefineNode("rabbitmq", Queue, "RabbitMQ", "desc")
+	sys := arch.DefineNode("sys", System, "System", "desc")
+
+	toKafka := svc.ConnectTo(kafka, "publish").GetID()
+	toRabbit := svc.ConnectTo(rabbit, "publish").GetID()
+	arch.ComposedOf("sys-comp", "desc", sys.UniqueID, []string{svc.UniqueID, kafka.UniqueID, rabbit.UniqueID})
+	arch.DefineOptions("order-queue", "Order queue broker").
+		Option("Kafka", []string{kafka.UniqueID}, []string{toKafka}).
+		Option("RabbitMQ", []string{rabbit.UniqueID}, []string{toRabbit})
+	arch.DefineFlow("f1", "Flow", "desc").Step(toKafka, "via kafka").Step(toRabbit, "via rabbit")
+	return arch
+}
+
+func TestArchitecture_Resolve(t *testing.T) {
+	t.Run("should keep the chosen alternative only", func(t *testing.T) {
+		arch := newQueueChoiceArch()
+		if errs := arch.Validate(NoDanglingRelationships(), NoUnusedNodes()); len(errs) != 0 {
+			t.Fatalf("expected options architecture to validate, got %v", errs)
+		}
+
+		if err := arch.Resolve(map[string]string{"order-queue": "RabbitMQ"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, n := range arch.Nodes {
+			if n.UniqueID == "kafka" {
+				t.Errorf("expected rejected node kafka to be removed")
+			}
+		}
+		for _, r := range arch.Relationships {
+			if r.UniqueID == "order-queue" || r.UniqueID == "order-svc-connects-kafka" {
+				t.Errorf("expected relationship %s to be removed", r.UniqueID)
+			}
+			if c := r.RelationshipType.ComposedOf; c != nil && len(c.Nodes) != 2 {
+				t.Errorf("expected kafka to be pruned from composition, got %v", c.Nodes)
+			}
+		}
+		if got := arch.Flows[0].Transitions; len(got) != 1 || got[0].RelationshipID != "order-svc-connects-rabbitmq" {
+			t.Errorf("expected flow to keep only the rabbitmq transition, got %v", got)
+		}
+		if errs := arch.Validate(NoDanglingRelationships(), AllFlowsHaveValidTransitions()); len(errs) != 0 {
+			t.Errorf("expected resolved architecture to validate, got %v", errs)
+		}
+	})
+
+	t.Run("should accept a 1-based index", func(t *testing.T) {
+		arch := newQueueChoiceArch()
+		if err := arch.Resolve(map[string]string{"order-queue": "1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, n := range arch.Nodes {
+			if n.UniqueID == "rabbitmq" {
+				t.Errorf("expected rabbitmq to be removed")
+			}
+		}
+	})
+
+	t.Run("should reject unknown options and choices without changes", func(t *testing.T) {
+		arch := newQueueChoiceArch()
+		if err := arch.Resolve(map[string]string{"missing": "1"}); err == nil {
+			t.Errorf("expected error for unknown options relationship")
+		}
+		err := arch.Resolve(map[string]string{"order-queue": "SQS"})
+		if err == nil || !strings.Contains(err.Error(), `1="Kafka"`) {
+			t.Errorf("expected error listing available options, got %v", err)
+		}
+		if len(arch.Nodes) != 4 || len(arch.Relationships) != 4 {
+			t.Errorf("expected architecture to be unchanged")
+		}
+	})
+}
+
+func TestArchitecture_Resolved(t *testing.T) {
+	t.Run("should leave the architecture resolvable again", func(t *testing.T) {
+		arch := newQueueChoiceArch()
+		for _, choice := range []string{"Kafka", "RabbitMQ"} {
+			resolved, err := arch.Resolved(map[string]string{"order-queue": choice})
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", choice, err)
+			}
+			if len(resolved.Nodes) != 3 || len(resolved.Flows[0].Transitions) != 1 {
+				t.Errorf("%s: unexpected resolution %v", choice, resolved.Nodes)
+			}
+		}
+		if len(arch.Nodes) != 4 || len(arch.Relationships) != 4 || len(arch.Flows[0].Transitions) != 2 {
+			t.Errorf("expected architecture to be unchanged")
+		}
+		for _, r := range arch.Relationships {
+			if c := r.RelationshipType.ComposedOf; c != nil && len(c.Nodes) != 3 {
+				t.Errorf("expected composition to be unchanged, got %v", c.Nodes)
+			}
+		}
+	})
+
+	t.Run("should prune dropped relationships from unchosen options", func(t *testing.T) {
+		arch := newQueueChoiceArch()
+		svc, kafka := arch.Nodes[0], arch.Nodes[1]
+		audit := svc.ConnectTo(kafka, "audit").GetID()
+		arch.DefineOptions("audit-sink", "Audit sink").
+			Option("Kafka", nil, []string{audit}).
+			Option("None", nil, nil)
+
+		resolved, err := arch.Resolved(map[string]string{"order-queue": "RabbitMQ"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if errs := resolved.Validate(NoDanglingRelationships()); len(errs) != 0 {
+			t.Errorf("expected no dangling references, got %v", errs)
+		}
+	})
+}
+
+func TestNoDanglingRelationships_Options(t *testing.T) {
+	arch := NewArchitecture("a", "A", "desc")
+	arch.DefineOptions("opt", "desc").Option("A", []string{"ghost"}, []string{"ghost-rel"})
+
+	errs := NoDanglingRelationships().Validate(arch)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+}
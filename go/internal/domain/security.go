@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SensitiveClassifications are the data classifications that must only travel
+// over encrypted connections.
+var SensitiveClassifications = []string{"confidential", "restricted", "PII", "PCI"}
+
+// plaintextProtocols are protocols that do not encrypt traffic themselves.
+var plaintextProtocols = []string{"HTTP", "JDBC", "FTP", "LDAP"}
+
+// carriedProtocols lists the interface protocols a relationship protocol can
+// carry besides its own, e.g. a REST API served over HTTPS.
+var carriedProtocols = map[string][]string{
+	"HTTP":  {"REST"},
+	"HTTPS": {"REST"},
+}
+
+// SecurityRules returns the rules checking data classification, encryption and
+// protocols of relationships.
+func SecurityRules() []ValidationRule {
+	return []ValidationRule{
+		SensitiveDataIsEncrypted(),
+		NoPlaintextProtocols(),
+		ProtocolsMatchInterfaces(),
+	}
+}
+
+// tlsControl is a control requiring TLS, found on the architecture, a node or
+// a relationship.
+type tlsControl struct {
+	owner, id, url string
+}
+
+func (c tlsControl) String() string {
+	return fmt.Sprintf("%s control %q (%s)", c.owner, c.id, c.url)
+}
+
+// findTLSControl returns the first control whose requirement URL names TLS.
+func findTLSControl(owner string, controls map[string]*Control) (tlsControl, bool) {
+	ids := make([]string, 0, len(controls))
+	for id, c := range controls {
+		if c != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, req := range controls[id].Requirements {
+			if strings.Contains(strings.ToLower(req.RequirementURL), "tls") {
+				return tlsControl{owner: owner, id: id, url: req.RequirementURL}, true
+			}
+		}
+	}
+	return tlsControl{}, false
+}
+
+// transportSecurity answers, for one relationship, whether its traffic is
+// known to be encrypted and which control, if any, requires it to be.
+type transportSecurity struct {
+	secured  bool
+	required tlsControl
+	mandated bool
+}
+
+func newTransportSecurity(a *Architecture, g *Graph, rel *Relationship) transportSecurity {
+	var s transportSecurity
+	_, ownTLS := findTLSControl("relationship", rel.Controls)
+	s.secured = (rel.Encrypted != nil && *rel.Encrypted) || ownTLS
+
+	if c, ok := findTLSControl("architecture", a.Controls); ok {
+		s.required, s.mandated = c, true
+		return s
+	}
+	for _, ref := range rel.NodeRefs() {
+		if n, ok := g.Node(ref.ID); ok {
+			if c, ok := findTLSControl("node "+n.UniqueID, n.Controls); ok {
+				s.required, s.mandated = c, true
+				return s
+			}
+		}
+	}
+	return s
+}
+
+// carriesData reports whether data travels over the relationship, as opposed
+// to structural relationships such as composed-of.
+func carriesData(rel *Relationship) bool {
+	return rel.RelationshipType.Connects != nil || rel.RelationshipType.Interacts != nil
+}
+
+// sensitiveDataIsEncrypted checks that sensitive data only crosses encrypted
+// connections, and that no connection is marked unencrypted where a control
+// requires TLS
+type sensitiveDataIsEncrypted struct{}
+
+func SensitiveDataIsEncrypted() ValidationRule { return sensitiveDataIsEncrypted{} }
+
+func (r sensitiveDataIsEncrypted) Name() string { return "SensitiveDataIsEncrypted" }
+
+func (r sensitiveDataIsEncrypted) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, rel := range a.Relationships {
+		if !carriesData(rel) {
+			continue
+		}
+		sec := newTransportSecurity(a, g, rel)
+		switch {
+		case sec.secured:
+		case containsString(SensitiveClassifications, rel.DataClassification):
+			errors = append(errors, ValidationError{
+				Rule:   r.Name(),
+				NodeID: rel.UniqueID,
+				Message: fmt.Sprintf("%s data is not encrypted; mark the connection Encrypted(true)",
+					rel.DataClassification),
+			})
+		case sec.mandated && rel.Encrypted != nil:
+			errors = append(errors, ValidationError{
+				Rule:    r.Name(),
+				NodeID:  rel.UniqueID,
+				Message: fmt.Sprintf("connection is not encrypted, but %s requires TLS", sec.required),
+			})
+		}
+	}
+	return errors
+}
+
+// noPlaintextProtocols checks that plain protocols such as HTTP and JDBC are
+// wrapped in TLS. Findings are errors by default; a policy may lower them,
+// except where a control requires TLS.
+type noPlaintextProtocols struct{}
+
+func NoPlaintextProtocols() ValidationRule { return noPlaintextProtocols{} }
+
+func (r noPlaintextProtocols) Name() string { return "NoPlaintextProtocols" }
+
+func (r noPlaintextProtocols) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, rel := range a.Relationships {
+		if !carriesData(rel) {
+			continue
+		}
+		sec := newTransportSecurity(a, g, rel)
+		if sec.secured {
+			continue
+		}
+		for _, p := range relationshipProtocols(g, rel) {
+			if !containsString(plaintextProtocols, p) {
+				continue
+			}
+			e := ValidationError{
+				Rule:    r.Name(),
+				NodeID:  rel.UniqueID,
+				Message: fmt.Sprintf("plain %s without TLS; mark the connection Encrypted(true)", p),
+			}
+			if sec.mandated {
+				e.Severity = SeverityError
+				e.Message += fmt.Sprintf(" as %s requires", sec.required)
+			}
+			errors = append(errors, e)
+			break
+		}
+	}
+	return errors
+}
+
+// relationshipProtocols returns the protocol of the relationship, or else the
+// protocols of the interfaces it connects.
+func relationshipProtocols(g *Graph, rel *Relationship) []string {
+	if rel.Protocol != "" {
+		return []string{rel.Protocol}
+	}
+	var protocols []string
+	for _, intf := range connectedInterfaces(g, rel) {
+		if intf.Protocol != "" {
+			protocols = append(protocols, intf.Protocol)
+		}
+	}
+	return protocols
+}
+
+// connectedInterfaces returns the source and destination interfaces of a
+// connects relationship that exist on their nodes.
+func connectedInterfaces(g *Graph, rel *Relationship) []Interface {
+	c := rel.RelationshipType.Connects
+	if c == nil {
+		return nil
+	}
+	var intfs []Interface
+	for _, end := range []NodeInterface{c.Source, c.Destination} {
+		n, ok := g.Node(end.Node)
+		if !ok {
+			continue
+		}
+		for _, id := range end.Interfaces {
+			if intf, ok := n.FindInterface(id); ok {
+				intfs = append(intfs, intf)
+			}
+		}
+	}
+	return intfs
+}
+
+// protocolsMatchInterfaces checks that the protocol of a connects relationship
+// agrees with the interfaces at both of its ends
+type protocolsMatchInterfaces struct{}
+
+func ProtocolsMatchInterfaces() ValidationRule { return protocolsMatchInterfaces{} }
+
+func (r protocolsMatchInterfaces) Name() string { return "ProtocolsMatchInterfaces" }
+
+func (r protocolsMatchInterfaces) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, rel := range a.Relationships {
+		if rel.Protocol == "" {
+			continue
+		}
+		for _, intf := range connectedInterfaces(g, rel) {
+			if intf.Protocol == "" || protocolCarries(rel.Protocol, intf.Protocol) {
+				continue
+			}
+			errors = append(errors, ValidationError{
+				Rule:   r.Name(),
+				NodeID: rel.UniqueID,
+				Message: fmt.Sprintf("protocol %s does not match interface %q protocol %s",
+					rel.Protocol, intf.UniqueID, intf.Protocol),
+			})
+		}
+	}
+	return errors
+}
+
+// protocolCarries reports whether a relationship using protocol rel can reach
+// an interface speaking intf. TLS and mTLS wrap any protocol.
+func protocolCarries(rel, intf string) bool {
+	if strings.EqualFold(rel, intf) || strings.EqualFold(rel, "TLS") || strings.EqualFold(rel, "mTLS") {
+		return true
+	}
+	return containsString(carriedProtocols[strings.ToUpper(rel)], intf)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSensitiveDataIsEncrypted(t *testing.T) {
+	t.Run("should require encryption for sensitive data", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		api.ConnectTo(db, "reads").WithID("pii").Is("PII")
+		api.ConnectTo(db, "writes").WithID("pci").Is("pci").Encrypted(true)
+		api.ConnectTo(db, "pings").WithID("internal").Is("internal").Encrypted(false)
+
+		errs := a.Validate(SensitiveDataIsEncrypted())
+		if len(errs) != 1 || errs[0].NodeID != "pii" || errs[0].Code != "CALM016" {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("should accept a TLS control on the relationship", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		api.ConnectTo(db, "reads").Is("confidential").
+			Control("security", "mTLS", NewRequirementURL("https://policy.example.com/mtls", ""))
+		if errs := a.Validate(SensitiveDataIsEncrypted()); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("should honor architecture TLS controls", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		a.AddControl("security", "TLS", NewRequirementURL("https://policy.example.com/tls-1-3-minimum", ""))
+		api.ConnectTo(db, "pings").Is("internal").Encrypted(false)
+
+		errs := a.Validate(SensitiveDataIsEncrypted())
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, `architecture control "security"`) {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
+
+func TestNoPlaintextProtocols(t *testing.T) {
+	t.Run("should fail on plain protocols without TLS", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		api.Interface("api-jdbc", "JDBC")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		db.Interface("db-sql", "JDBC")
+		api.ConnectTo(db, "reads").WithID("plain").Via("api-jdbc", "db-sql")
+		api.ConnectTo(db, "writes").WithID("tls").Via("api-jdbc", "db-sql").Encrypted(true)
+		api.ConnectTo(db, "calls").WithID("http").Protocol("HTTP")
+
+		errs := a.Validate(NoPlaintextProtocols())
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].NodeID != "plain" ||
+			errs[0].Message != "plain JDBC without TLS; mark the connection Encrypted(true)" {
+			t.Errorf("unexpected error %v", errs[0])
+		}
+		if errs[1].NodeID != "http" || errs[1].Severity != SeverityError {
+			t.Errorf("expected an error, got %v", errs[1])
+		}
+	})
+
+	t.Run("should fail where a node requires TLS", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		api := a.DefineNode("api", Service, "API", "desc")
+		api.Interface("api-jdbc", "JDBC")
+		db := a.DefineNode("db", Database, "DB", "desc")
+		db.Interface("db-sql", "JDBC")
+		db.AddControl("security", "TLS", NewRequirementURL("https://policy.example.com/tls", ""))
+		api.ConnectTo(db, "reads").Via("api-jdbc", "db-sql")
+
+		errs := a.Validate(NoPlaintextProtocols())
+		if len(errs) != 1 || errs[0].Severity != SeverityError ||
+			!strings.Contains(errs[0].Message, `node db control "security"`) {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
+
+func TestProtocolsMatchInterfaces(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	api := a.DefineNode("api", Service, "API", "desc")
+	api.Interface("api-http", "HTTP")
+	api.Interface("api-jdbc", "JDBC")
+	db := a.DefineNode("db", Database, "DB", "desc")
+	db.Interface("db-sql", "JDBC")
+	api.Interface("api-rest", "REST")
+	api.ConnectTo(db, "reads").WithID("mismatch").Via("api-http", "db-sql").Protocol("HTTP")
+	api.ConnectTo(db, "calls").WithID("rest").Via("api-rest", "").Protocol("HTTPS")
+	api.ConnectTo(db, "wrapped").WithID("tls").Via("api-jdbc", "db-sql").Protocol("TLS")
+
+	errs := a.Validate(ProtocolsMatchInterfaces())
+	if len(errs) != 1 || errs[0].NodeID != "mismatch" ||
+		errs[0].Message != `protocol HTTP does not match interface "db-sql" protocol JDBC` {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
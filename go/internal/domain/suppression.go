@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metadata keys suppressing validation findings on the element that carries
+// them, or on every element when set on the architecture.
+const (
+	// MetaLintIgnore lists the names or codes of the rules to suppress.
+	MetaLintIgnore = "calm-lint-ignore"
+	// MetaLintReason explains why; a suppression without a reason is ignored.
+	MetaLintReason = "calm-lint-reason"
+)
+
+const lintSuppressionRule = "LintSuppressions"
+
+func init() {
+	RegisterMetaKey(
+		MetaKey{Name: MetaLintIgnore, Type: MetaTypeStringList, Description: "Validation rules to suppress"},
+		MetaKey{Name: MetaLintReason, Type: MetaTypeString, Description: "Why the rules are suppressed"},
+	)
+}
+
+// WithLintIgnore suppresses the given rules, by name or code, for the node.
+func WithLintIgnore(reason string, rules ...string) NodeOption {
+	return func(n *Node) {
+		n.Metadata[MetaLintIgnore] = rules
+		n.Metadata[MetaLintReason] = reason
+	}
+}
+
+// lintElements indexes the elements of an architecture by ID so that
+// validation errors can be completed with their element kind and source and
+// checked against suppressions.
+type lintElements struct {
+	kinds    map[string]ElementKind
+	sources  map[string]SourcePos
+	ignored  map[string][]string // element ID ("" for the architecture) -> rules
+	problems []ValidationError
+}
+
+func newLintElements(a *Architecture) *lintElements {
+	l := &lintElements{
+		kinds:   make(map[string]ElementKind),
+		sources: make(map[string]SourcePos),
+		ignored: make(map[string][]string),
+	}
+	l.add("", "", a.Metadata, SourcePos{})
+	for _, n := range a.Nodes {
+		l.add(n.UniqueID, KindNode, n.Metadata, a.origins[n])
+	}
+	for _, r := range a.Relationships {
+		l.add(r.UniqueID, KindRelationship, r.Metadata, a.origins[r])
+	}
+	for _, f := range a.Flows {
+		l.add(f.UniqueID, KindFlow, f.Metadata, a.origins[f])
+	}
+	return l
+}
+
+func (l *lintElements) add(id string, kind ElementKind, meta Metadata, pos SourcePos) {
+	if id != "" {
+		if _, ok := l.kinds[id]; !ok {
+			l.kinds[id] = kind
+			l.sources[id] = pos
+		}
+	}
+	raw, ok := meta[MetaLintIgnore]
+	if !ok {
+		return
+	}
+	report := func(msg string) {
+		e := ValidationError{Rule: lintSuppressionRule, NodeID: id, Message: msg}
+		l.problems = append(l.problems, l.complete(e))
+	}
+	rules, ok := stringList(raw)
+	if !ok {
+		report(fmt.Sprintf("%s must be a list of rule names or codes", MetaLintIgnore))
+		return
+	}
+	if reason, _ := meta[MetaLintReason].(string); strings.TrimSpace(reason) == "" {
+		report(fmt.Sprintf("%s %q has no %s; the suppression is ignored", MetaLintIgnore, rules, MetaLintReason))
+		return
+	}
+	l.ignored[id] = append(l.ignored[id], rules...)
+}
+
+// complete fills in the code, severity and kind the rule left empty.
+func (l *lintElements) complete(e ValidationError) ValidationError {
+	if info, ok := LookupRuleInfo(e.Rule); ok {
+		if e.Code == "" {
+			e.Code = info.Code
+		}
+		if e.Severity == "" {
+			e.Severity = info.Severity
+		}
+	}
+	if e.Severity == "" {
+		e.Severity = SeverityError
+	}
+	if e.Kind == "" {
+		e.Kind = l.kinds[e.NodeID]
+	}
+	if e.Source == (SourcePos{}) {
+		e.Source = l.sources[e.NodeID]
+	}
+	return e
+}
+
+// suppressed reports whether e is suppressed on its element or on the whole
+// architecture. Problems with suppressions themselves cannot be suppressed.
+func (l *lintElements) suppressed(e ValidationError) bool {
+	if e.Rule == lintSuppressionRule {
+		return false
+	}
+	for _, id := range []string{e.NodeID, ""} {
+		for _, rule := range l.ignored[id] {
+			if rule == e.Rule || (e.Code != "" && rule == e.Code) {
+				return true
+			}
+		}
+		if e.NodeID == "" {
+			break
+		}
+	}
+	return false
+}
+
+func stringList(v any) ([]string, bool) {
+	switch list := v.(type) {
+	case []string:
+		return list, true
+	case []any:
+		out := make([]string, len(list))
+		for i, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out[i] = s
+		}
+		return out, true
+	}
+	return nil, false
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArchitecture_ValidateCompletesErrors(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	a.DefineNode("api", Service, "API", "desc")
+	a.DefineNode("db", Database, "DB", "desc")
+	a.Connect("r1", "desc", "api", "ghost")
+
+	errs := a.Validate(AllNodesHaveOwner(), NoDanglingRelationships(), NoUnusedNodes())
+	byRule := make(map[string]ValidationError)
+	for _, e := range errs {
+		byRule[e.Rule] = e
+	}
+	if e := byRule["AllNodesHaveOwner"]; e.Code != "CALM001" || e.Kind != KindNode || e.Severity != SeverityError {
+		t.Errorf("unexpected owner error %#v", e)
+	}
+	if e := byRule["NoDanglingRelationships"]; e.Kind != KindRelationship {
+		t.Errorf("expected a relationship error, got %#v", e)
+	}
+	if e := byRule["NoUnusedNodes"]; e.Severity != SeverityWarning {
+		t.Errorf("expected a warning, got %#v", e)
+	}
+}
+
+func TestArchitecture_ValidateSuppressions(t *testing.T) {
+	t.Run("should drop errors suppressed by name or code", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("api", Service, "API", "desc",
+			WithLintIgnore("health is checked by the mesh", "AllServicesHaveHealthEndpoint", "CALM001"))
+		a.DefineNode("worker", Service, "Worker", "desc")
+
+		errs := a.Validate(AllNodesHaveOwner(), AllServicesHaveHealthEndpoint())
+		for _, e := range errs {
+			if e.NodeID == "api" {
+				t.Errorf("expected api errors to be suppressed, got %v", e)
+			}
+		}
+		if len(errs) != 2 {
+			t.Errorf("expected the worker errors, got %v", errs)
+		}
+	})
+
+	t.Run("should apply architecture suppressions to every element", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		a.Metadata = Metadata{MetaLintIgnore: []any{"AllNodesHaveOwner"}, MetaLintReason: "tracked elsewhere"}
+		a.DefineNode("api", Service, "API", "desc")
+		if errs := a.Validate(AllNodesHaveOwner()); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("should require a reason", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("api", Service, "API", "desc", WithLintIgnore(" ", "AllNodesHaveOwner"))
+		errs := a.Validate(AllNodesHaveOwner())
+		if len(errs) != 2 {
+			t.Fatalf("expected the owner error and a suppression error, got %v", errs)
+		}
+		if errs[1].Code != "CALM014" || !strings.Contains(errs[1].Message, "has no calm-lint-reason") {
+			t.Errorf("unexpected suppression error %v", errs[1])
+		}
+	})
+
+	t.Run("should reject suppressions that are not a list", func(t *testing.T) {
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("api", Service, "API", "desc", WithOwner("team", "cc"),
+			WithMeta(map[string]any{MetaLintIgnore: "AllNodesHaveOwner", MetaLintReason: "r"}))
+		errs := a.Validate()
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, "must be a list") {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	if !SeverityError.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) ||
+		!Severity("").AtLeast(SeverityError) {
+		t.Errorf("unexpected severity ordering")
+	}
+}
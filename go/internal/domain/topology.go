@@ -0,0 +1,259 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metadata keys marking the role a node plays in the topology.
+const (
+	// MetaEntryPoint marks a node that actors may reach directly.
+	MetaEntryPoint = "entry-point"
+	// MetaLoadBalancer marks a node that spreads traffic over the nodes it
+	// connects to.
+	MetaLoadBalancer = "load-balancer"
+)
+
+func init() {
+	RegisterMetaKey(
+		MetaKey{Name: MetaEntryPoint, Type: MetaTypeBoolean, Description: "Whether actors may reach the node"},
+		MetaKey{Name: MetaLoadBalancer, Type: MetaTypeBoolean, Description: "Whether the node balances load"},
+	)
+}
+
+// WithEntryPoint marks the node as an entry point actors may reach.
+func WithEntryPoint() NodeOption {
+	return func(n *Node) { n.Metadata[MetaEntryPoint] = true }
+}
+
+// WithLoadBalancer marks the node as a load balancer over the nodes it
+// connects to.
+func WithLoadBalancer() NodeOption {
+	return func(n *Node) { n.Metadata[MetaLoadBalancer] = true }
+}
+
+// asyncProtocols are protocols whose callers do not wait for the callee.
+var asyncProtocols = []string{"AMQP", "Kafka", "MQTT", "JMS"}
+
+// TopologyRules returns the fitness rules for the shape of the architecture,
+// using DefaultLayering.
+func TopologyRules() []ValidationRule {
+	return []ValidationRule{
+		NoSynchronousCycles(),
+		LayeringHolds(DefaultLayering()),
+		NoSinglePointsOfFailure(),
+		NoContainmentCycles(),
+	}
+}
+
+// noSynchronousCycles checks that services do not call each other in a cycle
+// over synchronous connections. Asynchronous protocols such as AMQP break a
+// cycle
+type noSynchronousCycles struct{}
+
+func NoSynchronousCycles() ValidationRule { return noSynchronousCycles{} }
+
+func (r noSynchronousCycles) Name() string { return "NoSynchronousCycles" }
+
+func (r noSynchronousCycles) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+	isService := func(id string) bool {
+		n, ok := g.Node(id)
+		return ok && n.NodeType == Service
+	}
+	sync := func(e Edge) bool {
+		if e.Relationship.RelationshipType.Connects == nil || !isService(e.From) || !isService(e.To) {
+			return false
+		}
+		for _, p := range relationshipProtocols(g, e.Relationship) {
+			if containsString(asyncProtocols, p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var errors []ValidationError
+	for _, cycle := range g.CyclesFunc(sync) {
+		errors = append(errors, ValidationError{
+			Rule:    r.Name(),
+			NodeID:  cycle[0],
+			Message: "synchronous call cycle: " + strings.Join(append(cycle, cycle[0]), " -> "),
+		})
+	}
+	return errors
+}
+
+// noContainmentCycles checks that no node contains itself through composed-of
+// relationships, which would leave it without a place in rendered diagrams
+type noContainmentCycles struct{}
+
+func NoContainmentCycles() ValidationRule { return noContainmentCycles{} }
+
+func (r noContainmentCycles) Name() string { return "NoContainmentCycles" }
+
+func (r noContainmentCycles) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, cycle := range NewGraph(a).ContainmentCycles() {
+		errors = append(errors, ValidationError{
+			Rule:    r.Name(),
+			NodeID:  cycle[0],
+			Message: "composed-of cycle: " + strings.Join(append(cycle, cycle[0]), " is contained in "),
+		})
+	}
+	return errors
+}
+
+// noSinglePointsOfFailure checks that a tier-1 node behind a load balancer is
+// not its only instance
+type noSinglePointsOfFailure struct{}
+
+func NoSinglePointsOfFailure() ValidationRule { return noSinglePointsOfFailure{} }
+
+func (r noSinglePointsOfFailure) Name() string { return "NoSinglePointsOfFailure" }
+
+func (r noSinglePointsOfFailure) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
+
+	var errors []ValidationError
+	for _, lb := range a.Nodes {
+		if balances, _ := lb.Metadata[MetaLoadBalancer].(bool); !balances {
+			continue
+		}
+		var targets []string
+		for _, e := range g.Outbound(lb.UniqueID) {
+			if e.Relationship.RelationshipType.Connects != nil && !containsString(targets, e.To) {
+				targets = append(targets, e.To)
+			}
+		}
+		if len(targets) != 1 {
+			continue
+		}
+		n, ok := g.Node(targets[0])
+		if !ok {
+			continue
+		}
+		if tier, _ := n.Metadata[MetaTier].(string); Tier(tier) != Tier1 {
+			continue
+		}
+		errors = append(errors, ValidationError{
+			Rule:    r.Name(),
+			NodeID:  n.UniqueID,
+			Message: fmt.Sprintf("tier-1 node is the only instance behind load balancer %q", lb.UniqueID),
+		})
+	}
+	return errors
+}
+
+// Layer is a named group of nodes for LayeringHolds.
+type Layer struct {
+	Name string
+	// Select picks the nodes of the layer with the syntax of policy rules;
+	// a node belongs to the first layer that selects it.
+	Select PolicySelector
+	// MayReach lists the layers this layer may connect or interact with,
+	// including itself if its nodes may reach each other.
+	MayReach []string
+	// SameTeam lists the layers of MayReach that may only be reached on
+	// nodes of the same team.
+	SameTeam []string
+}
+
+// Layering configures LayeringHolds. Nodes outside every layer are not
+// checked.
+type Layering struct {
+	Layers []Layer
+	// TeamField is the node field naming its team, such as "owner" or
+	// "metadata.team"; the default is "costCenter".
+	TeamField string
+}
+
+// DefaultLayering lets actors reach entry points only, entry points reach
+// services, and services reach the databases of their own team.
+func DefaultLayering() Layering {
+	return Layering{Layers: []Layer{
+		{Name: "actor", Select: PolicySelector{NodeTypes: []NodeType{Actor}}, MayReach: []string{"entry-point"}},
+		{
+			Name:     "entry-point",
+			Select:   PolicySelector{Where: map[string]string{"metadata." + MetaEntryPoint: "true"}},
+			MayReach: []string{"entry-point", "service"},
+		},
+		{
+			Name:     "service",
+			Select:   PolicySelector{NodeTypes: []NodeType{Service, System, Queue}},
+			MayReach: []string{"service", "data"},
+			SameTeam: []string{"data"},
+		},
+		{Name: "data", Select: PolicySelector{NodeTypes: []NodeType{Database, DataAsset}}, MayReach: []string{"data"}},
+	}}
+}
+
+// layeringHolds checks that connects and interacts relationships only cross
+// the layers their layering allows
+type layeringHolds struct {
+	layering Layering
+}
+
+func LayeringHolds(l Layering) ValidationRule { return layeringHolds{layering: l} }
+
+func (r layeringHolds) Name() string { return "LayeringHolds" }
+
+func (r layeringHolds) Validate(a *Architecture) []ValidationError {
+	layers := make([]policyRule, len(r.layering.Layers))
+	for i, l := range r.layering.Layers {
+		var err error
+		if l.Select.Kind != "" && l.Select.Kind != KindNode {
+			err = fmt.Errorf("layers select nodes, not %s", l.Select.Kind)
+		} else {
+			layers[i], err = compilePolicyRule(PolicyRule{Name: l.Name, Select: l.Select})
+		}
+		if err != nil {
+			return []ValidationError{{Rule: r.Name(), Message: fmt.Sprintf("layer %q: %v", l.Name, err)}}
+		}
+	}
+	teamField := r.layering.TeamField
+	if teamField == "" {
+		teamField = "costCenter"
+	}
+
+	layerOf := make(map[string]int)
+	teamOf := make(map[string]string)
+	for _, e := range (policyRule{kind: KindNode}).elements(a) {
+		for i, l := range layers {
+			if l.selects(e) {
+				layerOf[e.id] = i
+				break
+			}
+		}
+		if team, ok := fieldValues(e.fields, teamField); ok {
+			teamOf[e.id] = team[0]
+		}
+	}
+
+	var errors []ValidationError
+	report := func(e Edge, msg string) {
+		errors = append(errors, ValidationError{Rule: r.Name(), NodeID: e.Relationship.UniqueID, Message: msg})
+	}
+	g := NewGraph(a)
+	for _, id := range g.edgeSources() {
+		from, ok := layerOf[id]
+		if !ok {
+			continue
+		}
+		for _, e := range g.Outbound(id) {
+			to, ok := layerOf[e.To]
+			if !ok {
+				continue
+			}
+			src, dst := r.layering.Layers[from], r.layering.Layers[to]
+			switch {
+			case !containsString(src.MayReach, dst.Name):
+				report(e, fmt.Sprintf("%s %q may not reach %s %q", src.Name, e.From, dst.Name, e.To))
+			case containsString(src.SameTeam, dst.Name) && teamOf[e.From] != teamOf[e.To]:
+				report(e, fmt.Sprintf("%s %q (team %q) reaches %s %q of team %q",
+					src.Name, e.From, teamOf[e.From], dst.Name, e.To, teamOf[e.To]))
+			}
+		}
+	}
+	return errors
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNoSynchronousCycles(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	orders := a.DefineNode("orders", Service, "Orders", "desc")
+	stock := a.DefineNode("stock", Service, "Stock", "desc")
+	billing := a.DefineNode("billing", Service, "Billing", "desc")
+	orders.ConnectTo(stock, "reserves")
+	stock.ConnectTo(orders, "confirms")
+	orders.ConnectTo(billing, "bills")
+	billing.ConnectTo(orders, "notifies").Protocol("AMQP")
+
+	errs := a.Validate(NoSynchronousCycles())
+	if len(errs) != 1 || errs[0].NodeID != "orders" || errs[0].Code != "CALM022" ||
+		errs[0].Message != "synchronous call cycle: orders -> stock -> orders" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestNoContainmentCycles(t *testing.T) {
+	a := NewArchitecture("a", "A", "desc")
+	a.DefineNode("system", System, "System", "desc")
+	a.DefineNode("cluster", System, "Cluster", "desc")
+	a.ComposedOf("c1", "desc", "system", []string{"cluster"})
+	a.ComposedOf("c2", "desc", "cluster", []string{"system"})
+
+	errs := a.Validate(NoContainmentCycles())
+	if len(errs) != 1 || errs[0].Kind != KindNode ||
+		errs[0].Message != "composed-of cycle: cluster is contained in system is contained in cluster" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestNoSinglePointsOfFailure(t *testing.T) {
+	build := func(instances int) *Architecture {
+		a := NewArchitecture("a", "A", "desc")
+		lb := a.DefineNode("lb", Service, "LB", "desc", WithLoadBalancer())
+		for i := 1; i <= instances; i++ {
+			gw := a.DefineNode(fmt.Sprintf("gw-%d", i), Service, "Gateway", "desc", WithTier(Tier1))
+			lb.ConnectTo(gw, "routes")
+		}
+		return a
+	}
+
+	if errs := build(2).Validate(NoSinglePointsOfFailure()); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+	errs := build(1).Validate(NoSinglePointsOfFailure())
+	if len(errs) != 1 || errs[0].NodeID != "gw-1" || !strings.Contains(errs[0].Message, `load balancer "lb"`) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLayeringHolds(t *testing.T) {
+	newArch := func() *Architecture {
+		a := NewArchitecture("a", "A", "desc")
+		a.DefineNode("user", Actor, "User", "desc")
+		a.DefineNode("lb", Service, "LB", "desc", WithEntryPoint())
+		a.DefineNode("orders", Service, "Orders", "desc", WithOwner("orders-team", "CC-1"))
+		a.DefineNode("stock", Service, "Stock", "desc", WithOwner("stock-team", "CC-2"))
+		a.DefineNode("orders-db", Database, "Orders DB", "desc", WithOwner("dba-team", "CC-1"))
+		return a
+	}
+
+	t.Run("should accept the allowed layers", func(t *testing.T) {
+		a := newArch()
+		a.Interacts("uses", "desc", "user", "lb")
+		a.Connect("routes", "desc", "lb", "orders")
+		a.Connect("persists", "desc", "orders", "orders-db")
+		if errs := a.Validate(LayeringHolds(DefaultLayering())); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("should reject skipped layers and other teams' databases", func(t *testing.T) {
+		a := newArch()
+		a.Interacts("bypass", "desc", "user", "orders")
+		a.Connect("steals", "desc", "stock", "orders-db")
+
+		errs := a.Validate(LayeringHolds(DefaultLayering()))
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %v", errs)
+		}
+		if errs[0].NodeID != "bypass" || errs[0].Message != `actor "user" may not reach service "orders"` {
+			t.Errorf("unexpected error %v", errs[0])
+		}
+		if errs[1].NodeID != "steals" ||
+			errs[1].Message != `service "stock" (team "CC-2") reaches data "orders-db" of team "CC-1"` {
+			t.Errorf("unexpected error %v", errs[1])
+		}
+	})
+
+	t.Run("should use custom layers and team fields", func(t *testing.T) {
+		a := newArch()
+		a.Connect("steals", "desc", "stock", "orders-db")
+		layering := Layering{
+			Layers: []Layer{
+				{Name: "app", Select: PolicySelector{NodeTypes: []NodeType{Service}}, MayReach: []string{"db"}},
+				{Name: "db", Select: PolicySelector{NodeTypes: []NodeType{Database}}},
+			},
+			TeamField: "owner",
+		}
+		if errs := a.Validate(LayeringHolds(layering)); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+		layering.Layers[0].SameTeam = []string{"db"}
+		if errs := a.Validate(LayeringHolds(layering)); len(errs) != 1 ||
+			!strings.Contains(errs[0].Message, `(team "stock-team")`) {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("should report invalid layers", func(t *testing.T) {
+		invalid := Layer{Name: "x", Select: PolicySelector{Where: map[string]string{"owner": "("}}}
+		layering := Layering{Layers: []Layer{invalid}}
+		errs := newArch().Validate(LayeringHolds(layering))
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, `layer "x": where "owner"`) {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"sync"
 )
 
 // ValidationRule defines a rule to check against the architecture
@@ -10,27 +11,153 @@ type ValidationRule interface {
 	Validate(a *Architecture) []ValidationError
 }
 
+// Severity is how serious a validation failure is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Valid reports whether s is a known severity. An empty severity is valid and
+// means SeverityError.
+func (s Severity) Valid() bool {
+	switch s {
+	case "", SeverityError, SeverityWarning, SeverityInfo:
+		return true
+	}
+	return false
+}
+
+// ElementKind is the kind of architecture element a rule looks at.
+type ElementKind string
+
+const (
+	KindNode         ElementKind = "node"
+	KindRelationship ElementKind = "relationship"
+	KindFlow         ElementKind = "flow"
+)
+
+// AtLeast reports whether s is as serious as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	}
+	return 3
+}
+
 // ValidationError represents a validation failure
 type ValidationError struct {
-	Rule    string
-	NodeID  string
-	Message string
+	Rule     string
+	Code     string // stable rule code, e.g. "CALM001"
+	NodeID   string // ID of the node, relationship or flow at fault
+	Kind     ElementKind
+	Message  string
+	Severity Severity // empty means SeverityError
+	// Source is where the element was defined in the Go DSL; zero when
+	// unknown, e.g. for architectures parsed from JSON.
+	Source SourcePos
 }
 
 func (e ValidationError) String() string {
+	rule := e.Rule
+	if e.Code != "" {
+		rule = e.Code + " " + rule
+	}
+	if e.Severity != "" && e.Severity != SeverityError {
+		rule += " " + string(e.Severity)
+	}
 	if e.NodeID != "" {
-		return fmt.Sprintf("[%s] %s: %s", e.Rule, e.NodeID, e.Message)
+		return fmt.Sprintf("[%s] %s: %s", rule, e.NodeID, e.Message)
 	}
-	return fmt.Sprintf("[%s] %s", e.Rule, e.Message)
+	return fmt.Sprintf("[%s] %s", rule, e.Message)
 }
 
-// Validate runs all rules against the architecture and returns errors
+// SchemaConformanceRule names the errors of rendered documents that do not
+// conform to their CALM meta-schema.
+const SchemaConformanceRule = "ConformsToCALMSchema"
+
+// RuleInfo describes a validation rule independently of its implementation:
+// a code that stays the same when the rule is renamed, and the severity of its
+// findings.
+type RuleInfo struct {
+	Code     string
+	Severity Severity
+}
+
+var (
+	ruleInfosMu sync.RWMutex
+	ruleInfos   = map[string]RuleInfo{
+		"AllNodesHaveOwner":              {Code: "CALM001", Severity: SeverityError},
+		"AllServicesHaveHealthEndpoint":  {Code: "CALM002", Severity: SeverityError},
+		"NoDanglingRelationships":        {Code: "CALM003", Severity: SeverityError},
+		"AllFlowsHaveValidTransitions":   {Code: "CALM004", Severity: SeverityError},
+		"AllDatabasesHaveBackupSchedule": {Code: "CALM005", Severity: SeverityError},
+		"AllTier1NodesHaveRunbook":       {Code: "CALM006", Severity: SeverityError},
+		"NoUnknownNodeTypes":             {Code: "CALM007", Severity: SeverityError},
+		"InterfaceDefinitionsConform":    {Code: "CALM008", Severity: SeverityError},
+		"NoUnusedNodes":                  {Code: "CALM009", Severity: SeverityWarning},
+		"MetadataMatchesVocabulary":      {Code: "CALM010", Severity: SeverityError},
+		"FlowSequenceIsContiguous":       {Code: "CALM011", Severity: SeverityError},
+		"FlowDirectionsAreValid":         {Code: "CALM012", Severity: SeverityError},
+		"FlowStepsChain":                 {Code: "CALM013", Severity: SeverityError},
+		lintSuppressionRule:              {Code: "CALM014", Severity: SeverityError},
+		SchemaConformanceRule:            {Code: "CALM015", Severity: SeverityError},
+		"SensitiveDataIsEncrypted":       {Code: "CALM016", Severity: SeverityError},
+		"NoPlaintextProtocols":           {Code: "CALM017", Severity: SeverityError},
+		"ProtocolsMatchInterfaces":       {Code: "CALM018", Severity: SeverityError},
+		"InterfaceReferencesResolve":     {Code: "CALM019", Severity: SeverityError},
+		"NoUnusedInterfaces":             {Code: "CALM020", Severity: SeverityWarning},
+		"NoInterfaceAddressCollisions":   {Code: "CALM021", Severity: SeverityError},
+		"NoSynchronousCycles":            {Code: "CALM022", Severity: SeverityError},
+		"LayeringHolds":                  {Code: "CALM023", Severity: SeverityError},
+		"NoSinglePointsOfFailure":        {Code: "CALM024", Severity: SeverityError},
+		"NoContainmentCycles":            {Code: "CALM025", Severity: SeverityError},
+		"ControlConfigsConform":          {Code: "CALM026", Severity: SeverityError},
+		"ControlRequirementsResolve":     {Code: "CALM027", Severity: SeverityWarning},
+	}
+)
+
+// RegisterRuleInfo sets the code and severity reported for a rule. Codes of
+// the built-in rules start with "CALM"; pick another prefix for custom rules.
+func RegisterRuleInfo(rule string, info RuleInfo) {
+	ruleInfosMu.Lock()
+	defer ruleInfosMu.Unlock()
+	ruleInfos[rule] = info
+}
+
+// LookupRuleInfo returns the code and severity of a rule.
+func LookupRuleInfo(rule string) (RuleInfo, bool) {
+	ruleInfosMu.RLock()
+	defer ruleInfosMu.RUnlock()
+	info, ok := ruleInfos[rule]
+	return info, ok
+}
+
+// Validate runs all rules against the architecture and returns errors. Each
+// error is completed with the code and severity of its rule and the kind of
+// element it is about; errors suppressed through calm-lint-ignore metadata
+// are dropped.
 func (a *Architecture) Validate(rules ...ValidationRule) []ValidationError {
+	elems := newLintElements(a)
 	var errors []ValidationError
 	for _, rule := range rules {
-		errors = append(errors, rule.Validate(a)...)
+		for _, e := range rule.Validate(a) {
+			e = elems.complete(e)
+			if !elems.suppressed(e) {
+				errors = append(errors, e)
+			}
+		}
 	}
-	return errors
+	return append(errors, elems.problems...)
 }
 
 // --- Built-in Validation Rules ---
@@ -67,7 +194,7 @@ func (r allServicesHaveHealthEndpoint) Validate(a *Architecture) []ValidationErr
 	var errors []ValidationError
 	for _, node := range a.Nodes {
 		if node.NodeType == Service {
-			if _, ok := node.Metadata["health-endpoint"]; !ok {
+			if _, ok := node.Metadata[MetaHealthEndpoint]; !ok {
 				errors = append(errors, ValidationError{
 					Rule:    r.Name(),
 					NodeID:  node.UniqueID,
@@ -87,76 +214,30 @@ func NoDanglingRelationships() ValidationRule { return noDanglingRelationships{}
 func (r noDanglingRelationships) Name() string { return "NoDanglingRelationships" }
 
 func (r noDanglingRelationships) Validate(a *Architecture) []ValidationError {
-	nodeIDs := make(map[string]bool)
-	for _, node := range a.Nodes {
-		nodeIDs[node.UniqueID] = true
-	}
+	g := NewGraph(a)
 
 	var errors []ValidationError
 	for _, rel := range a.Relationships {
-		rt := rel.RelationshipType
-
-		if rt.Connects != nil {
-			if !nodeIDs[rt.Connects.Source.Node] {
-				errors = append(errors, ValidationError{
-					Rule:    r.Name(),
-					NodeID:  rel.UniqueID,
-					Message: fmt.Sprintf("source node %q does not exist", rt.Connects.Source.Node),
-				})
-			}
-			if !nodeIDs[rt.Connects.Destination.Node] {
+		for _, ref := range rel.NodeRefs() {
+			if !g.HasNode(ref.ID) {
 				errors = append(errors, ValidationError{
 					Rule:    r.Name(),
 					NodeID:  rel.UniqueID,
-					Message: fmt.Sprintf("destination node %q does not exist", rt.Connects.Destination.Node),
+					Message: fmt.Sprintf("%s %q does not exist", ref.Role, ref.ID),
 				})
 			}
 		}
 
-		if rt.Interacts != nil {
-			if actor, ok := rt.Interacts["actor"].(string); ok {
-				if !nodeIDs[actor] {
-					errors = append(errors, ValidationError{
-						Rule:    r.Name(),
-						NodeID:  rel.UniqueID,
-						Message: fmt.Sprintf("actor %q does not exist", actor),
-					})
-				}
-			}
-			if nodes, ok := rt.Interacts["nodes"].([]string); ok {
-				for _, n := range nodes {
-					if !nodeIDs[n] {
-						errors = append(errors, ValidationError{
-							Rule:    r.Name(),
-							NodeID:  rel.UniqueID,
-							Message: fmt.Sprintf("target node %q does not exist", n),
-						})
-					}
-				}
-			}
-		}
-
-		if rt.ComposedOf != nil {
-			if container, ok := rt.ComposedOf["container"].(string); ok {
-				if !nodeIDs[container] {
+		for _, d := range rel.RelationshipType.Options {
+			for _, id := range d.Relationships {
+				if !g.HasRelationship(id) {
 					errors = append(errors, ValidationError{
 						Rule:    r.Name(),
 						NodeID:  rel.UniqueID,
-						Message: fmt.Sprintf("container node %q does not exist", container),
+						Message: fmt.Sprintf("option %q references non-existent relationship %q", d.Description, id),
 					})
 				}
 			}
-			if nodes, ok := rt.ComposedOf["nodes"].([]string); ok {
-				for _, n := range nodes {
-					if !nodeIDs[n] {
-						errors = append(errors, ValidationError{
-							Rule:    r.Name(),
-							NodeID:  rel.UniqueID,
-							Message: fmt.Sprintf("contained node %q does not exist", n),
-						})
-					}
-				}
-			}
 		}
 	}
 	return errors
@@ -170,15 +251,12 @@ func AllFlowsHaveValidTransitions() ValidationRule { return allFlowsHaveValidTra
 func (r allFlowsHaveValidTransitions) Name() string { return "AllFlowsHaveValidTransitions" }
 
 func (r allFlowsHaveValidTransitions) Validate(a *Architecture) []ValidationError {
-	relIDs := make(map[string]bool)
-	for _, rel := range a.Relationships {
-		relIDs[rel.UniqueID] = true
-	}
+	g := NewGraph(a)
 
 	var errors []ValidationError
 	for _, flow := range a.Flows {
 		for _, t := range flow.Transitions {
-			if !relIDs[t.RelationshipID] {
+			if !g.HasRelationship(t.RelationshipID) {
 				errors = append(errors, ValidationError{
 					Rule:    r.Name(),
 					NodeID:  flow.UniqueID,
@@ -201,7 +279,7 @@ func (r allDatabasesHaveBackupSchedule) Validate(a *Architecture) []ValidationEr
 	var errors []ValidationError
 	for _, node := range a.Nodes {
 		if node.NodeType == Database {
-			if _, ok := node.Metadata["backup-schedule"]; !ok {
+			if _, ok := node.Metadata[MetaBackupSchedule]; !ok {
 				errors = append(errors, ValidationError{
 					Rule:    r.Name(),
 					NodeID:  node.UniqueID,
@@ -223,9 +301,9 @@ func (r allTier1NodesHaveRunbook) Name() string { return "AllTier1NodesHaveRunbo
 func (r allTier1NodesHaveRunbook) Validate(a *Architecture) []ValidationError {
 	var errors []ValidationError
 	for _, node := range a.Nodes {
-		tier, _ := node.Metadata["tier"].(string)
-		if tier == "tier-1" {
-			if _, ok := node.Metadata["runbook"]; !ok {
+		tier, _ := node.Metadata[MetaTier].(string)
+		if Tier(tier) == Tier1 {
+			if _, ok := node.Metadata[MetaRunbook]; !ok {
 				errors = append(errors, ValidationError{
 					Rule:    r.Name(),
 					NodeID:  node.UniqueID,
@@ -237,48 +315,82 @@ func (r allTier1NodesHaveRunbook) Validate(a *Architecture) []ValidationError {
 	return errors
 }
 
-// noUnusedNodes checks that all nodes are referenced by at least one relationship
-type noUnusedNodes struct{}
+// noUnknownNodeTypes checks that every node uses a CALM or registered custom node type
+type noUnknownNodeTypes struct{}
 
-func NoUnusedNodes() ValidationRule { return noUnusedNodes{} }
+func NoUnknownNodeTypes() ValidationRule { return noUnknownNodeTypes{} }
 
-func (r noUnusedNodes) Name() string { return "NoUnusedNodes" }
+func (r noUnknownNodeTypes) Name() string { return "NoUnknownNodeTypes" }
 
-func (r noUnusedNodes) Validate(a *Architecture) []ValidationError {
-	usedNodes := make(map[string]bool)
+func (r noUnknownNodeTypes) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	for _, node := range a.Nodes {
+		if !IsKnownNodeType(node.NodeType) {
+			errors = append(errors, ValidationError{
+				Rule:    r.Name(),
+				NodeID:  node.UniqueID,
+				Message: fmt.Sprintf("unknown node-type %q (use RegisterNodeType for custom types)", node.NodeType),
+			})
+		}
+	}
+	return errors
+}
 
-	for _, rel := range a.Relationships {
-		rt := rel.RelationshipType
+// interfaceDefinitionsConform checks that interface definitions have a config
+// that conforms to the schema at their definition-url
+type interfaceDefinitionsConform struct {
+	schemas SchemaValidator
+}
 
-		if rt.Connects != nil {
-			usedNodes[rt.Connects.Source.Node] = true
-			usedNodes[rt.Connects.Destination.Node] = true
-		}
-		if rt.Interacts != nil {
-			if actor, ok := rt.Interacts["actor"].(string); ok {
-				usedNodes[actor] = true
-			}
-			if nodes, ok := rt.Interacts["nodes"].([]string); ok {
-				for _, n := range nodes {
-					usedNodes[n] = true
+func InterfaceDefinitionsConform(schemas SchemaValidator) ValidationRule {
+	return interfaceDefinitionsConform{schemas: schemas}
+}
+
+func (r interfaceDefinitionsConform) Name() string { return "InterfaceDefinitionsConform" }
+
+func (r interfaceDefinitionsConform) Validate(a *Architecture) []ValidationError {
+	var errors []ValidationError
+	report := func(nodeID, msg string) {
+		errors = append(errors, ValidationError{Rule: r.Name(), NodeID: nodeID, Message: msg})
+	}
+	for _, node := range a.Nodes {
+		for _, intf := range node.Interfaces {
+			if intf.DefinitionURL == "" {
+				if intf.Config != nil {
+					report(node.UniqueID, fmt.Sprintf("interface %q has config but no definition-url", intf.UniqueID))
 				}
+				continue
 			}
-		}
-		if rt.ComposedOf != nil {
-			if container, ok := rt.ComposedOf["container"].(string); ok {
-				usedNodes[container] = true
+			if intf.Config == nil {
+				report(node.UniqueID, fmt.Sprintf("interface %q has a definition-url but no config", intf.UniqueID))
+				continue
 			}
-			if nodes, ok := rt.ComposedOf["nodes"].([]string); ok {
-				for _, n := range nodes {
-					usedNodes[n] = true
-				}
+			violations, err := r.schemas.ValidateURL(intf.DefinitionURL, intf.Config)
+			if err != nil {
+				report(node.UniqueID, fmt.Sprintf("interface %q: %v", intf.UniqueID, err))
+				continue
+			}
+			for _, v := range violations {
+				report(node.UniqueID, fmt.Sprintf("interface %q config: %s", intf.UniqueID, v))
 			}
 		}
 	}
+	return errors
+}
+
+// noUnusedNodes checks that all nodes are referenced by at least one relationship
+type noUnusedNodes struct{}
+
+func NoUnusedNodes() ValidationRule { return noUnusedNodes{} }
+
+func (r noUnusedNodes) Name() string { return "NoUnusedNodes" }
+
+func (r noUnusedNodes) Validate(a *Architecture) []ValidationError {
+	g := NewGraph(a)
 
 	var errors []ValidationError
 	for _, node := range a.Nodes {
-		if !usedNodes[node.UniqueID] {
+		if !g.IsReferenced(node.UniqueID) {
 			errors = append(errors, ValidationError{
 				Rule:    r.Name(),
 				NodeID:  node.UniqueID,
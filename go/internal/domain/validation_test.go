@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestValidationError_String(t *testing.T) {
 	t.Run("with node id", func(t *testing.T) {
@@ -16,6 +19,13 @@ func TestValidationError_String(t *testing.T) {
 			t.Fatalf("unexpected string: %s", got)
 		}
 	})
+
+	t.Run("with code and severity", func(t *testing.T) {
+		err := ValidationError{Rule: "RuleC", Code: "X001", NodeID: "n", Message: "bad", Severity: SeverityWarning}
+		if got := err.String(); got != "[X001 RuleC warning] n: bad" {
+			t.Fatalf("unexpected string: %s", got)
+		}
+	})
 }
 
 func TestValidationRules(t *testing.T) {
@@ -97,3 +107,69 @@ func TestValidationRules(t *testing.T) {
 		}
 	})
 }
+
+func TestNoUnknownNodeTypes(t *testing.T) {
+	arch := NewArchitecture("a", "A", "desc")
+	arch.DefineNode("net", Network, "Network", "desc")
+	arch.DefineNode("asset", DataAsset, "Asset", "desc")
+	arch.DefineNode("typo", NodeType("servce"), "Typo", "desc")
+	arch.DefineNode("mf", NodeType("test-mainframe"), "Mainframe", "desc")
+
+	errs := NoUnknownNodeTypes().Validate(arch)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors before registration, got %v", errs)
+	}
+
+	RegisterNodeType("test-mainframe")
+	t.Cleanup(func() { UnregisterNodeType("test-mainframe") })
+	errs = NoUnknownNodeTypes().Validate(arch)
+	if len(errs) != 1 || errs[0].NodeID != "typo" {
+		t.Fatalf("expected only the misspelled type to be reported, got %v", errs)
+	}
+
+	if name, ok := NodeTypeConstName(DataAsset); !ok || name != "DataAsset" {
+		t.Errorf("expected DataAsset constant, got %q", name)
+	}
+	if _, ok := NodeTypeConstName("test-mainframe"); ok {
+		t.Errorf("custom types have no Go constant")
+	}
+}
+
+type stubSchemas map[string][]string
+
+func (s stubSchemas) ValidateURL(url string, doc any) ([]string, error) {
+	violations, ok := s[url]
+	if !ok {
+		return nil, fmt.Errorf("%s is not in url-mapping.json", url)
+	}
+	return violations, nil
+}
+
+func TestInterfaceDefinitionsConform(t *testing.T) {
+	arch := NewArchitecture("a", "A", "desc")
+	api := arch.DefineNode("api", Service, "API", "desc")
+	api.Interface("rest", "").Definition("https://example.com/rest.json", map[string]any{"port": 0})
+	api.Interface("grpc", "").Definition("https://example.com/grpc.json", map[string]any{})
+	api.Interface("plain", "HTTPS").SetPort(443)
+	api.Interface("half", "").Definition("https://example.com/rest.json", nil)
+
+	if got := api.Interfaces[0]; got.DefinitionURL != "https://example.com/rest.json" || got.Config == nil {
+		t.Fatalf("expected definition to be set, got %+v", got)
+	}
+
+	rule := InterfaceDefinitionsConform(stubSchemas{"https://example.com/rest.json": {"port: must be >= 1"}})
+	errs := rule.Validate(arch)
+	want := []string{
+		`interface "rest" config: port: must be >= 1`,
+		`interface "grpc": https://example.com/grpc.json is not in url-mapping.json`,
+		`interface "half" has a definition-url but no config`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for i, e := range errs {
+		if e.NodeID != "api" || e.Message != want[i] {
+			t.Errorf("error %d: got %v, want %q", i, e, want[i])
+		}
+	}
+}
@@ -4,22 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/token"
 	"log"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 )
 
 // SyncArchitectureFromJSON synchronizes the AST with the provided CALM JSON.
+// Node metadata may use either CALM form; it is written as a WithMeta option
+// for added nodes, while the metadata of existing nodes is left to the source.
 func SyncArchitectureFromJSON(f *ast.File, jsonStr string) error {
 	var archData struct {
 		Nodes []struct {
-			ID   string `json:"unique-id"`
-			Type string `json:"node-type"`
-			Name string `json:"name"`
-			Desc string `json:"description"`
+			ID   string          `json:"unique-id"`
+			Type string          `json:"node-type"`
+			Name string          `json:"name"`
+			Desc string          `json:"description"`
+			Meta domain.Metadata `json:"metadata"`
 		} `json:"nodes"`
 	}
 
@@ -67,13 +73,19 @@ func SyncArchitectureFromJSON(f *ast.File, jsonStr string) error {
 			delete(existingNodeIDs, n.ID)
 		} else {
 			// Add new node
-			nodeType := "Service"
+			nodeType := string(domain.Service)
 			if n.Type != "" {
-				// Convert "service" -> "Service"
-				caser := cases.Title(language.English)
-				nodeType = caser.String(strings.ToLower(n.Type))
+				nodeType = n.Type
 			}
-			if err := AddNodeInAST(f, n.ID, nodeType, n.Name, n.Desc); err != nil {
+			var opts []ast.Expr
+			if len(n.Meta) > 0 {
+				opt, err := withMetaExpr(nodeTypeQualifier(f), n.Meta)
+				if err != nil {
+					return fmt.Errorf("metadata of node %q: %w", n.ID, err)
+				}
+				opts = append(opts, opt)
+			}
+			if err := AddNodeInAST(f, n.ID, nodeType, n.Name, n.Desc, opts...); err != nil {
 				return err
 			}
 		}
@@ -133,8 +145,10 @@ func UpdateNodeNameInAST(f *ast.File, nodeID, newName string) error {
 }
 
 // AddNodeInAST appends a new DefineNode call to the build or defineNodes function in the AST.
-func AddNodeInAST(f *ast.File, nodeID, nodeType, name, desc string) error {
-	typeExpr := findDefineNodeTypeExpr(f)
+// nodeType may be a CALM node-type ("data-asset") or a Go constant name ("DataAsset").
+// opts are appended to the call as node options.
+func AddNodeInAST(f *ast.File, nodeID, nodeType, name, desc string, opts ...ast.Expr) error {
+	typeExpr := nodeTypeExpr(nodeTypeQualifier(f), nodeType)
 	for _, decl := range f.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
 		if !ok {
@@ -158,9 +172,12 @@ func AddNodeInAST(f *ast.File, nodeID, nodeType, name, desc string) error {
 			}
 		}
 
-		// Create: <receiver>.DefineNode("id", <Type>, "name", "desc")
-		if typeExpr == nil {
-			typeExpr = defaultNodeTypeExpr(nodeType)
+		// Create: <receiver>.DefineNode("id", <Type>, "name", "desc", opts...)
+		args := []ast.Expr{
+			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", nodeID)},
+			typeExpr,
+			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", name)},
+			&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", desc)},
 		}
 		newStmt := &ast.ExprStmt{
 			X: &ast.CallExpr{
@@ -168,12 +185,7 @@ func AddNodeInAST(f *ast.File, nodeID, nodeType, name, desc string) error {
 					X:   ast.NewIdent(receiverName),
 					Sel: ast.NewIdent("DefineNode"),
 				},
-				Args: []ast.Expr{
-					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", nodeID)},
-					cloneTypeExpr(typeExpr),
-					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", name)},
-					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", desc)},
-				},
+				Args: append(args, opts...),
 			},
 		}
 
@@ -257,49 +269,89 @@ func UpdateNodePropertyInAST(f *ast.File, nodeID, property, value string) error
 	return nil
 }
 
-func findDefineNodeTypeExpr(f *ast.File) ast.Expr {
-	var found ast.Expr
+// nodeTypeQualifier returns the package qualifier (e.g. "domain") used by the
+// node-type argument of existing DefineNode calls, or "" for unqualified names.
+func nodeTypeQualifier(f *ast.File) string {
+	qualifier := ""
 	ast.Inspect(f, func(n ast.Node) bool {
 		call, ok := n.(*ast.CallExpr)
 		if !ok {
 			return true
 		}
 		sel, ok := call.Fun.(*ast.SelectorExpr)
-		if !ok || sel.Sel.Name != "DefineNode" {
+		if !ok || sel.Sel.Name != "DefineNode" || len(call.Args) < 2 {
 			return true
 		}
-		if len(call.Args) < 2 {
-			return true
+		typeArg := call.Args[1]
+		if conv, ok := typeArg.(*ast.CallExpr); ok {
+			typeArg = conv.Fun // NodeType("custom") or domain.NodeType("custom")
+		}
+		if typeSel, ok := typeArg.(*ast.SelectorExpr); ok {
+			if pkg, ok := typeSel.X.(*ast.Ident); ok {
+				qualifier = pkg.Name
+			}
 		}
-		found = call.Args[1]
 		return false
 	})
-	return found
+	return qualifier
 }
 
-func defaultNodeTypeExpr(nodeType string) ast.Expr {
-	if strings.Contains(nodeType, ".") {
-		parts := strings.SplitN(nodeType, ".", 2)
-		return &ast.SelectorExpr{
-			X:   ast.NewIdent(parts[0]),
-			Sel: ast.NewIdent(parts[1]),
+// nodeTypeExpr builds the node-type argument for DefineNode: the Go constant
+// for built-in types, or a NodeType("...") conversion for custom types.
+func nodeTypeExpr(qualifier, nodeType string) ast.Expr {
+	qualify := func(name string) ast.Expr {
+		if qualifier == "" {
+			return ast.NewIdent(name)
 		}
+		return &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: ast.NewIdent(name)}
+	}
+
+	if constName, ok := domain.NodeTypeConstName(domain.NodeType(nodeType)); ok {
+		return qualify(constName)
+	}
+	if _, ok := domain.NodeTypeFromConstName(nodeType); ok {
+		return qualify(nodeType)
+	}
+	return &ast.CallExpr{
+		Fun:  qualify("NodeType"),
+		Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", nodeType)}},
 	}
-	return ast.NewIdent(nodeType)
 }
 
-func cloneTypeExpr(expr ast.Expr) ast.Expr {
-	switch v := expr.(type) {
-	case *ast.Ident:
-		return ast.NewIdent(v.Name)
-	case *ast.SelectorExpr:
-		return &ast.SelectorExpr{
-			X:   cloneTypeExpr(v.X),
-			Sel: ast.NewIdent(v.Sel.Name),
+// withMetaExpr builds a WithMeta(map[string]any{...}) node option holding meta.
+func withMetaExpr(qualifier string, meta domain.Metadata) (ast.Expr, error) {
+	fn := "WithMeta"
+	if qualifier != "" {
+		fn = qualifier + "." + fn
+	}
+	return parser.ParseExpr(fn + "(" + goLiteral(map[string]any(meta)) + ")")
+}
+
+// goLiteral returns Go source for a value decoded from JSON.
+func goLiteral(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = goLiteral(item)
 		}
-	default:
-		return expr
+		return "[]any{" + strings.Join(items, ", ") + "}"
+	case map[string]any:
+		items := make([]string, 0, len(val))
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			items = append(items, strconv.Quote(k)+": "+goLiteral(val[k]))
+		}
+		return "map[string]any{" + strings.Join(items, ", ") + "}"
 	}
+	return fmt.Sprintf("%#v", v)
 }
 
 func isWithOwnerCall(fn ast.Expr) bool {
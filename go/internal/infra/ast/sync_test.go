@@ -126,7 +126,107 @@ func build() {
 	if !strings.Contains(actual, `"Updated Name"`) {
 		t.Errorf("expected updated name not found")
 	}
-	if !strings.Contains(actual, `"node2"`) {
-		t.Errorf("expected new node not found")
+	if !strings.Contains(actual, `arch.DefineNode("node2", Database, "New DB", "new desc")`) {
+		t.Errorf("expected new node with Database type, got:\n%s", actual)
+	}
+}
+
+func TestSyncArchitectureFromJSONMetadataForms(t *testing.T) {
+	src := `package main
+func build() {
+	arch.DefineNode("node1", Service, "Old Name", "desc")
+}`
+
+	// Metadata may be an object or an array of objects; added nodes keep it.
+	jsonInput := `{
+		"unique-id": "test-arch",
+		"nodes": [
+			{
+				"unique-id": "node1",
+				"node-type": "service",
+				"name": "Updated Name",
+				"description": "updated desc",
+				"metadata": [{"tier": "tier-1"}, {"owner": "team-a"}]
+			},
+			{
+				"unique-id": "node2",
+				"node-type": "database",
+				"name": "New DB",
+				"description": "new desc",
+				"metadata": {"tier": "tier-2"}
+			},
+			{
+				"unique-id": "node3",
+				"node-type": "service",
+				"name": "New Svc",
+				"description": "new desc",
+				"metadata": [{"tier": "tier-3"}, {"tags": ["pci"], "replicas": 2}]
+			}
+		]
+	}`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SyncArchitectureFromJSON(f, jsonInput); err != nil {
+		t.Fatalf("failed to sync from JSON: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := buf.String()
+	if !strings.Contains(actual, `"Updated Name"`) {
+		t.Errorf("expected updated name not found")
+	}
+	for _, want := range []string{
+		`arch.DefineNode("node2", Database, "New DB", "new desc", WithMeta(map[string]any{"tier": "tier-2"}))`,
+		`arch.DefineNode("node3", Service, "New Svc", "new desc", WithMeta(map[string]any{"replicas": 2, "tags": []any{"pci"}, "tier": "tier-3"}))`,
+	} {
+		if !strings.Contains(actual, want) {
+			t.Errorf("expected %s, got:\n%s", want, actual)
+		}
+	}
+}
+
+func TestAddNodeTypeExpressions(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		nodeType string
+		want     string
+	}{
+		{"qualified CALM type", `domain.Service`, "data-asset", `domain.DataAsset`},
+		{"qualified constant name", `domain.Service`, "WebClient", `domain.WebClient`},
+		{"unqualified CALM type", `Service`, "ldap", `LDAP`},
+		{"custom type", `domain.Service`, "mainframe", `domain.NodeType("mainframe")`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := "package main\nfunc BuildArchitecture() {\n\tarch.DefineNode(\"node1\", " + tc.src + ", \"N\", \"d\")\n}"
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "test.go", src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := AddNodeInAST(f, "node2", tc.nodeType, "Name 2", "desc"); err != nil {
+				t.Fatalf("failed to add node: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := format.Node(&buf, fset, f); err != nil {
+				t.Fatal(err)
+			}
+			want := `arch.DefineNode("node2", ` + tc.want + `, "Name 2", "desc")`
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expected %s, got:\n%s", want, buf.String())
+			}
+		})
 	}
 }
@@ -1,20 +1,66 @@
 package generator
 
 import (
+	"fmt"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 	"github.com/sokoide/advent-of-calm-2025/internal/infra/render"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/schema"
 	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
 )
 
 // DefaultGenerator returns the standard CALM generator setup shared by CLI and Studio.
 func DefaultGenerator() usecase.Generator {
+	schemas := schema.DefaultValidator()
 	return usecase.Generator{
-		Builder: usecase.EcommerceBuilder{},
+		Builder: usecase.NewEcommerceBuilder(),
 		Renderers: map[usecase.OutputFormat]usecase.Renderer{
 			usecase.FormatJSON:   render.JSONRenderer{},
 			usecase.FormatD2:     render.D2Renderer{},
 			usecase.FormatRichD2: render.RichD2Renderer{},
 		},
-		Validator:     usecase.RuleValidator{Rules: usecase.DefaultValidationRules()},
+		Validator:     usecase.RuleValidator{Rules: defaultRules(schemas)},
+		Documents:     schemas,
 		DefaultFormat: usecase.FormatJSON,
 	}
 }
+
+// ForArchitecture returns the default generator wired to the builder
+// registered under id.
+func ForArchitecture(id string) (usecase.Generator, error) {
+	b, err := usecase.LookupBuilder(id)
+	if err != nil {
+		return usecase.Generator{}, err
+	}
+	gen := DefaultGenerator()
+	gen.Builder = b
+	gen.Ref = id
+	return gen, nil
+}
+
+// WithPolicy applies the severity overrides of p to the rules of gen's
+// RuleValidator and adds the rules of p after them.
+func WithPolicy(gen usecase.Generator, p *domain.Policy) (usecase.Generator, error) {
+	rules, err := p.ValidationRules()
+	if err != nil {
+		return gen, err
+	}
+	v, ok := gen.Validator.(usecase.RuleValidator)
+	if !ok {
+		return gen, fmt.Errorf("policy rules need a RuleValidator, got %T", gen.Validator)
+	}
+	base, err := p.ApplySeverities(v.Rules)
+	if err != nil {
+		return gen, err
+	}
+	v.Rules = append(append([]domain.ValidationRule(nil), base...), rules...)
+	gen.Validator = v
+	return gen, nil
+}
+
+// defaultRules adds the rules that need infrastructure, such as schema and
+// control config lookup through url-mapping.json, to the use-case defaults.
+func defaultRules(schemas *schema.Validator) []domain.ValidationRule {
+	rules := append(usecase.DefaultValidationRules(), domain.InterfaceDefinitionsConform(schemas))
+	return append(rules, domain.ControlRules(schemas, schemas)...)
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/parser"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/schema"
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+// DetailsLoader resolves node details references, trying in order:
+// a builder registered under that ID, a URL listed in url-mapping.json, and a
+// CALM JSON file under BaseDir. A file path found in a CALM file is relative
+// to that file's directory (see ResolveRef). File paths that leave BaseDir, such as
+// absolute or "../" paths and symlinks pointing out, are rejected, because
+// Studio passes references straight from its HTTP requests.
+type DetailsLoader struct {
+	Mapping *schema.URLMapping
+	BaseDir string
+}
+
+// NewDetailsLoader uses the url-mapping.json found from dir upwards and
+// resolves file paths against dir.
+func NewDetailsLoader(dir string) DetailsLoader {
+	// A missing mapping only disables URL resolution; Resolve accepts nil.
+	m, _ := schema.FindURLMapping(dir)
+	return DetailsLoader{Mapping: m, BaseDir: dir}
+}
+
+// ResolveRef returns ref relative to BaseDir when it is a file path found in
+// the file from; other references are returned as they are.
+func (l DetailsLoader) ResolveRef(from, ref string) string {
+	if !l.isFile(from) || !l.isFile(ref) {
+		return ref
+	}
+	return filepath.Join(filepath.Dir(from), ref)
+}
+
+// isFile reports whether ref is a file path rather than a builder ID or a
+// mapped URL.
+func (l DetailsLoader) isFile(ref string) bool {
+	if ref == "" {
+		return false
+	}
+	if _, err := usecase.LookupBuilder(ref); err == nil {
+		return false
+	}
+	_, err := l.Mapping.Resolve(ref)
+	return err != nil
+}
+
+// Load returns the architecture ref points to.
+func (l DetailsLoader) Load(ref string) (*domain.Architecture, error) {
+	if b, err := usecase.LookupBuilder(ref); err == nil {
+		if c, ok := b.(interface {
+			Compose() (*domain.Architecture, error)
+		}); ok {
+			return c.Compose()
+		}
+		return b.Build(), nil
+	}
+	if path, err := l.Mapping.Resolve(ref); err == nil {
+		return parser.LoadJSONFile(path)
+	}
+
+	notFound := fmt.Errorf(
+		"cannot resolve %q: not a registered architecture, a url-mapping entry or a file under %s", ref, l.BaseDir)
+	if !filepath.IsLocal(ref) {
+		return nil, notFound
+	}
+	root, err := os.OpenRoot(l.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	data, err := root.ReadFile(ref)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	arch, err := parser.ParseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	return arch, nil
+}
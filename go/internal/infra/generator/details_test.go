@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+func TestDetailsLoader(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, link string) {
+		arch := `{"unique-id": "` + filepath.Base(name) + `", "nodes": [{"unique-id": "n", "node-type": "system",` +
+			` "name": "N", "description": "d", "details": {"detailed-architecture": "` + link + `"}}]}`
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(arch), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("root.json", "sub/a.json")
+	write("sub/a.json", "b.json")
+	write("sub/b.json", "../root.json")
+
+	t.Run("should resolve file links against the file holding them", func(t *testing.T) {
+		loader := DetailsLoader{BaseDir: dir}
+		root, err := loader.Load("root.json")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		linked, err := usecase.FollowDetails(root, "root.json", loader)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var refs []string
+		for _, l := range linked {
+			refs = append(refs, l.Ref)
+		}
+		want := []string{filepath.Join("sub", "a.json"), filepath.Join("sub", "b.json")}
+		if len(refs) != len(want) || refs[0] != want[0] || refs[1] != want[1] {
+			t.Errorf("expected %v, got %v", want, refs)
+		}
+	})
+
+	t.Run("should keep builder IDs as they are", func(t *testing.T) {
+		loader := DetailsLoader{BaseDir: dir}
+		if got := loader.ResolveRef("sub/a.json", usecase.DefaultArchitectureID); got != usecase.DefaultArchitectureID {
+			t.Errorf("expected builder ID to be kept, got %q", got)
+		}
+		if got := loader.ResolveRef(usecase.DefaultArchitectureID, "sub/a.json"); got != "sub/a.json" {
+			t.Errorf("expected link from a builder to be kept, got %q", got)
+		}
+	})
+
+	t.Run("should reject paths outside the base directory", func(t *testing.T) {
+		if _, err := (DetailsLoader{BaseDir: filepath.Join(dir, "sub")}).Load("../root.json"); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
@@ -0,0 +1,431 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// PathError reports malformed CALM JSON together with the JSON path of the offending value.
+type PathError struct {
+	Path    string
+	Message string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// JSONParser implements the domain.Parser port for CALM JSON documents.
+type JSONParser struct{}
+
+// Parse converts CALM JSON content into a CALM architecture model.
+func (JSONParser) Parse(content string) (*domain.Architecture, error) {
+	return ParseJSON([]byte(content))
+}
+
+// LoadJSONFile reads and parses a CALM JSON file from disk.
+func LoadJSONFile(path string) (*domain.Architecture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	arch, err := ParseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return arch, nil
+}
+
+// Method-free copies of the domain types: embedding them lets the decode
+// structs below shadow fields such as metadata and controls, which the domain
+// types' own UnmarshalJSON methods would otherwise decode as a whole.
+type (
+	plainArchitecture domain.Architecture
+	plainNode         domain.Node
+	plainRelationship domain.Relationship
+	plainFlow         domain.Flow
+)
+
+// ParseJSON decodes a CALM JSON document into a domain architecture.
+// Relationship variants are decoded into the same typed structs the Go DSL
+// produces, and every node points back to its architecture.
+func ParseJSON(data []byte) (*domain.Architecture, error) {
+	var doc struct {
+		plainArchitecture
+		Metadata      json.RawMessage            `json:"metadata"`
+		Controls      map[string]json.RawMessage `json:"controls"`
+		Flows         []json.RawMessage          `json:"flows"`
+		Nodes         []json.RawMessage          `json:"nodes"`
+		Relationships []json.RawMessage          `json:"relationships"`
+	}
+	if err := decodeAt(data, "$", &doc); err != nil {
+		return nil, err
+	}
+
+	arch := (*domain.Architecture)(&doc.plainArchitecture)
+	var err error
+	if arch.Metadata, arch.MetadataLayout, err = parseMetadata(doc.Metadata, "$.metadata"); err != nil {
+		return nil, err
+	}
+
+	controls, err := parseControls(doc.Controls, "$.controls")
+	if err != nil {
+		return nil, err
+	}
+	arch.Controls = controls
+
+	for i, raw := range doc.Nodes {
+		node, err := parseNode(raw, fmt.Sprintf("$.nodes[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		node.Arch = arch
+		arch.Nodes = append(arch.Nodes, node)
+	}
+
+	for i, raw := range doc.Relationships {
+		rel, err := parseRelationship(raw, fmt.Sprintf("$.relationships[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		arch.Relationships = append(arch.Relationships, rel)
+	}
+
+	for i, raw := range doc.Flows {
+		flow, err := parseFlow(raw, fmt.Sprintf("$.flows[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		arch.Flows = append(arch.Flows, flow)
+	}
+
+	return arch, nil
+}
+
+func parseNode(raw json.RawMessage, path string) (*domain.Node, error) {
+	var doc struct {
+		plainNode
+		Metadata   json.RawMessage            `json:"metadata"`
+		Controls   map[string]json.RawMessage `json:"controls"`
+		Interfaces []json.RawMessage          `json:"interfaces"`
+	}
+	if err := decodeAt(raw, path, &doc); err != nil {
+		return nil, err
+	}
+
+	node := (*domain.Node)(&doc.plainNode)
+	if node.UniqueID == "" {
+		return nil, &PathError{Path: path + ".unique-id", Message: "required"}
+	}
+	if node.NodeType == "" {
+		return nil, &PathError{Path: path + ".node-type", Message: "required"}
+	}
+	var err error
+	if node.Metadata, node.MetadataLayout, err = parseMetadata(doc.Metadata, path+".metadata"); err != nil {
+		return nil, err
+	}
+
+	controls, err := parseControls(doc.Controls, path+".controls")
+	if err != nil {
+		return nil, err
+	}
+	node.Controls = controls
+
+	for i, rawIntf := range doc.Interfaces {
+		intfPath := fmt.Sprintf("%s.interfaces[%d]", path, i)
+		var intf domain.Interface
+		if err := decodeAt(rawIntf, intfPath, &intf); err != nil {
+			return nil, err
+		}
+		if intf.UniqueID == "" {
+			return nil, &PathError{Path: intfPath + ".unique-id", Message: "required"}
+		}
+		node.Interfaces = append(node.Interfaces, intf)
+	}
+
+	return node, nil
+}
+
+func parseRelationship(raw json.RawMessage, path string) (*domain.Relationship, error) {
+	var doc struct {
+		plainRelationship
+		Metadata         json.RawMessage            `json:"metadata"`
+		Controls         map[string]json.RawMessage `json:"controls"`
+		RelationshipType map[string]json.RawMessage `json:"relationship-type"`
+	}
+	if err := decodeAt(raw, path, &doc); err != nil {
+		return nil, err
+	}
+
+	rel := (*domain.Relationship)(&doc.plainRelationship)
+	if rel.UniqueID == "" {
+		return nil, &PathError{Path: path + ".unique-id", Message: "required"}
+	}
+	var err error
+	if rel.Metadata, rel.MetadataLayout, err = parseMetadata(doc.Metadata, path+".metadata"); err != nil {
+		return nil, err
+	}
+	if len(doc.Controls) > 0 {
+		controls, err := parseControls(doc.Controls, path+".controls")
+		if err != nil {
+			return nil, err
+		}
+		rel.Controls = controls
+	}
+
+	typePath := path + ".relationship-type"
+	if len(doc.RelationshipType) != 1 {
+		return nil, &PathError{
+			Path:    typePath,
+			Message: fmt.Sprintf("expected exactly one relationship type, got %d", len(doc.RelationshipType)),
+		}
+	}
+
+	for kind, body := range doc.RelationshipType {
+		kindPath := typePath + "." + kind
+		switch kind {
+		case "connects":
+			var connects domain.Connects
+			if err := decodeAt(body, kindPath, &connects); err != nil {
+				return nil, err
+			}
+			if connects.Source.Node == "" {
+				return nil, &PathError{Path: kindPath + ".source.node", Message: "required"}
+			}
+			if connects.Destination.Node == "" {
+				return nil, &PathError{Path: kindPath + ".destination.node", Message: "required"}
+			}
+			rel.RelationshipType.Connects = &connects
+		case "interacts":
+			actor, nodes, err := parseNodeGroup(body, kindPath, "actor")
+			if err != nil {
+				return nil, err
+			}
+			rel.RelationshipType.Interacts = &domain.Interacts{Actor: actor, Nodes: nodes}
+		case "composed-of":
+			container, nodes, err := parseNodeGroup(body, kindPath, "container")
+			if err != nil {
+				return nil, err
+			}
+			rel.RelationshipType.ComposedOf = &domain.ComposedOf{Container: container, Nodes: nodes}
+		case "deployed-in":
+			container, nodes, err := parseNodeGroup(body, kindPath, "container")
+			if err != nil {
+				return nil, err
+			}
+			rel.RelationshipType.DeployedIn = &domain.DeployedIn{Container: container, Nodes: nodes}
+		case "options":
+			options, err := parseOptions(body, kindPath)
+			if err != nil {
+				return nil, err
+			}
+			rel.RelationshipType.Options = options
+		default:
+			return nil, &PathError{Path: kindPath, Message: "unsupported relationship type"}
+		}
+	}
+
+	return rel, nil
+}
+
+// parseNodeGroup decodes the {"<key>": id, "nodes": [ids...]} shape shared by
+// interacts, composed-of and deployed-in relationships. Keys other than <key> and "nodes"
+// are rejected so that misspellings do not silently drop data.
+func parseNodeGroup(raw json.RawMessage, path, key string) (string, []string, error) {
+	var doc map[string]json.RawMessage
+	if err := decodeAt(raw, path, &doc); err != nil {
+		return "", nil, err
+	}
+	for k := range doc {
+		if k != key && k != "nodes" {
+			return "", nil, &PathError{Path: path + "." + k, Message: "unknown field"}
+		}
+	}
+
+	var owner string
+	if err := decodeAt(doc[key], path+"."+key, &owner); err != nil {
+		return "", nil, err
+	}
+	if owner == "" {
+		return "", nil, &PathError{Path: path + "." + key, Message: "required"}
+	}
+
+	var rawNodes []json.RawMessage
+	if err := decodeAt(doc["nodes"], path+".nodes", &rawNodes); err != nil {
+		return "", nil, err
+	}
+	if len(rawNodes) == 0 {
+		return "", nil, &PathError{Path: path + ".nodes", Message: "at least one node is required"}
+	}
+
+	nodes := make([]string, 0, len(rawNodes))
+	for i, rawNode := range rawNodes {
+		var id string
+		if err := decodeAt(rawNode, fmt.Sprintf("%s.nodes[%d]", path, i), &id); err != nil {
+			return "", nil, err
+		}
+		nodes = append(nodes, id)
+	}
+
+	return owner, nodes, nil
+}
+
+// parseOptions decodes the decision list of an options relationship.
+func parseOptions(raw json.RawMessage, path string) ([]domain.Decision, error) {
+	var rawDecisions []json.RawMessage
+	if err := decodeAt(raw, path, &rawDecisions); err != nil {
+		return nil, err
+	}
+	if len(rawDecisions) == 0 {
+		return nil, &PathError{Path: path, Message: "at least one option is required"}
+	}
+
+	decisions := make([]domain.Decision, 0, len(rawDecisions))
+	for i, rawDecision := range rawDecisions {
+		var d domain.Decision
+		if err := decodeAt(rawDecision, fmt.Sprintf("%s[%d]", path, i), &d); err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, nil
+}
+
+func parseFlow(raw json.RawMessage, path string) (*domain.Flow, error) {
+	var doc struct {
+		plainFlow
+		Metadata    json.RawMessage            `json:"metadata"`
+		Controls    map[string]json.RawMessage `json:"controls"`
+		Transitions []json.RawMessage          `json:"transitions"`
+	}
+	if err := decodeAt(raw, path, &doc); err != nil {
+		return nil, err
+	}
+
+	flow := (*domain.Flow)(&doc.plainFlow)
+	if flow.UniqueID == "" {
+		return nil, &PathError{Path: path + ".unique-id", Message: "required"}
+	}
+	var err error
+	if flow.Metadata, flow.MetadataLayout, err = parseMetadata(doc.Metadata, path+".metadata"); err != nil {
+		return nil, err
+	}
+	if len(doc.Controls) > 0 {
+		controls, err := parseControls(doc.Controls, path+".controls")
+		if err != nil {
+			return nil, err
+		}
+		flow.Controls = controls
+	}
+
+	for i, rawT := range doc.Transitions {
+		tPath := fmt.Sprintf("%s.transitions[%d]", path, i)
+		var t domain.Transition
+		if err := decodeAt(rawT, tPath, &t); err != nil {
+			return nil, err
+		}
+		if t.RelationshipID == "" {
+			return nil, &PathError{Path: tPath + ".relationship-unique-id", Message: "required"}
+		}
+		flow.Transitions = append(flow.Transitions, t)
+	}
+
+	return flow, nil
+}
+
+// parseMetadata decodes metadata in either CALM form (object or array of
+// objects); absent metadata becomes an empty map.
+func parseMetadata(raw json.RawMessage, path string) (domain.Metadata, domain.MetadataLayout, error) {
+	meta, layout, err := domain.DecodeMetadata(raw)
+	if err != nil {
+		return nil, nil, &PathError{Path: path, Message: err.Error()}
+	}
+	if meta == nil {
+		meta = make(domain.Metadata)
+	}
+	return meta, layout, nil
+}
+
+func parseControls(raws map[string]json.RawMessage, path string) (map[string]*domain.Control, error) {
+	controls := make(map[string]*domain.Control, len(raws))
+	for id, raw := range raws {
+		ctrlPath := path + "." + id
+		var doc struct {
+			domain.Control
+			Requirements []json.RawMessage `json:"requirements"`
+		}
+		if err := decodeAt(raw, ctrlPath, &doc); err != nil {
+			return nil, err
+		}
+
+		ctrl := doc.Control
+		for i, rawReq := range doc.Requirements {
+			reqPath := fmt.Sprintf("%s.requirements[%d]", ctrlPath, i)
+			var req domain.Requirement
+			if err := decodeAt(rawReq, reqPath, &req); err != nil {
+				return nil, err
+			}
+			if req.RequirementURL == "" {
+				return nil, &PathError{Path: reqPath + ".requirement-url", Message: "required"}
+			}
+			ctrl.Requirements = append(ctrl.Requirements, req)
+		}
+		controls[id] = &ctrl
+	}
+	return controls, nil
+}
+
+// decodeAt unmarshals raw into v and converts decoding failures into PathErrors rooted at path.
+func decodeAt(raw json.RawMessage, path string, v any) error {
+	if len(raw) == 0 {
+		return &PathError{Path: path, Message: "required"}
+	}
+
+	err := json.Unmarshal(raw, v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		p := path
+		if typeErr.Field != "" {
+			p += "." + typeErr.Field
+		}
+		return &PathError{Path: p, Message: fmt.Sprintf("expected %s, got %s", jsonTypeName(typeErr.Type), typeErr.Value)}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &PathError{Path: path, Message: fmt.Sprintf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)}
+	}
+
+	return &PathError{Path: path, Message: err.Error()}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestParseJSON(t *testing.T) {
+	t.Run("should normalize relationship variants", func(t *testing.T) {
+		doc := `{
+  "unique-id": "a",
+  "name": "A",
+  "nodes": [
+    {"unique-id": "user", "node-type": "actor", "name": "User"},
+    {"unique-id": "sys", "node-type": "system", "name": "System"},
+    {"unique-id": "svc", "node-type": "service", "name": "Service",
+     "interfaces": [{"unique-id": "api", "protocol": "HTTPS", "port": 443},
+       {"unique-id": "rest", "definition-url": "https://example.com/rest.json", "config": {"port": 8443}}]}
+  ],
+  "relationships": [
+    {"unique-id": "r1", "relationship-type": {"interacts": {"actor": "user", "nodes": ["svc", "ghost"]}}},
+    {"unique-id": "r2", "relationship-type": {"composed-of": {"container": "sys", "nodes": ["svc"]}}},
+    {"unique-id": "r3", "relationship-type": {"deployed-in": {"container": "sys", "nodes": ["svc"]}}},
+    {"unique-id": "r4", "relationship-type": {"options": [{"description": "Only", "nodes": ["svc"], "relationships": ["r3"]}]}}
+  ]
+}`
+		arch, err := ParseJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(arch.Nodes) != 3 || arch.Nodes[2].Interfaces[0].Port != 443 {
+			t.Fatalf("nodes not decoded: %+v", arch.Nodes)
+		}
+		if rest := arch.Nodes[2].Interfaces[1]; rest.DefinitionURL != "https://example.com/rest.json" ||
+			rest.Config.(map[string]any)["port"] != float64(8443) {
+			t.Fatalf("interface definition not decoded: %+v", rest)
+		}
+		for _, n := range arch.Nodes {
+			if n.Arch != arch {
+				t.Errorf("node %s missing architecture back-pointer", n.UniqueID)
+			}
+		}
+
+		interacts := arch.Relationships[0].RelationshipType.Interacts
+		if interacts == nil || interacts.Actor != "user" || len(interacts.Nodes) != 2 {
+			t.Fatalf("expected typed interacts, got %#v", interacts)
+		}
+		comp := arch.Relationships[1].RelationshipType.ComposedOf
+		if comp == nil || comp.Container != "sys" || len(comp.Nodes) != 1 {
+			t.Fatalf("expected typed composed-of, got %#v", comp)
+		}
+		deployed := arch.Relationships[2].RelationshipType.DeployedIn
+		if deployed == nil || deployed.Container != "sys" || len(deployed.Nodes) != 1 {
+			t.Fatalf("expected typed deployed-in, got %#v", deployed)
+		}
+		if opts := arch.Relationships[3].RelationshipType.Options; len(opts) != 1 || opts[0].Relationships[0] != "r3" {
+			t.Fatalf("expected options decisions, got %#v", opts)
+		}
+
+		errs := domain.NoDanglingRelationships().Validate(arch)
+		if len(errs) != 1 || errs[0].NodeID != "r1" {
+			t.Fatalf("expected dangling interacts target to be reported, got %v", errs)
+		}
+	})
+
+	t.Run("should decode relationship and flow controls", func(t *testing.T) {
+		doc := `{
+  "relationships": [
+    {"unique-id": "r1", "controls": {"mtls": {"description": "Mutual TLS",
+      "requirements": [{"requirement-url": "https://example.com/mtls.json", "config": {"min-version": "1.3"}}]}},
+     "relationship-type": {"connects": {"source": {"node": "a"}, "destination": {"node": "b"}}}}
+  ],
+  "flows": [
+    {"unique-id": "f1", "controls": {"audit": {"description": "Audit trail",
+      "requirements": [{"requirement-url": "https://example.com/audit.json",
+        "config-url": "https://example.com/audit-config.json"}]}},
+     "transitions": [{"relationship-unique-id": "r1", "sequence-number": 1}]}
+  ]
+}`
+		arch, err := ParseJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := arch.Relationships[0].Controls["mtls"]; c == nil || len(c.Requirements) != 1 ||
+			c.Requirements[0].RequirementURL != "https://example.com/mtls.json" {
+			t.Errorf("relationship controls not decoded: %#v", arch.Relationships[0].Controls)
+		}
+		if c := arch.Flows[0].Controls["audit"]; c == nil || len(c.Requirements) != 1 ||
+			c.Requirements[0].ConfigURL != "https://example.com/audit-config.json" {
+			t.Errorf("flow controls not decoded: %#v", arch.Flows[0].Controls)
+		}
+	})
+
+	t.Run("should accept both metadata forms and keep them on output", func(t *testing.T) {
+		doc := `{
+  "metadata": [{"owner": "platform"}, {"tier": "tier-1"}],
+  "nodes": [
+    {"unique-id": "a", "node-type": "service", "metadata": {"tier": "tier-1"}},
+    {"unique-id": "b", "node-type": "service", "metadata": [{"tier": "tier-2", "owner": "x"}]}
+  ],
+  "relationships": [
+    {"unique-id": "r", "metadata": [{"latency": "low"}],
+     "relationship-type": {"connects": {"source": {"node": "a"}, "destination": {"node": "b"}}}}
+  ],
+  "flows": [{"unique-id": "f", "metadata": [{"kind": "sync"}], "transitions": []}]
+}`
+		arch, err := ParseJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if arch.Metadata["tier"] != "tier-1" || arch.Nodes[1].Metadata["owner"] != "x" ||
+			arch.Relationships[0].Metadata["latency"] != "low" || arch.Flows[0].Metadata["kind"] != "sync" {
+			t.Fatalf("metadata not decoded")
+		}
+		if arch.Nodes[0].MetadataLayout != nil || len(arch.Nodes[1].MetadataLayout) != 1 {
+			t.Errorf("unexpected node layouts %v %v", arch.Nodes[0].MetadataLayout, arch.Nodes[1].MetadataLayout)
+		}
+
+		arch.Nodes[1].AddMeta("runbook", "https://example.com/rb")
+		out, err := json.Marshal(arch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{
+			`"metadata":[{"owner":"platform"},{"tier":"tier-1"}]`,
+			`"metadata":{"tier":"tier-1"}`,
+			`"metadata":[{"owner":"x","tier":"tier-2"},{"runbook":"https://example.com/rb"}]`,
+			`"metadata":[{"latency":"low"}]`,
+			`"metadata":[{"kind":"sync"}]`,
+		} {
+			if !strings.Contains(string(out), want) {
+				t.Errorf("expected %s in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("should allow DSL helpers on parsed models", func(t *testing.T) {
+		arch, err := ParseJSON([]byte(`{"nodes": [{"unique-id": "n", "node-type": "service"}]}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		arch.AddMeta("k", "v")
+		arch.Nodes[0].AddMeta("k", "v").AddControl("c", "desc")
+		if arch.Nodes[0].Controls["c"] == nil {
+			t.Fatalf("expected control on parsed node")
+		}
+	})
+
+	t.Run("should report JSON paths for malformed input", func(t *testing.T) {
+		cases := []struct {
+			name string
+			doc  string
+			path string
+		}{
+			{"invalid syntax", `{"nodes": [`, "$"},
+			{"wrong field type", `{"nodes": [{"unique-id": 5, "node-type": "service"}]}`, "$.nodes[0].unique-id"},
+			{"missing node id", `{"nodes": [{"node-type": "service"}]}`, "$.nodes[0].unique-id"},
+			{
+				"wrong interface port",
+				`{"nodes": [{"unique-id": "n", "node-type": "service", "interfaces": [{"unique-id": "i", "port": "80"}]}]}`,
+				"$.nodes[0].interfaces[0].port",
+			},
+			{
+				"non-string node reference",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {"interacts": {"actor": "a", "nodes": ["b", 7]}}}]}`,
+				"$.relationships[0].relationship-type.interacts.nodes[1]",
+			},
+			{
+				"missing container",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {"composed-of": {"nodes": ["b"]}}}]}`,
+				"$.relationships[0].relationship-type.composed-of.container",
+			},
+			{
+				"misspelled composed-of key",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {"composed-of": {"container": "a", "nodes": ["b"], "node": ["c"]}}}]}`,
+				"$.relationships[0].relationship-type.composed-of.node",
+			},
+			{
+				"wrong option nodes type",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {"options": [{"description": "d", "nodes": "a"}]}}]}`,
+				"$.relationships[0].relationship-type.options[0].nodes",
+			},
+			{
+				"no relationship type",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {}}]}`,
+				"$.relationships[0].relationship-type",
+			},
+			{
+				"unknown relationship type",
+				`{"relationships": [{"unique-id": "r", "relationship-type": {"links": {}}}]}`,
+				"$.relationships[0].relationship-type.links",
+			},
+			{
+				"bad sequence number",
+				`{"flows": [{"unique-id": "f", "transitions": [{"relationship-unique-id": "r", "sequence-number": "1"}]}]}`,
+				"$.flows[0].transitions[0].sequence-number",
+			},
+			{
+				"metadata array element not an object",
+				`{"nodes": [{"unique-id": "n", "node-type": "service", "metadata": [{"tier": "tier-1"}, 5]}]}`,
+				"$.nodes[0].metadata",
+			},
+			{
+				"missing requirement url",
+				`{"controls": {"security": {"description": "d", "requirements": [{"config": {}}]}}}`,
+				"$.controls.security.requirements[0].requirement-url",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := ParseJSON([]byte(tc.doc))
+				var pathErr *PathError
+				if !errors.As(err, &pathErr) {
+					t.Fatalf("expected PathError, got %v", err)
+				}
+				if pathErr.Path != tc.path {
+					t.Errorf("expected path %s, got %s (%v)", tc.path, pathErr.Path, err)
+				}
+			})
+		}
+	})
+}
+
+func TestLoadJSONFile(t *testing.T) {
+	arch, err := LoadJSONFile("../../../../architectures/ecommerce-platform.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if arch.UniqueID != "ecommerce-platform-architecture" {
+		t.Errorf("unexpected id %s", arch.UniqueID)
+	}
+	errs := arch.Validate(domain.NoDanglingRelationships(), domain.AllFlowsHaveValidTransitions(), domain.NoUnusedNodes())
+	if len(errs) != 0 {
+		t.Errorf("expected reference architecture to validate, got %v", errs)
+	}
+}
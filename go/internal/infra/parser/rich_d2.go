@@ -28,13 +28,28 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 	flowPattern := regexp.MustCompile(`#\s*@calm:flow\s+id=(\S+)\s+name=(.+)$`)
 	flowDescPattern := regexp.MustCompile(`#\s*@calm:flow-description=(.+)$`)
 	flowMetaPattern := regexp.MustCompile(`#\s*@calm:flow-metadata=(.+)$`)
+	flowControlsPattern := regexp.MustCompile(`#\s*@calm:flow-controls=(.+)$`)
 	flowStepPattern := regexp.MustCompile(`#\s*@calm:flow-step\s+seq=(\d+)\s+rel=(\S+)\s+dir=(\S+)\s+desc=(.+)$`)
 	composedPattern := regexp.MustCompile(`#\s*@calm:composed-of\s+id=(\S+)\s+container=(\S+)\s+nodes=(.+)$`)
+	deployedPattern := regexp.MustCompile(
+		`#\s*@calm:deployed-in\s+id=(\S+)\s+container=(\S+)\s+nodes=(\S+)(?:\s+desc=(.*))?$`,
+	)
+	optionsPattern := regexp.MustCompile(`#\s*@calm:options\s+id=(\S+)\s+data=(.+)$`)
 	controlPattern := regexp.MustCompile(`#\s*@calm:control\s+id=(\S+)\s+data=(.+)$`)
+	relControlsPattern := regexp.MustCompile(`#\s*@calm:relationship-controls\s+id=(\S+)\s+data=(.+)$`)
 
 	nodeStartPattern := regexp.MustCompile(`^\s*(\S+):\s*(.+?)\s*\{`)
 	relPattern := regexp.MustCompile(`^\s*(\S+)\s*->\s*(\S+)`)
 
+	// Only blocks carrying a @calm:type are CALM nodes; this skips D2-only
+	// blocks such as the classes section.
+	flushNode := func() {
+		if currentNode != nil && currentNode.UniqueID != "" && currentNode.NodeType != "" {
+			arch.Nodes = append(arch.Nodes, currentNode)
+		}
+		currentNode = nil
+	}
+
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -81,7 +96,7 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 			currentFlow.Transitions = append(currentFlow.Transitions, domain.Transition{
 				SequenceNumber: seq,
 				RelationshipID: matches[2],
-				Direction:      matches[3],
+				Direction:      domain.FlowDirection(matches[3]),
 				Description:    unescapeD2String(matches[4]),
 			})
 			continue
@@ -95,10 +110,24 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 
 		// Parse flow metadata
 		if matches := flowMetaPattern.FindStringSubmatch(line); matches != nil && currentFlow != nil {
-			if currentFlow.Metadata == nil {
-				currentFlow.Metadata = make(map[string]any)
+			parseMetadataAnnotation(matches[1], &currentFlow.Metadata, &currentFlow.MetadataLayout)
+			continue
+		}
+
+		// Parse flow controls
+		if matches := flowControlsPattern.FindStringSubmatch(line); matches != nil && currentFlow != nil {
+			json.Unmarshal([]byte(matches[1]), &currentFlow.Controls)
+			continue
+		}
+
+		// Parse controls of composed-of and deployed-in relationships, which
+		// follow the relationship they belong to
+		if matches := relControlsPattern.FindStringSubmatch(line); matches != nil {
+			for _, rel := range arch.Relationships {
+				if rel.UniqueID == matches[1] {
+					json.Unmarshal([]byte(matches[2]), &rel.Controls)
+				}
 			}
-			json.Unmarshal([]byte(matches[1]), &currentFlow.Metadata)
 			continue
 		}
 
@@ -109,9 +138,28 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 			rel := &domain.Relationship{
 				UniqueID: matches[1],
 				RelationshipType: domain.RelationshipType{
-					ComposedOf: map[string]any{
-						"container": matches[2],
-						"nodes":     nodes,
+					ComposedOf: &domain.ComposedOf{
+						Container: matches[2],
+						Nodes:     nodes,
+					},
+				},
+			}
+			arch.Relationships = append(arch.Relationships, rel)
+			continue
+		}
+
+		// Parse deployed-in relationships
+		if matches := deployedPattern.FindStringSubmatch(line); matches != nil {
+			var nodes []string
+			json.Unmarshal([]byte(matches[3]), &nodes)
+			rel := &domain.Relationship{
+				UniqueID:    matches[1],
+				Description: unescapeD2String(matches[4]),
+				Metadata:    make(map[string]any),
+				RelationshipType: domain.RelationshipType{
+					DeployedIn: &domain.DeployedIn{
+						Container: matches[2],
+						Nodes:     nodes,
 					},
 				},
 			}
@@ -119,6 +167,17 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 			continue
 		}
 
+		// Parse options (decision point) relationships
+		if matches := optionsPattern.FindStringSubmatch(line); matches != nil {
+			rel := &domain.Relationship{UniqueID: matches[1]}
+			json.Unmarshal([]byte(matches[2]), rel)
+			if rel.Metadata == nil {
+				rel.Metadata = make(map[string]any)
+			}
+			arch.Relationships = append(arch.Relationships, rel)
+			continue
+		}
+
 		// Parse global controls
 		if matches := controlPattern.FindStringSubmatch(line); matches != nil {
 			var ctrl domain.Control
@@ -130,9 +189,7 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 		// Parse node start
 		if matches := nodeStartPattern.FindStringSubmatch(line); matches != nil {
 			// End previous node if any
-			if currentNode != nil && currentNode.UniqueID != "" {
-				arch.Nodes = append(arch.Nodes, currentNode)
-			}
+			flushNode()
 			currentNode = &domain.Node{
 				Arch:     arch,
 				UniqueID: matches[1],
@@ -147,16 +204,13 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 		// Parse relationship start
 		if matches := relPattern.FindStringSubmatch(line); matches != nil {
 			// End previous node if any
-			if currentNode != nil && currentNode.UniqueID != "" {
-				arch.Nodes = append(arch.Nodes, currentNode)
-				currentNode = nil
-			}
+			flushNode()
 			currentRel = &domain.Relationship{
 				Metadata: make(map[string]any),
 				RelationshipType: domain.RelationshipType{
 					Connects: &domain.Connects{
-						Source:      domain.NodeInterface{Node: matches[1]},
-						Destination: domain.NodeInterface{Node: matches[2]},
+						Source:      domain.NodeInterface{Node: d2NodeRef(matches[1])},
+						Destination: domain.NodeInterface{Node: d2NodeRef(matches[2])},
 					},
 				},
 			}
@@ -165,28 +219,39 @@ func ParseRichD2(content string) (*domain.Architecture, error) {
 
 		// End of block
 		if strings.TrimSpace(line) == "}" {
-			if currentNode != nil && currentNode.UniqueID != "" {
-				arch.Nodes = append(arch.Nodes, currentNode)
-				currentNode = nil
-			}
+			flushNode()
+			// Edges without a @calm:id (e.g. deployed-in visuals) are presentation only.
 			if currentRel != nil && currentRel.UniqueID != "" {
-				arch.Relationships = append(arch.Relationships, currentRel)
-				currentRel = nil
+				arch.Relationships = addRelationship(arch.Relationships, currentRel)
 			}
+			currentRel = nil
 		}
 	}
 
 	// Add last node/rel if pending
-	if currentNode != nil && currentNode.UniqueID != "" {
-		arch.Nodes = append(arch.Nodes, currentNode)
-	}
+	flushNode()
 	if currentRel != nil && currentRel.UniqueID != "" {
-		arch.Relationships = append(arch.Relationships, currentRel)
+		arch.Relationships = addRelationship(arch.Relationships, currentRel)
 	}
 
 	return arch, nil
 }
 
+// addRelationship appends rel to rels. The renderer writes an interacts
+// relationship as one edge per node, all with the same @calm:id; those edges
+// are merged back into a single relationship.
+func addRelationship(rels []*domain.Relationship, rel *domain.Relationship) []*domain.Relationship {
+	if in := rel.RelationshipType.Interacts; in != nil {
+		for _, prev := range rels {
+			if prev.UniqueID == rel.UniqueID && prev.RelationshipType.Interacts != nil {
+				prev.RelationshipType.Interacts.Nodes = append(prev.RelationshipType.Interacts.Nodes, in.Nodes...)
+				return rels
+			}
+		}
+	}
+	return append(rels, rel)
+}
+
 // RichD2Parser implements the domain.Parser port for Rich D2 inputs.
 type RichD2Parser struct{}
 
@@ -208,11 +273,13 @@ func parseNodeAnnotation(node *domain.Node, key, value string) {
 	case "description":
 		node.Description = unescapeD2String(value)
 	case "metadata":
-		json.Unmarshal([]byte(value), &node.Metadata)
+		parseMetadataAnnotation(value, &node.Metadata, &node.MetadataLayout)
 	case "interfaces":
 		json.Unmarshal([]byte(value), &node.Interfaces)
 	case "controls":
 		json.Unmarshal([]byte(value), &node.Controls)
+	case "details":
+		json.Unmarshal([]byte(value), &node.Details)
 	}
 }
 
@@ -243,25 +310,53 @@ func parseRelAnnotation(rel *domain.Relationship, key, value string) {
 			rel.RelationshipType.Connects.Destination.Interfaces = intfs
 		}
 	case "metadata":
-		json.Unmarshal([]byte(value), &rel.Metadata)
+		parseMetadataAnnotation(value, &rel.Metadata, &rel.MetadataLayout)
+	case "controls":
+		json.Unmarshal([]byte(value), &rel.Controls)
 	case "type":
 		if value == "interacts" {
-			// Convert to interacts type
+			// Convert to interacts type, keeping the edge target as the interacted node
+			interacts := &domain.Interacts{}
+			if c := rel.RelationshipType.Connects; c != nil {
+				interacts.Nodes = []string{c.Destination.Node}
+			}
+			rel.RelationshipType.Interacts = interacts
 			rel.RelationshipType.Connects = nil
 		}
 	case "actor":
 		if rel.RelationshipType.Interacts == nil {
-			rel.RelationshipType.Interacts = make(map[string]any)
+			rel.RelationshipType.Interacts = &domain.Interacts{}
 		}
-		rel.RelationshipType.Interacts["actor"] = value
+		rel.RelationshipType.Interacts.Actor = value
 	}
 }
 
 func parseFlowAnnotation(flow *domain.Flow, key, value string) {
 	switch key {
 	case "metadata":
-		json.Unmarshal([]byte(value), &flow.Metadata)
+		parseMetadataAnnotation(value, &flow.Metadata, &flow.MetadataLayout)
+	}
+}
+
+// parseMetadataAnnotation decodes a metadata annotation in either CALM form
+// (object or array of objects) and keeps its layout. Malformed values are
+// ignored like other annotations.
+func parseMetadataAnnotation(value string, meta *domain.Metadata, layout *domain.MetadataLayout) {
+	m, l, err := domain.DecodeMetadata([]byte(value))
+	if err != nil || m == nil {
+		return
+	}
+	*meta, *layout = m, l
+}
+
+// d2NodeRef converts an edge endpoint such as "sys.svc:" into the CALM node ID "svc",
+// dropping the container path and any trailing label separator.
+func d2NodeRef(ref string) string {
+	ref = strings.TrimSuffix(ref, ":")
+	if i := strings.LastIndex(ref, "."); i >= 0 {
+		return ref[i+1:]
 	}
+	return ref
 }
 
 func unescapeD2String(s string) string {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+	"github.com/sokoide/advent-of-calm-2025/internal/infra/render"
 )
 
 func TestParseRichD2(t *testing.T) {
@@ -97,8 +98,10 @@ customer -> api-gateway {
 		rel := arch.Relationships[0]
 		if rel.RelationshipType.Interacts == nil {
 			t.Errorf("expected interacts relationship type, got nil")
-		} else if rel.RelationshipType.Interacts["actor"] != "customer" {
-			t.Errorf("expected actor customer, got %v", rel.RelationshipType.Interacts["actor"])
+		} else if rel.RelationshipType.Interacts.Actor != "customer" {
+			t.Errorf("expected actor customer, got %v", rel.RelationshipType.Interacts.Actor)
+		} else if nodes := rel.RelationshipType.Interacts.Nodes; len(nodes) != 1 || nodes[0] != "api-gateway" {
+			t.Errorf("expected interacts nodes [api-gateway], got %v", nodes)
 		}
 
 		if rel.Encrypted == nil || !*rel.Encrypted {
@@ -181,8 +184,203 @@ order-service -> inventory-db {
 		if comp == nil {
 			t.Fatal("expected composed-of type")
 		}
-		if comp["container"] != "cont1" {
-			t.Errorf("expected container cont1, got %v", comp["container"])
+		if comp.Container != "cont1" {
+			t.Errorf("expected container cont1, got %v", comp.Container)
+		}
+	})
+
+	t.Run("should parse deployed-in relationships", func(t *testing.T) {
+		d2 := `
+# @calm:deployed-in id=svc-deployed-in-k8s container=k8s nodes=["svc"] desc=Runs on the cluster
+svc -> k8s: deployed-in {
+  style.stroke-dash: 5
+}
+`
+		arch, err := ParseRichD2(d2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(arch.Relationships) != 1 {
+			t.Fatalf("expected 1 relationship, got %d", len(arch.Relationships))
+		}
+		rel := arch.Relationships[0]
+		deployed := rel.RelationshipType.DeployedIn
+		if deployed == nil || deployed.Container != "k8s" || len(deployed.Nodes) != 1 || deployed.Nodes[0] != "svc" {
+			t.Fatalf("unexpected deployed-in %#v", deployed)
+		}
+		if rel.Description != "Runs on the cluster" {
+			t.Errorf("expected description, got %q", rel.Description)
+		}
+	})
+
+	t.Run("should round-trip relationship variants through the renderer", func(t *testing.T) {
+		src := domain.NewArchitecture("rt", "Round Trip", "desc")
+		user := src.DefineNode("user", domain.Actor, "User", "desc")
+		cluster := src.DefineNode("k8s", domain.System, "Cluster", "desc")
+		sys := src.DefineNode("sys", domain.System, "System", "desc")
+		svc := src.DefineNode("svc", domain.Service, "Service", "desc")
+		db := src.DefineNode("db", domain.Database, "DB", "desc")
+		src.ComposedOf("sys-comp", "composition", sys.UniqueID, []string{svc.UniqueID, db.UniqueID})
+		src.Interacts("user-svc", "uses", user.UniqueID, svc.UniqueID)
+		svc.ConnectTo(db, "reads").Protocol("JDBC")
+		svc.DeployedIn(cluster)
+		db.DeployedIn(cluster)
+		src.DefineOptions("db-choice", "Database engine").
+			Option("Postgres", []string{db.UniqueID}, []string{"svc-connects-db"})
+
+		out, err := render.RichD2Renderer{}.Render(src)
+		if err != nil {
+			t.Fatalf("unexpected render error: %v", err)
+		}
+		arch, err := ParseRichD2(out)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		if len(arch.Nodes) != len(src.Nodes) {
+			t.Fatalf("expected %d nodes, got %d:\n%s", len(src.Nodes), len(arch.Nodes), out)
+		}
+		rels := make(map[string]*domain.Relationship)
+		for _, r := range arch.Relationships {
+			rels[r.UniqueID] = r
+		}
+		if len(rels) != len(src.Relationships) {
+			t.Fatalf("expected %d relationships, got %d:\n%s", len(src.Relationships), len(rels), out)
+		}
+
+		c := rels["svc-connects-db"].RelationshipType.Connects
+		if c == nil || c.Source.Node != "svc" || c.Destination.Node != "db" {
+			t.Errorf("connects endpoints not preserved: %#v", c)
+		}
+		i := rels["user-svc"].RelationshipType.Interacts
+		if i == nil || i.Actor != "user" || len(i.Nodes) != 1 || i.Nodes[0] != "svc" {
+			t.Errorf("interacts not preserved: %#v", i)
+		}
+		d := rels["db-deployed-in-k8s"].RelationshipType.DeployedIn
+		if d == nil || d.Container != "k8s" || d.Nodes[0] != "db" {
+			t.Errorf("deployed-in not preserved: %#v", d)
+		}
+		o := rels["db-choice"].RelationshipType.Options
+		if len(o) != 1 || o[0].Description != "Postgres" || o[0].Relationships[0] != "svc-connects-db" {
+			t.Errorf("options not preserved: %#v", o)
+		}
+		if errs := arch.Validate(domain.NoDanglingRelationships(), domain.NoUnusedNodes()); len(errs) != 0 {
+			t.Errorf("expected parsed architecture to validate, got %v", errs)
+		}
+	})
+
+	t.Run("should round-trip interacts with several nodes", func(t *testing.T) {
+		src := domain.NewArchitecture("rt", "Round Trip", "desc")
+		user := src.DefineNode("user", domain.Actor, "User", "desc")
+		src.DefineNode("web", domain.WebClient, "Web", "desc")
+		src.DefineNode("api", domain.Service, "API", "desc")
+		rel := src.Interacts("user-uses", "uses", user.UniqueID, "web")
+		rel.RelationshipType.Interacts.Nodes = append(rel.RelationshipType.Interacts.Nodes, "api")
+
+		out, err := render.RichD2Renderer{}.Render(src)
+		if err != nil {
+			t.Fatalf("unexpected render error: %v", err)
+		}
+		arch, err := ParseRichD2(out)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if err := arch.Err(); err != nil {
+			t.Errorf("unexpected builder errors: %v", err)
+		}
+		if len(arch.Relationships) != 1 {
+			t.Fatalf("expected 1 relationship, got %d:\n%s", len(arch.Relationships), out)
+		}
+		i := arch.Relationships[0].RelationshipType.Interacts
+		if i == nil || i.Actor != "user" || len(i.Nodes) != 2 || i.Nodes[0] != "web" || i.Nodes[1] != "api" {
+			t.Errorf("interacts not preserved: %#v", i)
+		}
+	})
+
+	t.Run("should round-trip relationship and flow controls", func(t *testing.T) {
+		mtls := domain.NewRequirement("https://example.com/mtls.json", map[string]any{"min-version": "1.3"})
+		audit := domain.NewRequirementURL("https://example.com/audit.json", "https://example.com/audit-config.json")
+		src := domain.NewArchitecture("ctl", "Controls", "desc")
+		user := src.DefineNode("user", domain.Actor, "User", "desc")
+		cluster := src.DefineNode("k8s", domain.System, "Cluster", "desc")
+		svc := src.DefineNode("svc", domain.Service, "Service", "desc")
+		db := src.DefineNode("db", domain.Database, "DB", "desc")
+		rel := svc.ConnectTo(db, "reads").Control("mtls", "Mutual TLS", mtls)
+		src.Interacts("user-svc", "uses", user.UniqueID, svc.UniqueID).AddControl("mtls", "Mutual TLS", mtls)
+		svc.DeployedIn(cluster).AddControl("isolation", "Namespace isolation")
+		src.DefineFlow("checkout", "Checkout", "desc").Step(rel.GetID(), "read").Control("audit", "Audit trail", audit)
+
+		out, err := render.RichD2Renderer{}.Render(src)
+		if err != nil {
+			t.Fatalf("unexpected render error: %v", err)
+		}
+		arch, err := ParseRichD2(out)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		rels := make(map[string]*domain.Relationship)
+		for _, r := range arch.Relationships {
+			rels[r.UniqueID] = r
+		}
+		for _, id := range []string{"svc-connects-db", "user-svc"} {
+			c := rels[id].Controls["mtls"]
+			if c == nil || c.Description != "Mutual TLS" || len(c.Requirements) != 1 ||
+				c.Requirements[0].RequirementURL != "https://example.com/mtls.json" {
+				t.Errorf("%s controls not preserved: %#v\n%s", id, rels[id].Controls, out)
+			}
+		}
+		if c := rels["svc-deployed-in-k8s"].Controls["isolation"]; c == nil || c.Description != "Namespace isolation" {
+			t.Errorf("deployed-in controls not preserved: %#v", rels["svc-deployed-in-k8s"].Controls)
+		}
+		if len(arch.Flows) != 1 {
+			t.Fatalf("expected 1 flow, got %d", len(arch.Flows))
+		}
+		c := arch.Flows[0].Controls["audit"]
+		if c == nil || len(c.Requirements) != 1 ||
+			c.Requirements[0].ConfigURL != "https://example.com/audit-config.json" {
+			t.Errorf("flow controls not preserved: %#v", arch.Flows[0].Controls)
+		}
+	})
+
+	t.Run("should keep the array form of metadata", func(t *testing.T) {
+		src, err := ParseJSON([]byte(`{
+  "nodes": [{"unique-id": "svc", "node-type": "service", "name": "Svc",
+             "metadata": [{"tier": "tier-1"}, {"owner": "a"}]},
+            {"unique-id": "db", "node-type": "database", "name": "DB", "metadata": {"tier": "tier-2"}}],
+  "relationships": [{"unique-id": "r", "metadata": [{"latency": "low"}],
+    "relationship-type": {"connects": {"source": {"node": "svc"}, "destination": {"node": "db"}}}}],
+  "flows": [{"unique-id": "f", "name": "F", "metadata": [{"kind": "sync"}],
+    "transitions": [{"relationship-unique-id": "r", "sequence-number": 1}]}]
+}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out, err := render.RichD2Renderer{}.Render(src)
+		if err != nil {
+			t.Fatalf("unexpected render error: %v", err)
+		}
+		arch, err := ParseRichD2(out)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		nodes := make(map[string]*domain.Node)
+		for _, n := range arch.Nodes {
+			nodes[n.UniqueID] = n
+		}
+		if n := nodes["svc"]; n.Metadata["owner"] != "a" || len(n.MetadataLayout) != 2 {
+			t.Errorf("svc metadata not preserved: %v %v\n%s", n.Metadata, n.MetadataLayout, out)
+		}
+		if n := nodes["db"]; n.Metadata["tier"] != "tier-2" || n.MetadataLayout != nil {
+			t.Errorf("db metadata not preserved: %v %v", n.Metadata, n.MetadataLayout)
+		}
+		if r := arch.Relationships[0]; r.Metadata["latency"] != "low" || len(r.MetadataLayout) != 1 {
+			t.Errorf("relationship metadata not preserved: %v %v", r.Metadata, r.MetadataLayout)
+		}
+		if f := arch.Flows[0]; f.Metadata["kind"] != "sync" || len(f.MetadataLayout) != 1 {
+			t.Errorf("flow metadata not preserved: %v %v", f.Metadata, f.MetadataLayout)
 		}
 	})
 
@@ -204,4 +402,25 @@ order-service -> inventory-db {
 			t.Errorf("expected description, got %s", ctrl.Description)
 		}
 	})
+
+	t.Run("should round-trip node details", func(t *testing.T) {
+		src := domain.NewArchitecture("platform", "Platform", "desc")
+		src.DefineNode("payments", domain.System, "Payments", "desc",
+			domain.WithDetails("payments-detail", "https://example.com/patterns/payments.json"))
+
+		out, err := render.RichD2Renderer{}.Render(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		arch, err := ParseRichD2(out)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		d := arch.Nodes[0].Details
+		if d == nil || d.DetailedArchitecture != "payments-detail" ||
+			d.RequiredPattern != "https://example.com/patterns/payments.json" {
+			t.Errorf("details not preserved: %#v", d)
+		}
+	})
 }
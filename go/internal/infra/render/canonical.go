@@ -0,0 +1,15 @@
+package render
+
+import "github.com/sokoide/advent-of-calm-2025/internal/domain"
+
+// CanonicalRenderer renders the canonical form of an architecture (see
+// domain.Canonicalize), so equal models produce byte-identical output
+// regardless of declaration order.
+type CanonicalRenderer struct {
+	Renderer domain.Renderer
+}
+
+// Render canonicalizes a copy of the architecture and renders it.
+func (r CanonicalRenderer) Render(a *domain.Architecture) (string, error) {
+	return r.Renderer.Render(domain.Canonicalize(a))
+}
@@ -2,6 +2,7 @@ package render
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"strings"
@@ -10,10 +11,14 @@ import (
 )
 
 // D2Renderer renders CALM architectures into D2 source.
-type D2Renderer struct{}
+type D2Renderer struct {
+	// DetailsLink maps a node's detailed-architecture reference to the URL the
+	// node links to. When nil, the reference is used as is.
+	DetailsLink func(ref string) string
+}
 
 // Render generates D2 diagram source from the architecture.
-func (D2Renderer) Render(a *domain.Architecture) (string, error) {
+func (r D2Renderer) Render(a *domain.Architecture) (string, error) {
 	var sb strings.Builder
 
 	// Header
@@ -24,90 +29,48 @@ func (D2Renderer) Render(a *domain.Architecture) (string, error) {
 	sb.WriteString("direction: right\n\n")
 
 	// Style definitions
-	sb.WriteString("classes: {\n")
-	sb.WriteString("  actor: {\n    shape: person\n    style.fill: \"#e1f5fe\"\n  }\n")
-	sb.WriteString("  service: {\n    shape: rectangle\n    style.fill: \"#e8f5e9\"\n    style.border-radius: 8\n  }\n")
-	sb.WriteString("  database: {\n    shape: cylinder\n    style.fill: \"#fff3e0\"\n  }\n")
-	sb.WriteString("  queue: {\n    shape: queue\n    style.fill: \"#f3e5f5\"\n  }\n")
-	sb.WriteString("  system: {\n    shape: rectangle\n    style.fill: \"#fafafa\"\n    style.stroke-dash: 3\n  }\n")
-	sb.WriteString("}\n\n")
-
-	// Track composed nodes
-	nodeToParent := make(map[string]string)       // node -> direct parent
-	parentToChildren := make(map[string][]string) // parent -> direct children
-	nodeByID := make(map[string]*domain.Node)
-	for _, node := range a.Nodes {
-		nodeByID[node.UniqueID] = node
-	}
+	writeD2Classes(&sb, a)
 
-	for _, rel := range a.Relationships {
-		if rel.RelationshipType.ComposedOf != nil {
-			container, _ := rel.RelationshipType.ComposedOf["container"].(string)
-			if nodes, ok := rel.RelationshipType.ComposedOf["nodes"].([]string); ok {
-				for _, n := range nodes {
-					if _, exists := nodeToParent[n]; exists {
-						continue
-					}
-					nodeToParent[n] = container
-					parentToChildren[container] = append(parentToChildren[container], n)
-				}
-			}
-		}
-	}
+	g := domain.NewGraph(a)
 
 	// Recursive function to write node and its children
 	var writeNodeRecursive func(id string, indent string)
 	writeNodeRecursive = func(nodeID string, indent string) {
-		targetNode := nodeByID[nodeID]
-		if targetNode == nil {
+		targetNode, ok := g.Node(nodeID)
+		if !ok {
 			return
 		}
 
 		id := sanitizeID(targetNode.UniqueID)
-		children := parentToChildren[nodeID]
+		children := g.Children(nodeID)
 
 		if len(children) > 0 {
 			// It's a container
 			sb.WriteString(fmt.Sprintf("%s%s: %s {\n", indent, id, targetNode.Name))
-			sb.WriteString(fmt.Sprintf("%s  class: %s\n", indent, strings.ToLower(string(targetNode.NodeType))))
+			sb.WriteString(fmt.Sprintf("%s  class: %s\n", indent, d2ClassName(targetNode.NodeType)))
+			writeDetailsLink(&sb, targetNode, indent, r.DetailsLink)
 			for _, childID := range children {
-				// Verify if this node is still the valid parent
-				if currentParent, ok := nodeToParent[childID]; ok && currentParent == nodeID {
-					writeNodeRecursive(childID, indent+"  ")
-				}
+				writeNodeRecursive(childID, indent+"  ")
 			}
 			sb.WriteString(indent + "}\n")
 		} else {
 			// It's a leaf node
-			writeNode(&sb, targetNode, indent)
+			writeNode(&sb, targetNode, indent, r.DetailsLink)
 		}
 	}
 
 	// 1. Generate top-level nodes (those without parents)
-	for _, node := range a.Nodes {
-		if _, hasParent := nodeToParent[node.UniqueID]; !hasParent {
-			writeNodeRecursive(node.UniqueID, "")
-		}
+	for _, id := range g.Roots() {
+		writeNodeRecursive(id, "")
 	}
 
-	// Helper to get full D2 path for a node (e.g., parent.child.node)
-	getFullD2Path := func(nodeID string) string {
-		segments := []string{sanitizeID(nodeID)}
-		seen := make(map[string]bool)
-		for {
-			parent, ok := nodeToParent[nodeID]
-			if !ok || seen[parent] {
-				break
-			}
-			seen[parent] = true
-			segments = append([]string{sanitizeID(parent)}, segments...)
-			nodeID = parent
-		}
-		return strings.Join(segments, ".")
-	}
+	// Full D2 path for a node (e.g., parent.child.node)
+	getFullD2Path := func(nodeID string) string { return d2Path(g, nodeID) }
 
 	sb.WriteString("\n# Relationships\n")
 
+	optional := optionalRelationships(a)
+
 	// Generate relationships
 	for _, rel := range a.Relationships {
 		if rel.RelationshipType.Connects != nil {
@@ -128,22 +91,33 @@ func (D2Renderer) Render(a *domain.Architecture) (string, error) {
 				label += "(" + rel.DataClassification + ")"
 			}
 
+			edge := fmt.Sprintf("%s -> %s", srcPath, dstPath)
 			if label != "" {
-				sb.WriteString(fmt.Sprintf("%s -> %s: %s\n", srcPath, dstPath, label))
+				edge += ": " + label
+			}
+			if optional[rel.UniqueID] {
+				sb.WriteString(edge + " {\n  style.stroke-dash: 5\n}\n")
 			} else {
-				sb.WriteString(fmt.Sprintf("%s -> %s\n", srcPath, dstPath))
+				sb.WriteString(edge + "\n")
 			}
 		}
 
-		if rel.RelationshipType.Interacts != nil {
-			actor, _ := rel.RelationshipType.Interacts["actor"].(string)
-			if nodes, ok := rel.RelationshipType.Interacts["nodes"].([]string); ok {
-				for _, n := range nodes {
-					dstPath := getFullD2Path(n)
-					sb.WriteString(fmt.Sprintf("%s -> %s\n", sanitizeID(actor), dstPath))
-				}
+		if interacts := rel.RelationshipType.Interacts; interacts != nil {
+			for _, n := range interacts.Nodes {
+				dstPath := getFullD2Path(n)
+				sb.WriteString(fmt.Sprintf("%s -> %s\n", sanitizeID(interacts.Actor), dstPath))
+			}
+		}
+
+		if deployed := rel.RelationshipType.DeployedIn; deployed != nil {
+			for _, n := range deployed.Nodes {
+				writeDeployedInEdge(&sb, getFullD2Path(n), getFullD2Path(deployed.Container))
 			}
 		}
+
+		if rel.RelationshipType.Options != nil {
+			writeOptionsDecision(&sb, rel, getFullD2Path)
+		}
 	}
 
 	return sb.String(), nil
@@ -182,9 +156,9 @@ func (r D2Renderer) RenderSVG(a *domain.Architecture) (string, error) {
 	return string(output), nil
 }
 
-func writeNode(sb *strings.Builder, node *domain.Node, indent string) {
+func writeNode(sb *strings.Builder, node *domain.Node, indent string, detailsLink func(string) string) {
 	id := sanitizeID(node.UniqueID)
-	className := strings.ToLower(string(node.NodeType))
+	className := d2ClassName(node.NodeType)
 
 	// Node with label
 	sb.WriteString(fmt.Sprintf("%s%s: %s {\n", indent, id, node.Name))
@@ -194,10 +168,84 @@ func writeNode(sb *strings.Builder, node *domain.Node, indent string) {
 	if node.Owner != "" {
 		sb.WriteString(fmt.Sprintf("%s  tooltip: \"Owner: %s\"\n", indent, node.Owner))
 	}
+	writeDetailsLink(sb, node, indent, detailsLink)
 
 	sb.WriteString(indent + "}\n")
 }
 
+// writeDetailsLink makes a node with a detailed architecture clickable, so the
+// rendered SVG can drill down into the child diagram.
+func writeDetailsLink(sb *strings.Builder, node *domain.Node, indent string, detailsLink func(string) string) {
+	if node.Details == nil || node.Details.DetailedArchitecture == "" {
+		return
+	}
+	target := node.Details.DetailedArchitecture
+	if detailsLink != nil {
+		target = detailsLink(target)
+	}
+	sb.WriteString(fmt.Sprintf("%s  link: %q\n", indent, target))
+}
+
+// QueryLink returns a DetailsLink that appends the query-escaped reference to
+// prefix, e.g. QueryLink("/drill?ref=") for Studio's drill-down page.
+func QueryLink(prefix string) func(ref string) string {
+	return func(ref string) string { return prefix + url.QueryEscape(ref) }
+}
+
+// writeDeployedInEdge draws a deployed-in relationship as a dashed edge so it is
+// visually distinct from runtime connections.
+func writeDeployedInEdge(sb *strings.Builder, nodePath, containerPath string) {
+	sb.WriteString(fmt.Sprintf("%s -> %s: deployed-in {\n", nodePath, containerPath))
+	sb.WriteString("  style.stroke-dash: 5\n")
+	sb.WriteString("}\n")
+}
+
+// optionalRelationships returns the IDs of relationships that belong to an
+// alternative of an options relationship.
+func optionalRelationships(a *domain.Architecture) map[string]bool {
+	optional := make(map[string]bool)
+	for _, rel := range a.Relationships {
+		for _, d := range rel.RelationshipType.Options {
+			for _, id := range d.Relationships {
+				optional[id] = true
+			}
+		}
+	}
+	return optional
+}
+
+// writeOptionsDecision draws an options relationship as a diamond decision
+// point with one dashed, labelled branch per alternative node.
+func writeOptionsDecision(sb *strings.Builder, rel *domain.Relationship, path func(string) string) {
+	id := sanitizeID(rel.UniqueID)
+	label := rel.Description
+	if label == "" {
+		label = rel.UniqueID
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s {\n", id, label))
+	sb.WriteString("  shape: diamond\n")
+	sb.WriteString("  style.stroke-dash: 3\n")
+	sb.WriteString("}\n")
+	for _, d := range rel.RelationshipType.Options {
+		for _, n := range d.Nodes {
+			sb.WriteString(fmt.Sprintf("%s -> %s: %s {\n", id, path(n), d.Description))
+			sb.WriteString("  style.stroke-dash: 5\n")
+			sb.WriteString("}\n")
+		}
+	}
+}
+
+// d2Path returns the fully qualified D2 path of a node, prefixed by its
+// composed-of containers (e.g. "system.service").
+func d2Path(g *domain.Graph, nodeID string) string {
+	ancestors := g.Ancestors(nodeID)
+	segments := make([]string, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		segments = append(segments, sanitizeID(ancestors[i]))
+	}
+	return strings.Join(append(segments, sanitizeID(nodeID)), ".")
+}
+
 func sanitizeID(id string) string {
 	// D2 IDs can contain hyphens, but we need to escape special characters
 	return strings.ReplaceAll(id, " ", "-")
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// D2Class describes how nodes of one node type are drawn in D2.
+type D2Class struct {
+	Shape string
+	Fill  string
+	// Styles holds additional D2 style lines, e.g. "style.stroke-dash: 3".
+	Styles []string
+}
+
+// defaultD2Class is used for node types without a registered class.
+var defaultD2Class = D2Class{Shape: "rectangle", Fill: "#ffffff"}
+
+var (
+	d2ClassesMu  sync.RWMutex
+	d2ClassOrder = []domain.NodeType{
+		domain.Actor, domain.Service, domain.Database, domain.Queue, domain.System,
+		domain.WebClient, domain.Ecosystem, domain.Network, domain.LDAP, domain.DataAsset,
+	}
+	d2Classes = map[domain.NodeType]D2Class{
+		domain.Actor:     {Shape: "person", Fill: "#e1f5fe"},
+		domain.Service:   {Shape: "rectangle", Fill: "#e8f5e9", Styles: []string{"style.border-radius: 8"}},
+		domain.Database:  {Shape: "cylinder", Fill: "#fff3e0"},
+		domain.Queue:     {Shape: "queue", Fill: "#f3e5f5"},
+		domain.System:    {Shape: "rectangle", Fill: "#fafafa", Styles: []string{"style.stroke-dash: 3"}},
+		domain.WebClient: {Shape: "page", Fill: "#e3f2fd"},
+		domain.Ecosystem: {Shape: "cloud", Fill: "#f1f8e9"},
+		domain.Network:   {Shape: "hexagon", Fill: "#eceff1"},
+		domain.LDAP:      {Shape: "stored_data", Fill: "#fce4ec"},
+		domain.DataAsset: {Shape: "document", Fill: "#fffde7"},
+	}
+)
+
+// RegisterD2Class sets the D2 class used for a node type, typically alongside
+// domain.RegisterNodeType for custom company node types.
+func RegisterD2Class(t domain.NodeType, class D2Class) {
+	d2ClassesMu.Lock()
+	defer d2ClassesMu.Unlock()
+	if _, ok := d2Classes[t]; !ok {
+		d2ClassOrder = append(d2ClassOrder, t)
+	}
+	d2Classes[t] = class
+}
+
+// d2ClassName returns the D2 class name for a node type.
+func d2ClassName(t domain.NodeType) string {
+	return strings.ToLower(string(t))
+}
+
+// writeD2Classes writes the classes block: every registered class plus a
+// default-styled class for any other node type used in the architecture, so
+// that each `class:` reference resolves.
+func writeD2Classes(sb *strings.Builder, a *domain.Architecture) {
+	d2ClassesMu.RLock()
+	defer d2ClassesMu.RUnlock()
+
+	types := append([]domain.NodeType(nil), d2ClassOrder...)
+	seen := make(map[string]bool, len(types))
+	for _, t := range types {
+		seen[d2ClassName(t)] = true
+	}
+	for _, n := range a.Nodes {
+		if name := d2ClassName(n.NodeType); name != "" && !seen[name] {
+			seen[name] = true
+			types = append(types, n.NodeType)
+		}
+	}
+
+	sb.WriteString("classes: {\n")
+	for _, t := range types {
+		class, ok := d2Classes[t]
+		if !ok {
+			class = defaultD2Class
+		}
+		sb.WriteString(fmt.Sprintf("  %s: {\n", d2ClassName(t)))
+		sb.WriteString(fmt.Sprintf("    shape: %s\n", class.Shape))
+		sb.WriteString(fmt.Sprintf("    style.fill: %q\n", class.Fill))
+		for _, style := range class.Styles {
+			sb.WriteString("    " + style + "\n")
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n\n")
+}
@@ -53,3 +53,124 @@ func TestD2Renderer_ComposedNodes(t *testing.T) {
 		t.Errorf("expected svc1 inside container in output")
 	}
 }
+
+func TestD2Renderer_DeployedIn(t *testing.T) {
+	arch := domain.NewArchitecture("test-arch", "Test", "Desc")
+	cluster := arch.DefineNode("k8s", domain.System, "Cluster", "desc")
+	sys := arch.DefineNode("sys1", domain.System, "System 1", "desc")
+	svc := arch.DefineNode("svc1", domain.Service, "Service 1", "desc")
+	arch.ComposedOf("comp1", "composed", sys.UniqueID, []string{svc.UniqueID})
+	svc.DeployedIn(cluster)
+
+	output, err := D2Renderer{}.Render(arch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(output, "sys1.svc1 -> k8s: deployed-in {\n  style.stroke-dash: 5\n}") {
+		t.Errorf("expected dashed deployed-in edge in output:\n%s", output)
+	}
+}
+
+func TestD2Renderer_DetailsLink(t *testing.T) {
+	arch := domain.NewArchitecture("test-arch", "Test", "Desc")
+	arch.DefineNode("payments", domain.System, "Payments", "desc", domain.WithDetails("payments-detail", ""))
+	arch.DefineNode("orders", domain.Service, "Orders", "desc")
+
+	t.Run("should link to the reference by default", func(t *testing.T) {
+		output, err := D2Renderer{}.Render(arch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Count(output, "link:") != 1 || !strings.Contains(output, `link: "payments-detail"`) {
+			t.Errorf("expected one details link in output:\n%s", output)
+		}
+	})
+
+	t.Run("should map the reference through DetailsLink", func(t *testing.T) {
+		r := D2Renderer{DetailsLink: func(ref string) string { return "/drill?ref=" + ref }}
+		output, err := r.Render(arch)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(output, `link: "/drill?ref=payments-detail"`) {
+			t.Errorf("expected mapped details link in output:\n%s", output)
+		}
+	})
+}
+
+func TestD2Renderer_Options(t *testing.T) {
+	arch := domain.NewArchitecture("test-arch", "Test", "Desc")
+	svc := arch.DefineNode("svc", domain.Service, "Service", "desc")
+	kafka := arch.DefineNode("kafka", domain.Queue, "Kafka", "desc")
+	rabbit := arch.DefineNode("rabbit", domain.Queue, "RabbitMQ", "desc")
+	toKafka := svc.ConnectTo(kafka, "publish").GetID()
+	arch.DefineOptions("broker", "Broker choice").
+		Option("Kafka", []string{kafka.UniqueID}, []string{toKafka}).
+		Option("RabbitMQ", []string{rabbit.UniqueID}, nil)
+
+	output, err := D2Renderer{}.Render(arch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := []string{
+		"broker: Broker choice {\n  shape: diamond",
+		"broker -> kafka: Kafka {\n  style.stroke-dash: 5\n}",
+		"broker -> rabbit: RabbitMQ {\n  style.stroke-dash: 5\n}",
+		"svc -> kafka {\n  style.stroke-dash: 5\n}",
+	}
+	for _, c := range checks {
+		if !strings.Contains(output, c) {
+			t.Errorf("expected output to contain %q:\n%s", c, output)
+		}
+	}
+}
+
+func TestD2Renderer_NodeTypeClasses(t *testing.T) {
+	arch := domain.NewArchitecture("test-arch", "Test", "Desc")
+	arch.DefineNode("web", domain.WebClient, "Web", "desc")
+	arch.DefineNode("asset", domain.DataAsset, "Asset", "desc")
+	arch.DefineNode("mf", domain.NodeType("test-mainframe"), "Mainframe", "desc")
+	arch.DefineNode("gw", domain.NodeType("test-gateway"), "Gateway", "desc")
+	restoreD2Classes(t)
+	RegisterD2Class("test-gateway", D2Class{Shape: "hexagon", Fill: "#000000"})
+
+	output, err := D2Renderer{}.Render(arch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := []string{
+		"  webclient: {\n    shape: page",
+		"  data-asset: {\n    shape: document",
+		"  ldap: {\n    shape: stored_data",
+		"  test-gateway: {\n    shape: hexagon",
+		"  test-mainframe: {\n    shape: rectangle",
+		"class: data-asset",
+	}
+	for _, c := range checks {
+		if !strings.Contains(output, c) {
+			t.Errorf("expected output to contain %q", c)
+		}
+	}
+}
+
+// restoreD2Classes undoes RegisterD2Class calls made by a test, so that other
+// tests (such as the golden files) see only the built-in classes.
+func restoreD2Classes(t *testing.T) {
+	d2ClassesMu.RLock()
+	order := append([]domain.NodeType(nil), d2ClassOrder...)
+	classes := make(map[domain.NodeType]D2Class, len(d2Classes))
+	for k, v := range d2Classes {
+		classes[k] = v
+	}
+	d2ClassesMu.RUnlock()
+
+	t.Cleanup(func() {
+		d2ClassesMu.Lock()
+		defer d2ClassesMu.Unlock()
+		d2ClassOrder = order
+		d2Classes = classes
+	})
+}
@@ -1,13 +1,11 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-
 	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 )
 
@@ -56,8 +54,8 @@ func (GoDSLRenderer) Render(a *domain.Architecture) (string, error) {
 	// Controls
 	if len(a.Controls) > 0 {
 		sb.WriteString("\n\t// Define global controls\n")
-		for id, ctrl := range a.Controls {
-			generateControlDSL(&sb, id, ctrl)
+		for _, id := range domain.SortedControlIDs(a.Controls) {
+			generateControlDSL(&sb, id, a.Controls[id])
 		}
 	}
 
@@ -68,9 +66,10 @@ func (GoDSLRenderer) Render(a *domain.Architecture) (string, error) {
 }
 
 func generateNodeDSL(sb *strings.Builder, node *domain.Node) {
-	nodeType := string(node.NodeType)
-	caser := cases.Title(language.English)
-	nodeType = caser.String(strings.ToLower(nodeType))
+	nodeType, ok := domain.NodeTypeConstName(node.NodeType)
+	if !ok {
+		nodeType = fmt.Sprintf("NodeType(%q)", node.NodeType)
+	}
 
 	sb.WriteString(fmt.Sprintf("\tarch.DefineNode(\n"))
 	sb.WriteString(fmt.Sprintf("\t\t%q, %s, %q, %q,\n", node.UniqueID, nodeType, node.Name, node.Description))
@@ -92,6 +91,10 @@ func generateNodeDSL(sb *strings.Builder, node *domain.Node) {
 		sb.WriteString("\t\t}),\n")
 	}
 
+	if d := node.Details; d != nil {
+		sb.WriteString(fmt.Sprintf("\t\tWithDetails(%q, %q),\n", d.DetailedArchitecture, d.RequiredPattern))
+	}
+
 	// Interfaces
 	if len(node.Interfaces) > 0 {
 		sb.WriteString("\t\tWithInterfaces(\n")
@@ -122,6 +125,12 @@ func generateInterfaceDSL(sb *strings.Builder, iface domain.Interface) {
 	if iface.Database != "" {
 		sb.WriteString(fmt.Sprintf(", Database: %q", iface.Database))
 	}
+	if iface.DefinitionURL != "" {
+		sb.WriteString(fmt.Sprintf(", DefinitionURL: %q", iface.DefinitionURL))
+	}
+	if iface.Config != nil {
+		sb.WriteString(fmt.Sprintf(", Config: %s", formatValue(iface.Config)))
+	}
 	sb.WriteString("},\n")
 }
 
@@ -144,6 +153,7 @@ func generateRelDSL(sb *strings.Builder, rel *domain.Relationship) {
 		if rel.DataClassification != "" {
 			sb.WriteString(fmt.Sprintf("\t\tDataClassification: %q,\n", rel.DataClassification))
 		}
+		writeRelControlsDSL(sb, rel)
 
 		sb.WriteString("\t\tRelationshipType: RelationshipType{\n")
 		sb.WriteString("\t\t\tConnects: &Connects{\n")
@@ -169,34 +179,55 @@ func generateRelDSL(sb *strings.Builder, rel *domain.Relationship) {
 		sb.WriteString("\t})\n\n")
 	}
 
-	if rel.RelationshipType.Interacts != nil {
-		actor, _ := rel.RelationshipType.Interacts["actor"].(string)
-		nodes, _ := rel.RelationshipType.Interacts["nodes"].([]string)
-
+	if interacts := rel.RelationshipType.Interacts; interacts != nil {
 		sb.WriteString(fmt.Sprintf("\t// %s (interacts)\n", rel.UniqueID))
 		sb.WriteString(fmt.Sprintf("\tarch.AddRelationship(&Relationship{\n"))
 		sb.WriteString(fmt.Sprintf("\t\tUniqueID: %q,\n", rel.UniqueID))
 		sb.WriteString(fmt.Sprintf("\t\tDescription: %q,\n", rel.Description))
+		writeRelControlsDSL(sb, rel)
 		sb.WriteString("\t\tRelationshipType: RelationshipType{\n")
-		sb.WriteString("\t\t\tInteracts: map[string]any{\n")
-		sb.WriteString(fmt.Sprintf("\t\t\t\t\"actor\": %q,\n", actor))
-		sb.WriteString(fmt.Sprintf("\t\t\t\t\"nodes\": %s,\n", formatStringSlice(nodes)))
+		sb.WriteString("\t\t\tInteracts: &Interacts{\n")
+		sb.WriteString(fmt.Sprintf("\t\t\t\tActor: %q,\n", interacts.Actor))
+		sb.WriteString(fmt.Sprintf("\t\t\t\tNodes: %s,\n", formatStringSlice(interacts.Nodes)))
 		sb.WriteString("\t\t\t},\n")
 		sb.WriteString("\t\t},\n")
 		sb.WriteString("\t})\n\n")
 	}
 
-	if rel.RelationshipType.ComposedOf != nil {
-		container, _ := rel.RelationshipType.ComposedOf["container"].(string)
-		nodes, _ := rel.RelationshipType.ComposedOf["nodes"].([]string)
-
+	if comp := rel.RelationshipType.ComposedOf; comp != nil {
 		sb.WriteString(fmt.Sprintf("\t// %s (composed-of)\n", rel.UniqueID))
 		sb.WriteString(fmt.Sprintf("\tarch.AddRelationship(&Relationship{\n"))
 		sb.WriteString(fmt.Sprintf("\t\tUniqueID: %q,\n", rel.UniqueID))
+		writeRelControlsDSL(sb, rel)
+		sb.WriteString("\t\tRelationshipType: RelationshipType{\n")
+		sb.WriteString("\t\t\tComposedOf: &ComposedOf{\n")
+		sb.WriteString(fmt.Sprintf("\t\t\t\tContainer: %q,\n", comp.Container))
+		sb.WriteString(fmt.Sprintf("\t\t\t\tNodes: %s,\n", formatStringSlice(comp.Nodes)))
+		sb.WriteString("\t\t\t},\n")
+		sb.WriteString("\t\t},\n")
+		sb.WriteString("\t})\n\n")
+	}
+
+	if rel.RelationshipType.Options != nil {
+		sb.WriteString(fmt.Sprintf("\t// %s (options)\n", rel.UniqueID))
+		sb.WriteString(fmt.Sprintf("\tarch.DefineOptions(%q, %q)", rel.UniqueID, rel.Description))
+		for _, d := range rel.RelationshipType.Options {
+			sb.WriteString(fmt.Sprintf(".\n\t\tOption(%q, %s, %s)",
+				d.Description, formatStringSlice(d.Nodes), formatStringSlice(d.Relationships)))
+		}
+		sb.WriteString("\n\n")
+	}
+
+	if deployed := rel.RelationshipType.DeployedIn; deployed != nil {
+		sb.WriteString(fmt.Sprintf("\t// %s (deployed-in)\n", rel.UniqueID))
+		sb.WriteString(fmt.Sprintf("\tarch.AddRelationship(&Relationship{\n"))
+		sb.WriteString(fmt.Sprintf("\t\tUniqueID: %q,\n", rel.UniqueID))
+		sb.WriteString(fmt.Sprintf("\t\tDescription: %q,\n", rel.Description))
+		writeRelControlsDSL(sb, rel)
 		sb.WriteString("\t\tRelationshipType: RelationshipType{\n")
-		sb.WriteString("\t\t\tComposedOf: map[string]any{\n")
-		sb.WriteString(fmt.Sprintf("\t\t\t\t\"container\": %q,\n", container))
-		sb.WriteString(fmt.Sprintf("\t\t\t\t\"nodes\": %s,\n", formatStringSlice(nodes)))
+		sb.WriteString("\t\t\tDeployedIn: &DeployedIn{\n")
+		sb.WriteString(fmt.Sprintf("\t\t\t\tContainer: %q,\n", deployed.Container))
+		sb.WriteString(fmt.Sprintf("\t\t\t\tNodes: %s,\n", formatStringSlice(deployed.Nodes)))
 		sb.WriteString("\t\t\t},\n")
 		sb.WriteString("\t\t},\n")
 		sb.WriteString("\t})\n\n")
@@ -207,7 +238,15 @@ func generateFlowDSL(sb *strings.Builder, flow *domain.Flow) {
 	sb.WriteString(fmt.Sprintf("\tarch.DefineFlow(%q, %q, %q)", flow.UniqueID, flow.Name, flow.Description))
 
 	if len(flow.Metadata) > 0 {
-		sb.WriteString(fmt.Sprintf(".MetaMap(%s)", formatValue(flow.Metadata)))
+		sb.WriteString(fmt.Sprintf(".MetaMap(%s)", formatValue(map[string]any(flow.Metadata))))
+	}
+	for _, id := range domain.SortedControlIDs(flow.Controls) {
+		ctrl := flow.Controls[id]
+		args := []string{fmt.Sprintf("%q", id), fmt.Sprintf("%q", ctrl.Description)}
+		for _, req := range ctrl.Requirements {
+			args = append(args, "Requirement{"+formatRequirement(req)+"}")
+		}
+		sb.WriteString(fmt.Sprintf(".\n\t\tControl(%s)", strings.Join(args, ", ")))
 	}
 
 	transitions := append([]domain.Transition(nil), flow.Transitions...)
@@ -216,7 +255,7 @@ func generateFlowDSL(sb *strings.Builder, flow *domain.Flow) {
 	})
 
 	for _, t := range transitions {
-		if t.Direction != "" && t.Direction != "source-to-destination" {
+		if t.Direction != "" && t.Direction != domain.SourceToDestination {
 			sb.WriteString(fmt.Sprintf(".StepEx(%q, %q, %q)", t.RelationshipID, t.Description, t.Direction))
 		} else {
 			sb.WriteString(fmt.Sprintf(".Step(%q, %q)", t.RelationshipID, t.Description))
@@ -232,22 +271,52 @@ func generateControlDSL(sb *strings.Builder, id string, ctrl *domain.Control) {
 	if len(ctrl.Requirements) > 0 {
 		sb.WriteString("\t\tRequirements: []Requirement{\n")
 		for _, req := range ctrl.Requirements {
-			fields := []string{fmt.Sprintf("RequirementURL: %q", req.RequirementURL)}
-			if req.Config != nil {
-				fields = append(fields, fmt.Sprintf("Config: %s", formatValue(req.Config)))
-			}
-			if req.ConfigURL != "" {
-				fields = append(fields, fmt.Sprintf("ConfigURL: %q", req.ConfigURL))
-			}
-			sb.WriteString(fmt.Sprintf("\t\t\t{%s},\n", strings.Join(fields, ", ")))
+			sb.WriteString(fmt.Sprintf("\t\t\t{%s},\n", formatRequirement(req)))
 		}
 		sb.WriteString("\t\t},\n")
 	}
 	sb.WriteString("\t}\n")
 }
 
+// writeRelControlsDSL writes the Controls field of a relationship literal.
+func writeRelControlsDSL(sb *strings.Builder, rel *domain.Relationship) {
+	if len(rel.Controls) == 0 {
+		return
+	}
+	sb.WriteString("\t\tControls: map[string]*Control{\n")
+	for _, id := range domain.SortedControlIDs(rel.Controls) {
+		ctrl := rel.Controls[id]
+		sb.WriteString(fmt.Sprintf("\t\t\t%q: {Description: %q", id, ctrl.Description))
+		if len(ctrl.Requirements) > 0 {
+			reqs := make([]string, len(ctrl.Requirements))
+			for i, req := range ctrl.Requirements {
+				reqs[i] = "{" + formatRequirement(req) + "}"
+			}
+			sb.WriteString(fmt.Sprintf(", Requirements: []Requirement{%s}", strings.Join(reqs, ", ")))
+		}
+		sb.WriteString("},\n")
+	}
+	sb.WriteString("\t\t},\n")
+}
+
+// formatRequirement returns the fields of a Requirement literal.
+func formatRequirement(req domain.Requirement) string {
+	fields := []string{fmt.Sprintf("RequirementURL: %q", req.RequirementURL)}
+	if req.Config != nil {
+		fields = append(fields, fmt.Sprintf("Config: %s", formatValue(req.Config)))
+	}
+	if req.ConfigURL != "" {
+		fields = append(fields, fmt.Sprintf("ConfigURL: %q", req.ConfigURL))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// formatValue returns a Go literal for a JSON-like value. Values of other
+// types, such as structs or typed slices, are rendered as their JSON form.
 func formatValue(v any) string {
 	switch val := v.(type) {
+	case nil:
+		return "nil"
 	case string:
 		return fmt.Sprintf("%q", val)
 	case bool:
@@ -273,7 +342,15 @@ func formatValue(v any) string {
 		}
 		return "map[string]any{" + strings.Join(items, ", ") + "}"
 	default:
-		return fmt.Sprintf("%#v", val)
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%#v", val)
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return fmt.Sprintf("%#v", val)
+		}
+		return formatValue(generic)
 	}
 }
 
@@ -52,20 +52,54 @@ func TestGoDSLRenderer_Metadata(t *testing.T) {
 	}
 }
 
+func TestGoDSLRenderer_StructConfig(t *testing.T) {
+	type tlsConfig struct {
+		Port  int      `json:"port"`
+		Hosts []string `json:"hosts"`
+	}
+	arch := domain.NewArchitecture("test", "Test", "Desc")
+	arch.DefineNode("node1", domain.Service, "Node 1", "desc",
+		domain.WithInterfaces(&domain.Interface{UniqueID: "i1", Config: tlsConfig{Port: 443, Hosts: []string{"a"}}}),
+	)
+	arch.AddControl("c1", "desc", domain.NewRequirement("url", []map[string]any{{"rto": 5}}))
+
+	output, err := GoDSLRenderer{}.Render(arch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`Config: map[string]any{"hosts": []any{"a"}, "port": 443}`,
+		`Config: []any{map[string]any{"rto": 5}}`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in\n%s", want, output)
+		}
+	}
+}
+
 func TestGoDSLRenderer_Full(t *testing.T) {
 	arch := domain.NewArchitecture("full-arch", "Full Architecture", "Full Desc")
 	arch.ADRs = []string{"adr1.md"}
 
 	arch.DefineNode("n1", domain.Service, "Node 1", "desc1",
-		domain.WithInterfaces(&domain.Interface{UniqueID: "i1", Name: "Intf 1", Protocol: "http", Port: 80}),
+		domain.WithInterfaces(
+			&domain.Interface{UniqueID: "i1", Name: "Intf 1", Protocol: "http", Port: 80},
+			(&domain.Interface{UniqueID: "i2"}).Definition("https://example.com/rest.json", map[string]any{"port": 443}),
+		),
 	)
 	arch.DefineNode("n2", domain.Database, "Node 2", "desc2")
+	arch.DefineNode("n3", domain.WebClient, "Node 3", "desc3")
+	arch.DefineNode("n4", domain.NodeType("mainframe"), "Node 4", "desc4")
 
-	arch.Connect("r1", "Rel 1", "n1", "n2").Data("internal", true).WithProtocol("grpc")
+	arch.Connect("r1", "Rel 1", "n1", "n2").Data("internal", true).WithProtocol("grpc").
+		AddControl("mtls", "Mutual TLS", domain.NewRequirement("mtls-url", nil))
 	arch.Interacts("r2", "Rel 2", "actor1", "n1")
 	arch.ComposedOf("r3", "Rel 3", "sys1", []string{"n1"})
+	arch.DeployedIn("r4", "Rel 4", "k8s", []string{"n1"})
+	arch.DefineOptions("r5", "Rel 5").Option("Opt A", []string{"n2"}, []string{"r1"})
 
-	arch.DefineFlow("f1", "Flow 1", "desc").Step("r1", "step1")
+	arch.DefineFlow("f1", "Flow 1", "desc").Step("r1", "step1").
+		Control("audit", "Audit", domain.NewRequirementURL("audit-url", "audit-config"))
 
 	arch.AddControl("c1", "Control 1", domain.NewRequirement("url1", nil))
 
@@ -77,17 +111,28 @@ func TestGoDSLRenderer_Full(t *testing.T) {
 
 	checks := []string{
 		"arch.ADRs = []string{",
+		"\"n3\", WebClient, \"Node 3\"",
+		"\"n4\", NodeType(\"mainframe\"), \"Node 4\"",
 		"\"adr1.md\"",
 		"WithInterfaces(",
 		"&Interface{UniqueID: \"i1\", Name: \"Intf 1\", Protocol: \"http\", Port: 80}",
+		"DefinitionURL: \"https://example.com/rest.json\", Config: map[string]any{\"port\": 443}}",
 		"arch.AddRelationship(&Relationship{",
 		"UniqueID: \"r1\"",
 		"DataClassification: \"internal\"",
 		"Encrypted: BoolPtr(true)",
-		"\"actor\": \"actor1\"",
-		"\"container\": \"sys1\"",
+		"Interacts: &Interacts{",
+		"Actor: \"actor1\"",
+		"ComposedOf: &ComposedOf{",
+		"Container: \"sys1\"",
+		"DeployedIn: &DeployedIn{",
+		"Container: \"k8s\"",
+		"arch.DefineOptions(\"r5\", \"Rel 5\").\n\t\tOption(\"Opt A\", []string{\"n2\"}, []string{\"r1\"})",
 		"arch.DefineFlow(\"f1\", \"Flow 1\", \"desc\")",
 		".Step(\"r1\", \"step1\")",
+		"Controls: map[string]*Control{",
+		"\"mtls\": {Description: \"Mutual TLS\"",
+		"Control(\"audit\", \"Audit\", Requirement{RequirementURL: \"audit-url\", ConfigURL: \"audit-config\"})",
 		"arch.Controls[\"c1\"]",
 	}
 
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// buildGoldenArch builds the same model in declaration order or in reverse.
+func buildGoldenArch(reverse bool) *domain.Architecture {
+	arch := domain.NewArchitecture("shop", "Shop", "Golden test architecture")
+	arch.AddMeta("version", "1.0").AddMeta("owner", "Platform Team")
+
+	type nodeDef struct {
+		id    string
+		ntype domain.NodeType
+		name  string
+		opts  []domain.NodeOption
+	}
+	nodes := []nodeDef{
+		{"customer", domain.Actor, "Customer", nil},
+		{"platform", domain.System, "Platform", nil},
+		{"k8s", domain.Network, "Cluster", nil},
+		{"web", domain.WebClient, "Web", nil},
+		{"api", domain.Service, "API", []domain.NodeOption{
+			domain.WithOwner("team-api", "CC-1"),
+			domain.WithMeta(map[string]any{"tier": "tier-1", "health-endpoint": "/health"}),
+			domain.WithInterfaces(
+				&domain.Interface{UniqueID: "api-rest", Protocol: "HTTPS", Port: 443},
+				&domain.Interface{UniqueID: "api-admin", Protocol: "HTTPS", Port: 8443},
+			),
+		}},
+		{"db", domain.Database, "DB", []domain.NodeOption{domain.WithMeta(map[string]any{"backup-schedule": "daily"})}},
+	}
+	controls := [][2]string{{"security", "Security"}, {"availability", "Availability"}, {"audit", "Audit"}}
+	if reverse {
+		for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+			nodes[i], nodes[j] = nodes[j], nodes[i]
+		}
+		for i, j := 0, len(controls)-1; i < j; i, j = i+1, j-1 {
+			controls[i], controls[j] = controls[j], controls[i]
+		}
+	}
+	for _, c := range controls {
+		arch.AddControl(c[0], c[1]+" requirement", domain.NewRequirement("https://example.com/"+c[0]+".json", nil))
+	}
+	for _, n := range nodes {
+		arch.DefineNode(n.id, n.ntype, n.name, n.name+" node", n.opts...)
+	}
+
+	rels := []func(){
+		func() { arch.Interacts("customer-uses-web", "Customer browses", "customer", "web") },
+		func() { arch.Connect("web-to-api", "Web calls API", "web", "api").WithProtocol("HTTPS") },
+		func() { arch.Connect("api-to-db", "API queries DB", "api", "db").WithProtocol("JDBC") },
+		func() { arch.ComposedOf("platform-comp", "Platform parts", "platform", []string{"web", "api", "db"}) },
+		func() { arch.DeployedIn("k8s-deploy", "Runs on cluster", "k8s", []string{"db", "api"}) },
+	}
+	if reverse {
+		for i := len(rels) - 1; i >= 0; i-- {
+			rels[i]()
+		}
+	} else {
+		for _, r := range rels {
+			r()
+		}
+	}
+
+	arch.DefineFlow("checkout", "Checkout", "Customer checks out").
+		Step("web-to-api", "Submit order").
+		Step("api-to-db", "Store order")
+	return arch
+}
+
+func TestCanonicalRenderers_Golden(t *testing.T) {
+	cases := []struct {
+		file     string
+		renderer domain.Renderer
+	}{
+		{"json.golden", JSONRenderer{}},
+		{"d2.golden", D2Renderer{}},
+		{"rich_d2.golden", RichD2Renderer{}},
+		{"godsl.golden", GoDSLRenderer{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			r := CanonicalRenderer{Renderer: tc.renderer}
+			forward, err := r.Render(buildGoldenArch(false))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			backward, err := r.Render(buildGoldenArch(true))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if forward != backward {
+				t.Fatalf("declaration order changed canonical output:\n--- forward\n%s\n--- reverse\n%s", forward, backward)
+			}
+
+			path := filepath.Join("testdata", tc.file)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(forward), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("missing golden file (run go test -update): %v", err)
+			}
+			if forward != string(want) {
+				t.Errorf("output differs from %s (run go test -update to accept):\n%s", path, forward)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_DoesNotMutate(t *testing.T) {
+	arch := buildGoldenArch(true)
+	first := arch.Nodes[0].UniqueID
+	c := domain.Canonicalize(arch)
+	if arch.Nodes[0].UniqueID != first || c.Nodes[0].UniqueID != "api" {
+		t.Errorf("expected a sorted copy, got original %s and copy %s", arch.Nodes[0].UniqueID, c.Nodes[0].UniqueID)
+	}
+	if c.Nodes[0].Arch != c {
+		t.Errorf("expected copied nodes to point at the canonical architecture")
+	}
+}
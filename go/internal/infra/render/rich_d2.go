@@ -9,11 +9,15 @@ import (
 )
 
 // RichD2Renderer renders CALM architectures into Rich D2 source.
-type RichD2Renderer struct{}
+type RichD2Renderer struct {
+	// DetailsLink maps a node's detailed-architecture reference to the URL the
+	// node links to. When nil, the reference is used as is.
+	DetailsLink func(ref string) string
+}
 
 // Render generates D2 diagram source with embedded CALM metadata.
 // The metadata is stored in comments with @calm: prefix for bidirectional editing.
-func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
+func (r RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 	var sb strings.Builder
 
 	// Header with architecture metadata
@@ -30,79 +34,50 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 	sb.WriteString("direction: right\n\n")
 
 	// Style definitions
-	sb.WriteString("classes: {\n")
-	sb.WriteString("  actor: {\n    shape: person\n    style.fill: \"#e1f5fe\"\n  }\n")
-	sb.WriteString("  service: {\n    shape: rectangle\n    style.fill: \"#e8f5e9\"\n    style.border-radius: 8\n  }\n")
-	sb.WriteString("  database: {\n    shape: cylinder\n    style.fill: \"#fff3e0\"\n  }\n")
-	sb.WriteString("  queue: {\n    shape: queue\n    style.fill: \"#f3e5f5\"\n  }\n")
-	sb.WriteString("  system: {\n    shape: rectangle\n    style.fill: \"#fafafa\"\n    style.stroke-dash: 3\n  }\n")
-	sb.WriteString("}\n\n")
-
-	// Track composed nodes
-	nodeToParent := make(map[string]string)
-	parentToChildren := make(map[string][]string)
-	nodeByID := make(map[string]*domain.Node)
-
-	for _, node := range a.Nodes {
-		nodeByID[node.UniqueID] = node
-	}
+	writeD2Classes(&sb, a)
 
-	for _, rel := range a.Relationships {
-		if rel.RelationshipType.ComposedOf != nil {
-			container, _ := rel.RelationshipType.ComposedOf["container"].(string)
-			if nodes, ok := rel.RelationshipType.ComposedOf["nodes"].([]string); ok {
-				for _, n := range nodes {
-					if _, exists := nodeToParent[n]; exists {
-						continue
-					}
-					nodeToParent[n] = container
-					parentToChildren[container] = append(parentToChildren[container], n)
-				}
-			}
-		}
-	}
+	g := domain.NewGraph(a)
 
 	// Recursive function to render nodes and nested containers.
 	var writeNodeRecursive func(id string, indent string)
 	writeNodeRecursive = func(nodeID string, indent string) {
-		targetNode := nodeByID[nodeID]
-		if targetNode == nil {
+		targetNode, ok := g.Node(nodeID)
+		if !ok {
 			return
 		}
 
-		children := parentToChildren[nodeID]
+		children := g.Children(nodeID)
 		if len(children) == 0 {
-			writeRichNode(&sb, targetNode, indent)
+			writeRichNode(&sb, targetNode, indent, r.DetailsLink)
 			sb.WriteString("\n")
 			return
 		}
 
-		writeRichContainerHeader(&sb, targetNode, indent)
+		writeRichContainerHeader(&sb, targetNode, indent, r.DetailsLink)
 		for _, childID := range children {
-			if currentParent, ok := nodeToParent[childID]; ok && currentParent == nodeID {
-				writeNodeRecursive(childID, indent+"  ")
-			}
+			writeNodeRecursive(childID, indent+"  ")
 		}
 		sb.WriteString(indent + "}\n\n")
 	}
 
 	// Render top-level nodes (those without parents).
-	for _, node := range a.Nodes {
-		if _, hasParent := nodeToParent[node.UniqueID]; !hasParent {
-			writeNodeRecursive(node.UniqueID, "")
-		}
+	for _, id := range g.Roots() {
+		writeNodeRecursive(id, "")
 	}
 
 	sb.WriteString("\n# Relationships\n")
 
+	optional := optionalRelationships(a)
+	pathOf := func(nodeID string) string { return d2Path(g, nodeID) }
+
 	// Generate relationships with metadata
 	for _, rel := range a.Relationships {
 		if rel.RelationshipType.Connects != nil {
 			src := rel.RelationshipType.Connects.Source.Node
 			dst := rel.RelationshipType.Connects.Destination.Node
 
-			srcPath := getFullD2Path(src, nodeToParent)
-			dstPath := getFullD2Path(dst, nodeToParent)
+			srcPath := pathOf(src)
+			dstPath := pathOf(dst)
 
 			// Build label
 			label := ""
@@ -125,6 +100,9 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 
 			// Write CALM metadata as block
 			sb.WriteString(" {\n")
+			if optional[rel.UniqueID] {
+				sb.WriteString("  style.stroke-dash: 5\n")
+			}
 			sb.WriteString(fmt.Sprintf("  # @calm:id=%s\n", rel.UniqueID))
 			sb.WriteString(fmt.Sprintf("  # @calm:description=%s\n", escapeD2String(rel.Description)))
 
@@ -151,34 +129,50 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 				sb.WriteString(fmt.Sprintf("  # @calm:classification=%s\n", rel.DataClassification))
 			}
 			if len(rel.Metadata) > 0 {
-				sb.WriteString(fmt.Sprintf("  # @calm:metadata=%s\n", toJSON(rel.Metadata)))
+				sb.WriteString(fmt.Sprintf("  # @calm:metadata=%s\n", metadataJSON(rel.Metadata, rel.MetadataLayout)))
+			}
+			if len(rel.Controls) > 0 {
+				sb.WriteString(fmt.Sprintf("  # @calm:controls=%s\n", toJSON(rel.Controls)))
 			}
 
 			sb.WriteString("}\n")
 		}
 
-		if rel.RelationshipType.Interacts != nil {
-			actor, _ := rel.RelationshipType.Interacts["actor"].(string)
-			if nodes, ok := rel.RelationshipType.Interacts["nodes"].([]string); ok {
-				for _, n := range nodes {
-					dstPath := getFullD2Path(n, nodeToParent)
-					sb.WriteString(fmt.Sprintf("%s -> %s {\n", sanitizeID(actor), dstPath))
-					sb.WriteString(fmt.Sprintf("  # @calm:id=%s\n", rel.UniqueID))
-					sb.WriteString(fmt.Sprintf("  # @calm:type=interacts\n"))
-					sb.WriteString(fmt.Sprintf("  # @calm:actor=%s\n", actor))
-					if rel.DataClassification != "" {
-						sb.WriteString(fmt.Sprintf("  # @calm:classification=%s\n", rel.DataClassification))
-					}
-					sb.WriteString("}\n")
+		if interacts := rel.RelationshipType.Interacts; interacts != nil {
+			for _, n := range interacts.Nodes {
+				dstPath := pathOf(n)
+				sb.WriteString(fmt.Sprintf("%s -> %s {\n", sanitizeID(interacts.Actor), dstPath))
+				sb.WriteString(fmt.Sprintf("  # @calm:id=%s\n", rel.UniqueID))
+				sb.WriteString(fmt.Sprintf("  # @calm:type=interacts\n"))
+				sb.WriteString(fmt.Sprintf("  # @calm:actor=%s\n", interacts.Actor))
+				if rel.DataClassification != "" {
+					sb.WriteString(fmt.Sprintf("  # @calm:classification=%s\n", rel.DataClassification))
+				}
+				if len(rel.Controls) > 0 {
+					sb.WriteString(fmt.Sprintf("  # @calm:controls=%s\n", toJSON(rel.Controls)))
 				}
+				sb.WriteString("}\n")
 			}
 		}
 
-		if rel.RelationshipType.ComposedOf != nil {
-			container, _ := rel.RelationshipType.ComposedOf["container"].(string)
-			nodes, _ := rel.RelationshipType.ComposedOf["nodes"].([]string)
+		if comp := rel.RelationshipType.ComposedOf; comp != nil {
 			sb.WriteString(fmt.Sprintf("# @calm:composed-of id=%s container=%s nodes=%s\n",
-				rel.UniqueID, container, toJSON(nodes)))
+				rel.UniqueID, comp.Container, toJSON(comp.Nodes)))
+			writeRelControls(&sb, rel)
+		}
+
+		if deployed := rel.RelationshipType.DeployedIn; deployed != nil {
+			sb.WriteString(fmt.Sprintf("# @calm:deployed-in id=%s container=%s nodes=%s desc=%s\n",
+				rel.UniqueID, deployed.Container, toJSON(deployed.Nodes), escapeD2String(rel.Description)))
+			writeRelControls(&sb, rel)
+			for _, n := range deployed.Nodes {
+				writeDeployedInEdge(&sb, pathOf(n), pathOf(deployed.Container))
+			}
+		}
+
+		if rel.RelationshipType.Options != nil {
+			sb.WriteString(fmt.Sprintf("# @calm:options id=%s data=%s\n", rel.UniqueID, toJSON(rel)))
+			writeOptionsDecision(&sb, rel, pathOf)
 		}
 	}
 
@@ -189,7 +183,11 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 			sb.WriteString(fmt.Sprintf("# @calm:flow id=%s name=%s\n", flow.UniqueID, escapeD2String(flow.Name)))
 			sb.WriteString(fmt.Sprintf("# @calm:flow-description=%s\n", escapeD2String(flow.Description)))
 			if len(flow.Metadata) > 0 {
-				sb.WriteString(fmt.Sprintf("# @calm:flow-metadata=%s\n", toJSON(flow.Metadata)))
+				sb.WriteString(fmt.Sprintf("# @calm:flow-metadata=%s\n",
+					metadataJSON(flow.Metadata, flow.MetadataLayout)))
+			}
+			if len(flow.Controls) > 0 {
+				sb.WriteString(fmt.Sprintf("# @calm:flow-controls=%s\n", toJSON(flow.Controls)))
 			}
 			for _, t := range flow.Transitions {
 				sb.WriteString(fmt.Sprintf("# @calm:flow-step seq=%d rel=%s dir=%s desc=%s\n",
@@ -201,8 +199,8 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 	// Generate global controls
 	if len(a.Controls) > 0 {
 		sb.WriteString("\n# Global Controls\n")
-		for id, ctrl := range a.Controls {
-			ctrlJSON, _ := json.Marshal(ctrl)
+		for _, id := range domain.SortedControlIDs(a.Controls) {
+			ctrlJSON, _ := json.Marshal(a.Controls[id])
 			sb.WriteString(fmt.Sprintf("# @calm:control id=%s data=%s\n", id, string(ctrlJSON)))
 		}
 	}
@@ -210,9 +208,17 @@ func (RichD2Renderer) Render(a *domain.Architecture) (string, error) {
 	return sb.String(), nil
 }
 
-func writeRichContainerHeader(sb *strings.Builder, node *domain.Node, indent string) {
+// writeRelControls annotates the controls of a relationship drawn without a
+// block of its own (composed-of, deployed-in).
+func writeRelControls(sb *strings.Builder, rel *domain.Relationship) {
+	if len(rel.Controls) > 0 {
+		sb.WriteString(fmt.Sprintf("# @calm:relationship-controls id=%s data=%s\n", rel.UniqueID, toJSON(rel.Controls)))
+	}
+}
+
+func writeRichContainerHeader(sb *strings.Builder, node *domain.Node, indent string, detailsLink func(string) string) {
 	id := sanitizeID(node.UniqueID)
-	className := strings.ToLower(string(node.NodeType))
+	className := d2ClassName(node.NodeType)
 
 	sb.WriteString(fmt.Sprintf("%s%s: %s {\n", indent, id, node.Name))
 	sb.WriteString(fmt.Sprintf("%s  class: %s\n", indent, className))
@@ -233,12 +239,18 @@ func writeRichContainerHeader(sb *strings.Builder, node *domain.Node, indent str
 
 	// Metadata as JSON
 	if len(node.Metadata) > 0 {
-		sb.WriteString(fmt.Sprintf("%s  # @calm:metadata=%s\n", indent, toJSON(node.Metadata)))
+		sb.WriteString(fmt.Sprintf("%s  # @calm:metadata=%s\n", indent,
+			metadataJSON(node.Metadata, node.MetadataLayout)))
+	}
+
+	if node.Details != nil {
+		sb.WriteString(fmt.Sprintf("%s  # @calm:details=%s\n", indent, toJSON(node.Details)))
+		writeDetailsLink(sb, node, indent, detailsLink)
 	}
 }
 
-func writeRichNode(sb *strings.Builder, node *domain.Node, indent string) {
-	writeRichContainerHeader(sb, node, indent)
+func writeRichNode(sb *strings.Builder, node *domain.Node, indent string, detailsLink func(string) string) {
+	writeRichContainerHeader(sb, node, indent, detailsLink)
 
 	// Interfaces as JSON
 	if len(node.Interfaces) > 0 {
@@ -264,25 +276,15 @@ func toJSON(v any) string {
 	return string(data)
 }
 
-func getFullD2Path(nodeID string, composedNodes map[string]string) string {
-	segments := []string{sanitizeID(nodeID)}
-	seen := make(map[string]bool)
-	for {
-		parent, ok := composedNodes[nodeID]
-		if !ok || seen[parent] {
-			break
-		}
-		seen[parent] = true
-		segments = append([]string{sanitizeID(parent)}, segments...)
-		nodeID = parent
-	}
-	return strings.Join(segments, ".")
+// metadataJSON encodes metadata in the form (object or array) it was read in.
+func metadataJSON(m domain.Metadata, layout domain.MetadataLayout) string {
+	return toJSON(m.Shaped(layout))
 }
 
 // writeRichContainerNode writes a container node with its children in a single D2 block.
 func writeRichContainerNode(sb *strings.Builder, container *domain.Node, childIDs []string, allNodes []*domain.Node) {
 	id := sanitizeID(container.UniqueID)
-	className := strings.ToLower(string(container.NodeType))
+	className := d2ClassName(container.NodeType)
 
 	// Start container block
 	sb.WriteString(fmt.Sprintf("%s: %s {\n", id, container.Name))
@@ -302,7 +304,8 @@ func writeRichContainerNode(sb *strings.Builder, container *domain.Node, childID
 		sb.WriteString(fmt.Sprintf("  # @calm:description=%s\n", escapeD2String(container.Description)))
 	}
 	if len(container.Metadata) > 0 {
-		sb.WriteString(fmt.Sprintf("  # @calm:metadata=%s\n", toJSON(container.Metadata)))
+		sb.WriteString(fmt.Sprintf("  # @calm:metadata=%s\n",
+			metadataJSON(container.Metadata, container.MetadataLayout)))
 	}
 
 	sb.WriteString("\n")
@@ -311,7 +314,7 @@ func writeRichContainerNode(sb *strings.Builder, container *domain.Node, childID
 	for _, childID := range childIDs {
 		for _, n := range allNodes {
 			if n.UniqueID == childID {
-				writeRichNode(sb, n, "  ")
+				writeRichNode(sb, n, "  ", nil)
 				sb.WriteString("\n")
 				break
 			}
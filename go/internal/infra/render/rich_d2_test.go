@@ -54,6 +54,8 @@ func TestRichD2Renderer_Full(t *testing.T) {
 	arch.ComposedOf("comp1", "composed", "sys1", []string{"svc1"})
 
 	arch.Interacts("int1", "interacts", "actor1", "svc1")
+	arch.DefineNode("k8s", domain.System, "Cluster", "desc")
+	arch.DeployedIn("dep1", "deployed", "k8s", []string{"svc1"})
 
 	renderer := RichD2Renderer{}
 	output, err := renderer.Render(arch)
@@ -69,6 +71,8 @@ func TestRichD2Renderer_Full(t *testing.T) {
 		"# @calm:composed-of id=comp1",
 		"actor1 -> sys1.svc1 {",
 		"# @calm:type=interacts",
+		"# @calm:deployed-in id=dep1 container=k8s nodes=[\"svc1\"] desc=deployed",
+		"sys1.svc1 -> k8s: deployed-in {",
 	}
 
 	for _, c := range checks {
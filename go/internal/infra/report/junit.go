@@ -0,0 +1,71 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// marshalJUnit renders r as one JUnit test suite with a test case per result,
+// named after the rule and the element. Results at FailOn or above fail; the
+// others pass and keep their message in system-out.
+func marshalJUnit(r usecase.ValidationReport) ([]byte, error) {
+	suite := junitSuite{Name: r.Architecture, Cases: []junitCase{}}
+	for _, res := range r.Results {
+		c := junitCase{ClassName: toolName + "." + res.RuleID, Name: elementName(r, res)}
+		if res.Location != nil {
+			c.File, c.Line = res.Location.File, res.Location.Line
+		}
+		detail := fmt.Sprintf("[%s %s %s] %s", res.RuleID, res.Rule, res.Severity, res.Message)
+		if res.Failing {
+			c.Failure = &junitFailure{Type: res.RuleID, Message: res.Message, Text: detail}
+			suite.Failures++
+		} else {
+			c.SystemOut = detail
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Tests = len(suite.Cases)
+
+	out, err := xml.MarshalIndent(junitSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+// Format is a machine-readable format for validation reports.
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatJSON, FormatSARIF, FormatJUnit}
+
+// toolName names the validator in SARIF and JUnit output.
+const toolName = "arch-gen"
+
+// Write writes r to w in format f.
+func Write(w io.Writer, f Format, r usecase.ValidationReport) error {
+	var out []byte
+	var err error
+	switch f {
+	case FormatJSON:
+		out, err = json.MarshalIndent(r, "", "  ")
+	case FormatSARIF:
+		out, err = json.MarshalIndent(newSARIFLog(r), "", "  ")
+	case FormatJUnit:
+		out, err = marshalJUnit(r)
+	default:
+		return fmt.Errorf("unknown report format %q", f)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
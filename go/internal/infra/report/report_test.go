@@ -0,0 +1,107 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+func newTestReport() usecase.ValidationReport {
+	return usecase.ValidationReport{
+		Architecture: "shop",
+		FailOn:       domain.SeverityError,
+		Results: []usecase.ValidationResult{
+			{
+				RuleID: "CALM001", Rule: "AllNodesHaveOwner", Severity: domain.SeverityError, Failing: true,
+				Kind: domain.KindNode, ElementID: "api", Message: "missing owner",
+				Location: &usecase.SourceLocation{File: "internal/usecase/shop.go", Line: 12},
+			},
+			{
+				RuleID: "CALM020", Rule: "NoUnusedInterfaces", Severity: domain.SeverityWarning,
+				Kind: domain.KindNode, ElementID: "db", Message: `interface "db-sql" is not used by any relationship`,
+			},
+			{
+				RuleID: "CALM001", Rule: "AllNodesHaveOwner", Severity: domain.SeverityError, Failing: true,
+				Message: "architecture-level finding",
+			},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("should write the report as JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, newTestReport()); err != nil {
+			t.Fatal(err)
+		}
+		var got usecase.ValidationReport
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Results) != 3 || got.Results[0].Location.Line != 12 {
+			t.Errorf("unexpected report %+v", got)
+		}
+	})
+
+	t.Run("should write SARIF with rules and locations", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatSARIF, newTestReport()); err != nil {
+			t.Fatal(err)
+		}
+		var log sarifLog
+		if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+			t.Fatal(err)
+		}
+		run := log.Runs[0]
+		if log.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[1].ID != "CALM020" {
+			t.Fatalf("unexpected rules %+v", run.Tool.Driver.Rules)
+		}
+		first := run.Results[0]
+		phys := first.Locations[0].PhysicalLocation
+		if phys == nil || phys.ArtifactLocation.URI != "internal/usecase/shop.go" || phys.Region.StartLine != 12 ||
+			first.Locations[0].LogicalLocations[0].FullyQualifiedName != "shop/node/api" {
+			t.Errorf("unexpected locations %+v", first.Locations)
+		}
+		if second := run.Results[1]; second.Level != "warning" || second.RuleIndex != 1 ||
+			second.Locations[0].PhysicalLocation != nil {
+			t.Errorf("unexpected result %+v", second)
+		}
+		if third := run.Results[2]; third.RuleIndex != 0 || third.Locations != nil {
+			t.Errorf("unexpected result %+v", third)
+		}
+	})
+
+	t.Run("should write JUnit with failures at fail-on", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJUnit, newTestReport()); err != nil {
+			t.Fatal(err)
+		}
+		var suites junitSuites
+		if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+			t.Fatal(err)
+		}
+		suite := suites.Suites[0]
+		if suites.Tests != 3 || suites.Failures != 2 || suite.Name != "shop" {
+			t.Fatalf("unexpected suites %+v", suites)
+		}
+		first, second := suite.Cases[0], suite.Cases[1]
+		if first.ClassName != "arch-gen.CALM001" || first.Name != "shop/node/api" || first.Line != 12 ||
+			first.Failure == nil || first.Failure.Type != "CALM001" {
+			t.Errorf("unexpected case %+v", first)
+		}
+		if second.Failure != nil || !strings.Contains(second.SystemOut, "[CALM020 NoUnusedInterfaces warning]") {
+			t.Errorf("unexpected case %+v", second)
+		}
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		if err := Write(&bytes.Buffer{}, "xml", newTestReport()); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+// SARIF version and schema of the logs newSARIFLog writes.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// newSARIFLog converts r into a single-run SARIF log. Rules are listed in
+// order of first appearance; results carry the DSL source as physical
+// location where known and the element as logical location.
+func newSARIFLog(r usecase.ValidationReport) sarifLog {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	for _, res := range r.Results {
+		idx, ok := ruleIndex[res.RuleID]
+		if !ok {
+			level := sarifLevel(res.Severity)
+			if info, found := domain.LookupRuleInfo(res.Rule); found {
+				level = sarifLevel(info.Severity)
+			}
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[res.RuleID] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   res.RuleID,
+				Name:                 res.Rule,
+				DefaultConfiguration: sarifConfiguration{Level: level},
+			})
+		}
+
+		var loc sarifLocation
+		if res.Location != nil {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: res.Location.File},
+				Region:           sarifRegion{StartLine: res.Location.Line},
+			}
+		}
+		name := elementName(r, res)
+		if res.ElementID != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{
+				Name:               res.ElementID,
+				FullyQualifiedName: name,
+				Kind:               string(res.Kind),
+			}}
+		}
+		result := sarifResult{
+			RuleID:    res.RuleID,
+			RuleIndex: idx,
+			Level:     sarifLevel(res.Severity),
+			Message:   sarifMessage{Text: res.Message},
+			// Track findings by element rather than by line, so moving DSL
+			// code does not reopen them.
+			PartialFingerprints: map[string]string{"calmFinding/v1": res.RuleID + ":" + name + ":" + res.Message},
+		}
+		if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+			result.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, result)
+	}
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s domain.Severity) string {
+	switch s {
+	case domain.SeverityWarning:
+		return "warning"
+	case domain.SeverityInfo:
+		return "note"
+	}
+	return "error"
+}
+
+// elementName identifies the element of res within all architectures, such as
+// "ecommerce/node/order-service", or just the architecture for findings about
+// it as a whole.
+func elementName(r usecase.ValidationReport, res usecase.ValidationResult) string {
+	if res.ElementID == "" {
+		return r.Architecture
+	}
+	kind := string(res.Kind)
+	if kind == "" {
+		kind = "element"
+	}
+	return r.Architecture + "/" + kind + "/" + res.ElementID
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// PolicyFileName is the policy file looked up from the working directory upwards.
+const PolicyFileName = "calm-policy.json"
+
+// policyFileNames are the policy files FindPolicy looks for in each directory,
+// in order of preference.
+var policyFileNames = []string{PolicyFileName, "calm-policy.yaml", "calm-policy.yml"}
+
+// LoadPolicy reads a JSON policy file, or a YAML one when its extension is
+// .yaml or .yml (see yamlToJSON for the YAML it accepts).
+func LoadPolicy(path string) (*domain.Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	p, err := domain.ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// FindPolicy returns the path of the nearest calm-policy.json, or else
+// calm-policy.yaml or .yml, in dir or its parents, or "" when there is none.
+func FindPolicy(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range policyFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
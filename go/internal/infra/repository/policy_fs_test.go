@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestFindPolicy(t *testing.T) {
+	t.Run("should find the policy in a parent directory", func(t *testing.T) {
+		root := t.TempDir()
+		sub := filepath.Join(root, "go", "cmd")
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		doc := `{"rules": [{"name": "owners", "required": ["owner"]}]}`
+		if err := os.WriteFile(filepath.Join(root, PolicyFileName), []byte(doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := FindPolicy(sub)
+		if err != nil || path != filepath.Join(root, PolicyFileName) {
+			t.Fatalf("expected policy in %s, got %q (%v)", root, path, err)
+		}
+		p, err := LoadPolicy(path)
+		if err != nil || len(p.Rules) != 1 || p.Rules[0].Name != "owners" {
+			t.Errorf("unexpected policy %v (%v)", p, err)
+		}
+	})
+
+	t.Run("should report the file of an invalid policy", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), PolicyFileName)
+		if err := os.WriteFile(path, []byte(`{"rule": []}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("expected error naming %s, got %v", path, err)
+		}
+	})
+
+	t.Run("should load YAML policies", func(t *testing.T) {
+		root := t.TempDir()
+		doc := `# extra rules
+rules:
+  - name: queue-dlq
+    description: "queues need a dead-letter queue, really"  # quoted
+    severity: warning
+    select:
+      node-types: [queue]
+      where: {metadata.tier: tier-1}
+    required:
+    - metadata.dead-letter-queue
+    patterns:
+      metadata.dead-letter-queue: .+-dlq
+      'owner': "team-.+"
+severities:
+  CALM017: warning
+`
+		if err := os.WriteFile(filepath.Join(root, "calm-policy.yaml"), []byte(doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		path, err := FindPolicy(root)
+		if err != nil || filepath.Base(path) != "calm-policy.yaml" {
+			t.Fatalf("expected the YAML policy, got %q (%v)", path, err)
+		}
+		p, err := LoadPolicy(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := domain.Policy{
+			Rules: []domain.PolicyRule{{
+				Name:        "queue-dlq",
+				Description: "queues need a dead-letter queue, really",
+				Severity:    domain.SeverityWarning,
+				Select: domain.PolicySelector{
+					NodeTypes: []domain.NodeType{domain.Queue},
+					Where:     map[string]string{"metadata.tier": "tier-1"},
+				},
+				Required: []string{"metadata.dead-letter-queue"},
+				Patterns: map[string]string{"metadata.dead-letter-queue": ".+-dlq", "owner": "team-.+"},
+			}},
+			Severities: map[string]domain.Severity{"CALM017": domain.SeverityWarning},
+		}
+		if !reflect.DeepEqual(*p, want) {
+			t.Errorf("expected %+v, got %+v", want, *p)
+		}
+	})
+
+	t.Run("should report the line of invalid YAML", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "calm-policy.yml")
+		if err := os.WriteFile(path, []byte("rules:\n  - name: a\n     required: [x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadPolicy(path)
+		if err == nil || !strings.Contains(err.Error(), path+": line 3") {
+			t.Errorf("expected error naming %s line 3, got %v", path, err)
+		}
+	})
+}
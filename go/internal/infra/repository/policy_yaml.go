@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlToJSON converts the subset of YAML that policy files need into JSON:
+// block mappings and sequences, flow sequences and mappings ([a, b] and
+// {k: v}), plain and quoted scalars, and comments. Every scalar becomes a
+// string, since every policy value is one. Anchors, tags, multi-line scalars
+// and multiple documents are rejected.
+func yamlToJSON(data []byte) ([]byte, error) {
+	lines, err := yamlLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	p := &yamlParser{lines: lines}
+	var v any = map[string]any{}
+	if len(lines) > 0 {
+		if v, err = p.block(lines[0].indent); err != nil {
+			return nil, err
+		}
+		if p.pos < len(lines) {
+			return nil, p.errorf("unexpected indentation")
+		}
+	}
+	return json.Marshal(v)
+}
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// yamlLines splits src into its non-empty lines without comments.
+func yamlLines(src string) ([]yamlLine, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(src, "\n") {
+		raw = strings.TrimRight(raw, " \r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		text = strings.TrimSpace(stripYAMLComment(text))
+		if text == "" || len(lines) == 0 && text == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes a # comment that starts the line or follows a
+// space outside quotes.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	num := 0
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	} else if len(p.lines) > 0 {
+		num = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// block parses the mapping or sequence whose lines start at indent.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isSeqItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || l.indent == indent && isSeqItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, err := splitYAMLKey(l.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		if m[key], err = p.value(l, rest, true); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	s := []any{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || l.indent == indent && !isSeqItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if rest != "" && !strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "{") {
+			if _, _, err := splitYAMLKey(rest); err == nil {
+				// "- key: value" starts a mapping indented to the key.
+				p.lines[p.pos].indent = indent + len(l.text) - len(rest)
+				p.lines[p.pos].text = rest
+				m, err := p.mapping(p.lines[p.pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, m)
+				continue
+			}
+		}
+		p.pos++
+		v, err := p.value(l, rest, false)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+	return s, nil
+}
+
+// value parses the value after "key:" or "-" on line l: inline, or a nested
+// block on the following lines. A mapping value may be a sequence at the
+// key's own indentation.
+func (p *yamlParser) value(l yamlLine, inline string, inMapping bool) (any, error) {
+	if inline != "" {
+		v, err := inlineValue(inline)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.num, err)
+		}
+		return v, nil
+	}
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > l.indent || inMapping && next.indent == l.indent && isSeqItem(next.text) {
+			return p.block(next.indent)
+		}
+	}
+	return nil, nil
+}
+
+// inlineValue parses a value written on the line of its key: a flow
+// collection, a quoted scalar or a plain scalar running to the end of line.
+func inlineValue(s string) (any, error) {
+	f := &yamlFlow{s: s}
+	var v any
+	var err error
+	switch s[0] {
+	case '[', '{', '"', '\'':
+		v, err = f.value()
+	case '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, fmt.Errorf("unsupported YAML syntax %q", s[:1])
+	default:
+		return s, nil
+	}
+	if err == nil && f.i < len(f.s) {
+		err = fmt.Errorf("unexpected %q", f.s[f.i:])
+	}
+	return v, err
+}
+
+// splitYAMLKey splits "key: value" into the key and the inline value.
+func splitYAMLKey(text string) (key, rest string, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		f := &yamlFlow{s: text}
+		k, err := f.quoted()
+		if err != nil {
+			return "", "", err
+		}
+		after := f.s[f.i:]
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", fmt.Errorf("expected \":\" after key %q", k)
+		}
+		return k, strings.TrimSpace(after[1:]), nil
+	}
+	if strings.HasSuffix(text, ":") && !strings.Contains(text, ": ") {
+		return checkYAMLKey(text[:len(text)-1], "")
+	}
+	k, v, ok := strings.Cut(text, ": ")
+	if !ok {
+		return "", "", fmt.Errorf("expected \"key: value\", got %q", text)
+	}
+	return checkYAMLKey(strings.TrimSpace(k), strings.TrimSpace(v))
+}
+
+func checkYAMLKey(key, rest string) (string, string, error) {
+	if key == "" || strings.ContainsAny(key[:1], "[]{},&*!|>%@`?") {
+		return "", "", fmt.Errorf("unsupported key %q", key)
+	}
+	return key, rest, nil
+}
+
+// yamlFlow parses flow values: [a, b], {k: v} and scalars.
+type yamlFlow struct {
+	s string
+	i int
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+}
+
+func (f *yamlFlow) value() (any, error) {
+	f.skipSpace()
+	if f.i >= len(f.s) {
+		return nil, fmt.Errorf("missing value")
+	}
+	switch c := f.s[f.i]; c {
+	case '[':
+		f.i++
+		s := []any{}
+		err := f.items(']', func() error {
+			v, err := f.value()
+			s = append(s, v)
+			return err
+		})
+		return s, err
+	case '{':
+		f.i++
+		m := map[string]any{}
+		err := f.items('}', func() error {
+			k, err := f.scalar(true)
+			if err != nil {
+				return err
+			}
+			f.skipSpace()
+			if f.i >= len(f.s) || f.s[f.i] != ':' {
+				return fmt.Errorf("expected \":\" after key %q", k)
+			}
+			f.i++
+			v, err := f.value()
+			m[k] = v
+			return err
+		})
+		return m, err
+	case '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, fmt.Errorf("unsupported YAML syntax %q", string(c))
+	}
+	return f.scalar(false)
+}
+
+// items parses comma-separated entries up to end.
+func (f *yamlFlow) items(end byte, entry func() error) error {
+	f.skipSpace()
+	if f.i < len(f.s) && f.s[f.i] == end {
+		f.i++
+		return nil
+	}
+	for {
+		if err := entry(); err != nil {
+			return err
+		}
+		f.skipSpace()
+		if f.i >= len(f.s) {
+			return fmt.Errorf("missing %q", string(end))
+		}
+		switch f.s[f.i] {
+		case ',':
+			f.i++
+		case end:
+			f.i++
+			return nil
+		default:
+			return fmt.Errorf("unexpected %q", f.s[f.i:])
+		}
+	}
+}
+
+// scalar parses a quoted or plain scalar. Plain scalars end at a comma or a
+// closing bracket, and in keys at a colon.
+func (f *yamlFlow) scalar(key bool) (string, error) {
+	f.skipSpace()
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		return f.quoted()
+	}
+	start := f.i
+	for f.i < len(f.s) {
+		c := f.s[f.i]
+		if c == ',' || c == ']' || c == '}' || key && c == ':' {
+			break
+		}
+		f.i++
+	}
+	v := strings.TrimSpace(f.s[start:f.i])
+	if v == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	return v, nil
+}
+
+func (f *yamlFlow) quoted() (string, error) {
+	q := f.s[f.i]
+	for j := f.i + 1; j < len(f.s); j++ {
+		switch {
+		case q == '"' && f.s[j] == '\\':
+			j++
+		case q == '\'' && f.s[j] == '\'' && j+1 < len(f.s) && f.s[j+1] == '\'':
+			j++
+		case f.s[j] == q:
+			raw := f.s[f.i : j+1]
+			f.i = j + 1
+			if q == '\'' {
+				return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+			}
+			v, err := strconv.Unquote(raw)
+			if err != nil {
+				return "", fmt.Errorf("invalid quoted string %s", raw)
+			}
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted string %s", f.s[f.i:])
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+// LoadWorkspace reads the workspace config from goDir. A missing file yields
+// usecase.DefaultWorkspace so single-architecture checkouts keep working.
+func LoadWorkspace(goDir string) (usecase.Workspace, error) {
+	path := filepath.Join(goDir, usecase.WorkspaceFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return usecase.DefaultWorkspace(), nil
+		}
+		return usecase.Workspace{}, err
+	}
+
+	var ws usecase.Workspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return usecase.Workspace{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ws.Validate(); err != nil {
+		return usecase.Workspace{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return ws, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+	"github.com/sokoide/advent-of-calm-2025/internal/usecase"
+)
+
+type stubBuilder struct{}
+
+func (stubBuilder) Build() *domain.Architecture {
+	return domain.NewArchitecture("stub", "Stub", "desc")
+}
+
+func writeWorkspace(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, usecase.WorkspaceFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	usecase.RegisterBuilder("test-payments", stubBuilder{})
+	t.Cleanup(func() { usecase.UnregisterBuilder("test-payments") })
+
+	t.Run("should fall back to the default workspace", func(t *testing.T) {
+		ws, err := LoadWorkspace(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		arch, err := ws.Select("")
+		if err != nil || arch.ID != usecase.DefaultArchitectureID {
+			t.Errorf("expected default architecture, got %v (%v)", arch, err)
+		}
+	})
+
+	t.Run("should select architectures by ID", func(t *testing.T) {
+		dir := writeWorkspace(t, `{"default": "test-payments", "architectures": [
+			{"id": "ecommerce", "dsl": "internal/usecase/ecommerce_architecture.go"},
+			{"id": "test-payments", "dsl": "internal/usecase/payments.go", "layout": "payments"}]}`)
+		ws, err := LoadWorkspace(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if arch, _ := ws.Select(""); arch.Layout != "payments" {
+			t.Errorf("expected default payments architecture, got %v", arch)
+		}
+		if arch, _ := ws.Select("ecommerce"); arch.DSL != "internal/usecase/ecommerce_architecture.go" {
+			t.Errorf("unexpected ecommerce entry %v", arch)
+		}
+		if _, err := ws.Select("missing"); err == nil || !strings.Contains(err.Error(), "test-payments") {
+			t.Errorf("expected error listing available IDs, got %v", err)
+		}
+	})
+
+	t.Run("should reject invalid workspaces", func(t *testing.T) {
+		cases := map[string]string{
+			"unregistered": `{"architectures": [{"id": "ghost", "dsl": "ghost.go"}]}`,
+			"duplicate":    `{"architectures": [{"id": "ecommerce", "dsl": "a.go"}, {"id": "ecommerce", "dsl": "b.go"}]}`,
+			"missing dsl":  `{"architectures": [{"id": "ecommerce"}]}`,
+			"bad default":  `{"default": "x", "architectures": [{"id": "ecommerce", "dsl": "a.go"}]}`,
+		}
+		for name, content := range cases {
+			if _, err := LoadWorkspace(writeWorkspace(t, content)); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+func TestBuilderRegistry(t *testing.T) {
+	usecase.RegisterBuilder("test-registry", stubBuilder{})
+	t.Cleanup(func() { usecase.UnregisterBuilder("test-registry") })
+
+	b, err := usecase.LookupBuilder("test-registry")
+	if err != nil || b.Build().UniqueID != "stub" {
+		t.Fatalf("expected registered builder, got %v (%v)", b, err)
+	}
+	if _, err := usecase.LookupBuilder("nope"); err == nil || !strings.Contains(err.Error(), "ecommerce") {
+		t.Errorf("expected error listing available IDs, got %v", err)
+	}
+}
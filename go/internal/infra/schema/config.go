@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadConfig reads the control configuration at a config-url, resolved like
+// schemas through url-mapping.json or as a file: URL. It implements
+// domain.ConfigLoader. Config files must be JSON, which YAML parsers accept
+// too, so a mapped URL may keep its .yaml name.
+func (v *Validator) LoadConfig(rawURL string) (any, error) {
+	if v == nil {
+		return nil, fmt.Errorf("config %s: no url mapping", rawURL)
+	}
+	path, err := v.mapping.ResolveFile(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", rawURL, err)
+	}
+	var config any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("config %s: %w", rawURL, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidator_LoadConfig(t *testing.T) {
+	t.Run("should load mapped configs that conform to their requirement", func(t *testing.T) {
+		// The repository root url-mapping.json maps control requirements and
+		// configs to controls/.
+		m, err := FindURLMapping(".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := NewValidator(m)
+		config, err := v.LoadConfig("https://configs.example.com/security/tls-config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		violations, err := v.ValidateURL("https://internal-policy.example.com/security/tls-1-3-minimum", config)
+		if err != nil || len(violations) != 0 {
+			t.Errorf("expected a conforming config, got %v (%v)", violations, err)
+		}
+	})
+
+	t.Run("should load file URLs and reject other formats", func(t *testing.T) {
+		dir := t.TempDir()
+		good := filepath.Join(dir, "good.json")
+		bad := filepath.Join(dir, "bad.yaml")
+		if err := os.WriteFile(good, []byte(`{"rto-minutes": 15}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(bad, []byte("rto-minutes: 15\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		v := NewValidator(NewURLMapping(dir, map[string]string{"https://configs.example.com/dr.yaml": "bad.yaml"}))
+
+		config, err := v.LoadConfig("file://" + filepath.ToSlash(good))
+		if m, ok := config.(map[string]any); err != nil || !ok || m["rto-minutes"] != 15.0 {
+			t.Errorf("unexpected config %v (%v)", config, err)
+		}
+		if _, err := v.LoadConfig("https://configs.example.com/dr.yaml"); err == nil ||
+			!strings.Contains(err.Error(), "config https://configs.example.com/dr.yaml") {
+			t.Errorf("expected a parse error, got %v", err)
+		}
+		if _, err := v.LoadConfig("https://configs.example.com/unknown.yaml"); err == nil {
+			t.Errorf("expected an unmapped URL to fail")
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// URLMappingFileName is the file mapping schema URLs to local files, as used by
+// `calm validate -u`.
+const URLMappingFileName = "url-mapping.json"
+
+// URLMapping resolves schema URLs to local files so validation works offline.
+type URLMapping struct {
+	baseDir string
+	urls    map[string]string
+}
+
+// NewURLMapping returns a mapping whose relative paths are resolved against baseDir.
+func NewURLMapping(baseDir string, urls map[string]string) *URLMapping {
+	return &URLMapping{baseDir: baseDir, urls: urls}
+}
+
+// LoadURLMapping reads a url-mapping.json file. Paths in it are relative to the
+// directory containing the file.
+func LoadURLMapping(path string) (*URLMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var urls map[string]string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	abs, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return NewURLMapping(abs, urls), nil
+}
+
+// FindURLMapping looks for url-mapping.json in dir and its parents. When none
+// exists an empty mapping is returned, so every URL is reported as unresolved.
+func FindURLMapping(dir string) (*URLMapping, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, URLMappingFileName)
+		if _, err := os.Stat(path); err == nil {
+			return LoadURLMapping(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return NewURLMapping(dir, nil), nil
+		}
+		dir = parent
+	}
+}
+
+// Resolve returns the local file for url.
+func (m *URLMapping) Resolve(url string) (string, error) {
+	if m == nil {
+		return "", fmt.Errorf("%s is not in %s", url, URLMappingFileName)
+	}
+	path, ok := m.urls[url]
+	if !ok {
+		return "", fmt.Errorf("%s is not in %s", url, URLMappingFileName)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.baseDir, path)
+	}
+	return path, nil
+}
+
+// ResolveFile returns the local file for rawURL: the file the mapping names,
+// or the path of a file: URL.
+func (m *URLMapping) ResolveFile(rawURL string) (string, error) {
+	path, err := m.Resolve(rawURL)
+	if err == nil {
+		return path, nil
+	}
+	u, parseErr := url.Parse(rawURL)
+	if parseErr != nil || u.Scheme != "file" {
+		return "", err
+	}
+	return filepath.FromSlash(u.Path), nil
+}
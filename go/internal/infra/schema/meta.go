@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// calmSchemaBase is where the CALM 1.1 meta-schemas are published.
+const calmSchemaBase = "https://calm.finos.org/release/1.1/meta/"
+
+// CALMSchemaURL is the meta-schema NewArchitecture stamps into $schema.
+const CALMSchemaURL = calmSchemaBase + "calm.json"
+
+// StructuralSchemaURL is the $id of the embedded structural subset of the
+// CALM 1.1 meta-schema, which ValidateDocument falls back to when the
+// upstream meta-schemas are neither vendored nor mapped. It is a
+// reconstruction, not the upstream file, so it is not served as CALMSchemaURL.
+const StructuralSchemaURL = structuralSchemaBase + "calm.json"
+
+// structuralSchemaBase is where the embedded schemas are served from.
+const structuralSchemaBase = "embedded:///calm-1.1-structural/"
+
+//go:embed meta/calm-1.1-structural/*.json
+var metaSchemas embed.FS
+
+//go:embed meta/upstream
+var upstreamFS embed.FS
+
+// vendoredSchemas holds the upstream meta-schemas that
+// scripts/vendor-calm-schemas.sh copies into meta/upstream, served under
+// their published URLs by the directories of vendoredBases.
+var vendoredSchemas fs.FS = mustSub(upstreamFS, "meta/upstream")
+
+// vendoredBases maps published schema URLs to directories of vendoredSchemas.
+// calm.json extends the JSON Schema 2020-12 meta-schema, so that is vendored
+// too.
+var vendoredBases = map[string]string{
+	calmSchemaBase:                           "calm-1.1",
+	"https://json-schema.org/draft/2020-12/": "json-schema-2020-12",
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// embeddedSchema returns the embedded schema with the $id url: the structural
+// subset, or a vendored upstream meta-schema.
+func embeddedSchema(url string) ([]byte, bool) {
+	if name, ok := strings.CutPrefix(url, structuralSchemaBase); ok {
+		if strings.Contains(name, "/") {
+			return nil, false
+		}
+		data, err := metaSchemas.ReadFile(path.Join("meta", "calm-1.1-structural", name))
+		return data, err == nil
+	}
+	for base, dir := range vendoredBases {
+		if name, ok := strings.CutPrefix(url, base); ok && fs.ValidPath(name) {
+			data, err := fs.ReadFile(vendoredSchemas, path.Join(dir, name))
+			return data, err == nil
+		}
+	}
+	return nil, false
+}
+
+// DocumentSchema returns the schema ValidateDocument checks against and how to
+// describe it in reports: the CALM 1.1 meta-schema when url-mapping.json maps
+// CALMSchemaURL or it is vendored, otherwise the embedded structural subset.
+func (v *Validator) DocumentSchema() (url, desc string) {
+	if v != nil {
+		if _, err := v.mapping.Resolve(CALMSchemaURL); err == nil {
+			return CALMSchemaURL, "CALM 1.1 meta-schema"
+		}
+	}
+	if _, ok := embeddedSchema(CALMSchemaURL); ok {
+		return CALMSchemaURL, "vendored CALM 1.1 meta-schema"
+	}
+	return StructuralSchemaURL, "embedded CALM 1.1 structural subset"
+}
+
+// ValidateDocument checks a rendered CALM JSON document against the schema
+// named by DocumentSchema. A pattern named by the document's $schema is not
+// applied; use ValidateFile for pattern conformance. Violations under nodes,
+// relationships or flows are attributed to that element, and their messages
+// say which schema found them.
+func (v *Validator) ValidateDocument(data []byte) ([]domain.ValidationError, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("document is not a JSON object: %w", err)
+	}
+	schemaURL, desc := v.DocumentSchema()
+	s, err := v.load(schemaURL)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := v.validateDoc(s, schemaURL, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	info, _ := domain.LookupRuleInfo(domain.SchemaConformanceRule)
+	errors := make([]domain.ValidationError, 0, len(violations))
+	for _, viol := range violations {
+		pointer := viol.Pointer
+		if pointer == "" {
+			pointer = "/"
+		}
+		id, kind := elementAt(doc, viol.Pointer)
+		errors = append(errors, domain.ValidationError{
+			Rule:     domain.SchemaConformanceRule,
+			Code:     info.Code,
+			NodeID:   id,
+			Kind:     kind,
+			Message:  fmt.Sprintf("%s: %s (%s)", pointer, viol.Message, desc),
+			Severity: info.Severity,
+		})
+	}
+	return errors, nil
+}
+
+// elementAt returns the unique-id and kind of the node, relationship or flow
+// a JSON pointer points into.
+func elementAt(doc map[string]any, pointer string) (string, domain.ElementKind) {
+	kinds := map[string]domain.ElementKind{
+		"nodes": domain.KindNode, "relationships": domain.KindRelationship, "flows": domain.KindFlow,
+	}
+	parts := strings.SplitN(strings.TrimPrefix(pointer, "/"), "/", 3)
+	if len(parts) < 2 {
+		return "", ""
+	}
+	kind, ok := kinds[parts[0]]
+	if !ok {
+		return "", ""
+	}
+	elem, err := resolvePointer(doc, "/"+parts[0]+"/"+parts[1])
+	if err != nil {
+		return "", ""
+	}
+	obj, _ := elem.(map[string]any)
+	id, _ := obj["unique-id"].(string)
+	if id == "" {
+		return "", ""
+	}
+	return id, kind
+}
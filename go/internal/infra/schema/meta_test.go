@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestValidator_ValidateDocument(t *testing.T) {
+	v := NewValidator(NewURLMapping(t.TempDir(), nil))
+
+	t.Run("should accept a conforming document", func(t *testing.T) {
+		doc := `{"$schema": "` + CALMSchemaURL + `", "nodes": [
+			{"unique-id": "api", "node-type": "service", "name": "API", "description": "d",
+			 "controls": {"security": {"description": "d", "requirements": [{"requirement-url": "u", "config": {}}]}}}
+		], "relationships": [], "flows": [{"unique-id": "f", "name": "F", "description": "d",
+			"transitions": [{"relationship-unique-id": "r", "sequence-number": 1, "description": "d"}]}]}`
+		errs, err := v.ValidateDocument([]byte(doc))
+		if err != nil || len(errs) != 0 {
+			t.Errorf("expected no errors, got %v (%v)", errs, err)
+		}
+	})
+
+	t.Run("should attribute violations to elements", func(t *testing.T) {
+		doc := `{"nodes": [{"unique-id": "api", "node-type": "service", "name": "API"}],
+			"relationships": [{"unique-id": "r", "protocol": "SMTP",
+				"relationship-type": {"connects": {"source": {"node": "api"}, "destination": {"node": "db"}}}}],
+			"flows": [{"unique-id": "f", "name": "F", "description": "d", "transitions": []}],
+			"controls": {"bad key": {"description": "d", "requirements": []}}}`
+		errs, err := v.ValidateDocument([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range errs {
+			if e.Rule != domain.SchemaConformanceRule || e.Code != "CALM015" {
+				t.Errorf("unexpected rule on %v", e)
+			}
+			got = append(got, string(e.Kind)+" "+e.NodeID+" "+e.Message)
+		}
+		want := []string{
+			`  /controls: unknown property "bad key"`,
+			`flow f /flows/0/transitions: must have at least 1 items, got 0`,
+			`node api /nodes/0: missing required property "description"`,
+			`relationship r /relationships/0/protocol: must be one of`,
+		}
+		joined := strings.Join(got, "\n")
+		for _, w := range want {
+			if !strings.Contains(joined, w) {
+				t.Errorf("expected %q in\n%s", w, joined)
+			}
+		}
+		if len(errs) != len(want) {
+			t.Errorf("expected %d errors, got\n%s", len(want), joined)
+		}
+	})
+
+	t.Run("should serve the embedded subset under its own IDs only", func(t *testing.T) {
+		withVendored(t, fstest.MapFS{})
+		for _, name := range []string{"calm", "core", "interface", "control", "flow"} {
+			if _, ok := embeddedSchema("embedded:///calm-1.1-structural/" + name + ".json"); !ok {
+				t.Errorf("%s.json is not embedded", name)
+			}
+			if _, ok := embeddedSchema("https://calm.finos.org/release/1.1/meta/" + name + ".json"); ok {
+				t.Errorf("expected %s.json not to be served under the upstream URL", name)
+			}
+		}
+		if url, desc := v.DocumentSchema(); url != StructuralSchemaURL || !strings.Contains(desc, "structural subset") {
+			t.Errorf("expected the embedded subset, got %s (%s)", url, desc)
+		}
+	})
+
+	t.Run("should use the meta-schema mapped in url-mapping.json", func(t *testing.T) {
+		dir := t.TempDir()
+		calm := `{"type": "object", "required": ["mapped-only"]}`
+		if err := os.WriteFile(filepath.Join(dir, "calm.json"), []byte(calm), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mapped := NewValidator(NewURLMapping(dir, map[string]string{CALMSchemaURL: "calm.json"}))
+		if url, _ := mapped.DocumentSchema(); url != CALMSchemaURL {
+			t.Errorf("expected %s, got %s", CALMSchemaURL, url)
+		}
+		errs, err := mapped.ValidateDocument([]byte(`{"nodes": []}`))
+		if err != nil || len(errs) != 1 || !strings.Contains(errs[0].Message, `"mapped-only" (CALM 1.1 meta-schema)`) {
+			t.Errorf("expected the mapped schema to be applied, got %v (%v)", errs, err)
+		}
+	})
+
+	t.Run("should prefer the vendored meta-schemas to the subset", func(t *testing.T) {
+		withVendored(t, fstest.MapFS{
+			"calm-1.1/calm.json": {Data: []byte(`{"$id": "` + CALMSchemaURL + `", "allOf": [{"$ref": "core.json"}]}`)},
+			"calm-1.1/core.json": {Data: []byte(`{"type": "object", "required": ["vendored-only"]}`)},
+		})
+		if url, _ := v.DocumentSchema(); url != CALMSchemaURL {
+			t.Errorf("expected %s, got %s", CALMSchemaURL, url)
+		}
+		errs, err := NewValidator(NewURLMapping(t.TempDir(), nil)).ValidateDocument([]byte(`{"nodes": []}`))
+		if err != nil || len(errs) != 1 || !strings.Contains(errs[0].Message, `"vendored-only" (vendored CALM 1.1 meta-schema)`) {
+			t.Errorf("expected the vendored schema to be applied, got %v (%v)", errs, err)
+		}
+	})
+}
+
+// withVendored replaces the vendored meta-schemas for the rest of the test.
+func withVendored(t *testing.T, fsys fs.FS) {
+	t.Helper()
+	saved := vendoredSchemas
+	vendoredSchemas = fsys
+	t.Cleanup(func() { vendoredSchemas = saved })
+}
@@ -0,0 +1,547 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Validator checks documents against JSON schemas resolved through a
+// URLMapping. It implements domain.SchemaValidator and caches loaded schemas.
+//
+// Supported keywords: type, enum, const, properties, patternProperties,
+// required, additionalProperties, items, prefixItems, minItems, maxItems, minimum,
+// maximum, minLength, maxLength, pattern, allOf, anyOf, oneOf, if/then/else
+// and $ref. References to other documents are resolved through the mapping,
+// relative to the $id of the referring schema; fragments are JSON pointers.
+// Other keywords are ignored. A structural subset of the CALM 1.1
+// meta-schema is embedded, see ValidateDocument.
+type Validator struct {
+	mapping *URLMapping
+
+	mu      sync.Mutex
+	schemas map[string]any
+}
+
+// Violation is a place where a document does not conform to a schema.
+type Violation struct {
+	Pointer string // JSON pointer to the offending value, "" for the document
+	Path    string // the same location in dotted form, e.g. "nodes[0].name"
+	Message string
+}
+
+// String formats the violation with its dotted path.
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// NewValidator returns a validator that resolves schemas through m.
+func NewValidator(m *URLMapping) *Validator {
+	return &Validator{mapping: m, schemas: make(map[string]any)}
+}
+
+// DefaultValidator uses the url-mapping.json found from the working directory upwards.
+func DefaultValidator() *Validator {
+	m, err := FindURLMapping(".")
+	if err != nil {
+		m = nil
+	}
+	return NewValidator(m)
+}
+
+// ValidateURL validates doc against the schema at url. doc may be any
+// JSON-encodable value; it is compared in its JSON form.
+func (v *Validator) ValidateURL(url string, doc any) ([]string, error) {
+	s, err := v.load(url)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := v.validateDoc(s, url, doc)
+	return messages(violations), err
+}
+
+// ValidateFile validates doc against the schema, such as a CALM pattern, in
+// the file at path. References are resolved relative to the schema's $id, or
+// to the file itself when it has none.
+func (v *Validator) ValidateFile(path string, doc any) ([]Violation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s any
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	base := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	if obj, ok := s.(map[string]any); ok {
+		if id, ok := obj["$id"].(string); ok && id != "" {
+			base = id
+		}
+	}
+	v.mu.Lock()
+	v.schemas[base] = s
+	v.mu.Unlock()
+	return v.validateDoc(s, base, doc)
+}
+
+// Validate validates doc against an in-memory schema; both may be any
+// JSON-encodable value. References to other documents cannot be resolved.
+func Validate(schema, doc any) ([]string, error) {
+	s, err := toJSONValue(schema)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := (*Validator)(nil).validateDoc(s, "", doc)
+	return messages(violations), err
+}
+
+func (v *Validator) validateDoc(schema any, base string, doc any) ([]Violation, error) {
+	instance, err := toJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	val := &validation{v: v}
+	val.validate(schema, scope{base: base, root: schema}, instance, location{})
+	return val.violations, nil
+}
+
+func messages(violations []Violation) []string {
+	if violations == nil {
+		return nil
+	}
+	out := make([]string, len(violations))
+	for i, v := range violations {
+		out[i] = v.String()
+	}
+	return out
+}
+
+func (v *Validator) load(rawURL string) (any, error) {
+	if v == nil {
+		return nil, fmt.Errorf("schema %s: no url mapping", rawURL)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.schemas[rawURL]; ok {
+		return s, nil
+	}
+	data, err := v.read(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var s any
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", rawURL, err)
+	}
+	v.schemas[rawURL] = s
+	return s, nil
+}
+
+// read returns the schema at rawURL: the file url-mapping.json maps it to, an
+// embedded schema, or a file: URL.
+func (v *Validator) read(rawURL string) ([]byte, error) {
+	path, err := v.mapping.ResolveFile(rawURL)
+	if err != nil {
+		if data, ok := embeddedSchema(rawURL); ok {
+			return data, nil
+		}
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", rawURL, err)
+	}
+	return data, nil
+}
+
+// toJSONValue converts a Go value into its generic JSON representation.
+func toJSONValue(doc any) (any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// maxRefDepth bounds $ref chains so that recursive schemas cannot loop.
+const maxRefDepth = 64
+
+// validation collects the violations of one document.
+type validation struct {
+	v          *Validator
+	violations []Violation
+	refDepth   int
+}
+
+// scope is the schema document a subschema belongs to, against which $ref is
+// resolved.
+type scope struct {
+	base string
+	root any
+}
+
+// location is where a value is in the validated document.
+type location struct {
+	path    string
+	pointer string
+}
+
+func (l location) key(k string) location {
+	escaped := strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+	return location{path: joinPath(l.path, k), pointer: l.pointer + "/" + escaped}
+}
+
+func (l location) index(i int) location {
+	return location{path: fmt.Sprintf("%s[%d]", l.path, i), pointer: fmt.Sprintf("%s/%d", l.pointer, i)}
+}
+
+func (val *validation) report(at location, msg string) {
+	val.violations = append(val.violations, Violation{Pointer: at.pointer, Path: at.path, Message: msg})
+}
+
+// passes reports whether instance conforms to schema, without recording
+// violations.
+func (val *validation) passes(schema any, sc scope, instance any, at location) bool {
+	sub := &validation{v: val.v, refDepth: val.refDepth}
+	sub.validate(schema, sc, instance, at)
+	return len(sub.violations) == 0
+}
+
+// validate records a violation for every keyword of schema that instance fails.
+func (val *validation) validate(schema any, sc scope, instance any, at location) {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if b, isBool := schema.(bool); isBool && !b {
+			val.report(at, "is not allowed")
+		}
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		val.validateRef(ref, sc, instance, at)
+	}
+	if t, ok := s["type"]; ok && !matchesType(t, instance) {
+		val.report(at, fmt.Sprintf("must be of type %s, got %s", typeList(t), jsonType(instance)))
+		return
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, instance) {
+		b, _ := json.Marshal(c)
+		val.report(at, fmt.Sprintf("must be %s", b))
+	}
+	if enum, ok := s["enum"].([]any); ok && !inEnum(enum, instance) {
+		val.report(at, fmt.Sprintf("must be one of %s", formatEnum(enum)))
+	}
+
+	switch v := instance.(type) {
+	case map[string]any:
+		val.validateObject(s, sc, v, at)
+	case []any:
+		val.validateArray(s, sc, v, at)
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := s["minLength"].(float64); ok && length < min {
+			val.report(at, fmt.Sprintf("must be at least %v characters", min))
+		}
+		if max, ok := s["maxLength"].(float64); ok && length > max {
+			val.report(at, fmt.Sprintf("must be at most %v characters", max))
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				val.report(at, fmt.Sprintf("schema pattern %q is invalid: %v", pattern, err))
+			} else if !re.MatchString(v) {
+				val.report(at, fmt.Sprintf("must match pattern %q", pattern))
+			}
+		}
+	case float64:
+		if min, ok := s["minimum"].(float64); ok && v < min {
+			val.report(at, fmt.Sprintf("must be >= %v", min))
+		}
+		if max, ok := s["maximum"].(float64); ok && v > max {
+			val.report(at, fmt.Sprintf("must be <= %v", max))
+		}
+	}
+
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			val.validate(sub, sc, instance, at)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if val.passes(sub, sc, instance, at) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			val.report(at, "must match at least one schema in anyOf")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if val.passes(sub, sc, instance, at) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			val.report(at, fmt.Sprintf("must match exactly one schema in oneOf, matched %d", matched))
+		}
+	}
+	if cond, ok := s["if"]; ok {
+		branch := "then"
+		if !val.passes(cond, sc, instance, at) {
+			branch = "else"
+		}
+		if sub, ok := s[branch]; ok {
+			val.validate(sub, sc, instance, at)
+		}
+	}
+}
+
+func (val *validation) validateObject(s map[string]any, sc scope, obj map[string]any, at location) {
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				val.report(at, fmt.Sprintf("missing required property %q", name))
+			}
+		}
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	patterns, _ := s["patternProperties"].(map[string]any)
+	for _, k := range keys {
+		matched := false
+		if sub, ok := props[k]; ok {
+			val.validate(sub, sc, obj[k], at.key(k))
+			matched = true
+		}
+		for pattern, sub := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				val.report(at, fmt.Sprintf("schema pattern %q is invalid: %v", pattern, err))
+				continue
+			}
+			if re.MatchString(k) {
+				val.validate(sub, sc, obj[k], at.key(k))
+				matched = true
+			}
+		}
+		if matched {
+			continue
+		}
+		switch extra := s["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				val.report(at, fmt.Sprintf("unknown property %q", k))
+			}
+		case map[string]any:
+			val.validate(extra, sc, obj[k], at.key(k))
+		}
+	}
+}
+
+// validateArray applies prefixItems to the leading items and items to the
+// rest, as in draft 2020-12.
+func (val *validation) validateArray(s map[string]any, sc scope, list []any, at location) {
+	if min, ok := s["minItems"].(float64); ok && float64(len(list)) < min {
+		val.report(at, fmt.Sprintf("must have at least %v items, got %d", min, len(list)))
+	}
+	if max, ok := s["maxItems"].(float64); ok && float64(len(list)) > max {
+		val.report(at, fmt.Sprintf("must have at most %v items, got %d", max, len(list)))
+	}
+	prefix, _ := s["prefixItems"].([]any)
+	for i, item := range list {
+		if i < len(prefix) {
+			val.validate(prefix[i], sc, item, at.index(i))
+		} else if items, ok := s["items"]; ok {
+			val.validate(items, sc, item, at.index(i))
+		}
+	}
+}
+
+// validateRef validates instance against the schema ref points to.
+func (val *validation) validateRef(ref string, sc scope, instance any, at location) {
+	target, targetScope, err := val.resolveRef(ref, sc)
+	if err != nil {
+		val.report(at, fmt.Sprintf("cannot resolve $ref %q: %v", ref, err))
+		return
+	}
+	if val.refDepth >= maxRefDepth {
+		val.report(at, fmt.Sprintf("$ref %q nests more than %d levels", ref, maxRefDepth))
+		return
+	}
+	val.refDepth++
+	val.validate(target, targetScope, instance, at)
+	val.refDepth--
+}
+
+func (val *validation) resolveRef(ref string, sc scope) (any, scope, error) {
+	docURL, fragment, _ := strings.Cut(ref, "#")
+	target := sc
+	if docURL != "" {
+		u, err := url.Parse(ref)
+		if err != nil {
+			return nil, sc, err
+		}
+		if base, err := url.Parse(sc.base); err == nil && sc.base != "" {
+			u = base.ResolveReference(u)
+		}
+		u.Fragment = ""
+		doc, err := val.v.load(u.String())
+		if err != nil {
+			return nil, sc, err
+		}
+		target = scope{base: u.String(), root: doc}
+	}
+	node, err := resolvePointer(target.root, fragment)
+	return node, target, err
+}
+
+// resolvePointer returns the value at a JSON pointer such as "/$defs/node".
+func resolvePointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported fragment %q (want a JSON pointer)", pointer)
+	}
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			node = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("%q not found", pointer)
+		}
+	}
+	return node, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func matchesType(t, instance any) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, instance)
+	case []any:
+		for _, name := range tt {
+			if s, ok := name.(string); ok && isType(s, instance) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, instance any) bool {
+	switch name {
+	case "integer":
+		f, ok := instance.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := instance.(float64)
+		return ok
+	default:
+		return jsonType(instance) == name
+	}
+}
+
+func jsonType(instance any) string {
+	switch instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+func typeList(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func inEnum(enum []any, instance any) bool {
+	for _, e := range enum {
+		if jsonEqual(e, instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two JSON values; object keys are marshalled sorted.
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		b, _ := json.Marshal(e)
+		parts[i] = string(b)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const restAPIURL = "https://example.com/patterns/rest-api-interface.json"
+
+type restAPIConfig struct {
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	BasePath       string `json:"basePath"`
+	Authentication string `json:"authentication"`
+}
+
+func TestValidator_RepositoryMapping(t *testing.T) {
+	// The repository root url-mapping.json maps restAPIURL to patterns/.
+	m, err := FindURLMapping(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(m)
+
+	t.Run("should accept a conforming typed config", func(t *testing.T) {
+		cfg := restAPIConfig{Host: "api.example.com", Port: 443, BasePath: "/api/v1", Authentication: "OAuth2"}
+		violations, err := v.ValidateURL(restAPIURL, cfg)
+		if err != nil || len(violations) != 0 {
+			t.Errorf("expected no violations, got %v (%v)", violations, err)
+		}
+	})
+
+	t.Run("should report schema violations", func(t *testing.T) {
+		cfg := map[string]any{"host": "api", "port": 70000, "basePath": "api", "authentication": "Basic", "x": 1}
+		violations, err := v.ValidateURL(restAPIURL, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			`authentication: must be one of ["OAuth2", "API-Key", "None"]`,
+			`basePath: must match pattern "^/.*"`,
+			"port: must be <= 65535",
+			`unknown property "x"`,
+		}
+		if !reflect.DeepEqual(violations, want) {
+			t.Errorf("unexpected violations:\n got %q\nwant %q", violations, want)
+		}
+	})
+
+	t.Run("should report missing required properties and types", func(t *testing.T) {
+		violations, _ := v.ValidateURL(restAPIURL, map[string]any{"host": "api", "port": "443"})
+		joined := strings.Join(violations, "\n")
+		for _, want := range []string{`missing required property "basePath"`, "port: must be of type integer"} {
+			if !strings.Contains(joined, want) {
+				t.Errorf("expected %q in %v", want, violations)
+			}
+		}
+	})
+
+	t.Run("should fail on unmapped URLs", func(t *testing.T) {
+		if _, err := v.ValidateURL("https://example.com/unknown.json", map[string]any{}); err == nil {
+			t.Errorf("expected error for unmapped URL")
+		}
+	})
+}
+
+func TestLoadURLMapping_RelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "schemas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	schemaJSON := `{"type": "object", "properties": {"items": {"type": "array", "items": {"type": "string"}}}}`
+	if err := os.WriteFile(filepath.Join(dir, "schemas", "list.json"), []byte(schemaJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mapping := `{"https://example.com/list.json": "schemas/list.json"}`
+	if err := os.WriteFile(filepath.Join(dir, URLMappingFileName), []byte(mapping), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := FindURLMapping(filepath.Join(dir, "schemas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]any{"items": []any{"a", 1}}
+	violations, err := NewValidator(m).ValidateURL("https://example.com/list.json", doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(violations, []string{"items[1]: must be of type string, got number"}) {
+		t.Errorf("unexpected violations %v", violations)
+	}
+}
+
+func TestValidator_ValidateFile(t *testing.T) {
+	m, err := FindURLMapping(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(m)
+	patterns := filepath.Join("..", "..", "..", "..", "patterns")
+
+	t.Run("should check const, prefixItems and minItems with JSON pointers", func(t *testing.T) {
+		doc := map[string]any{
+			"nodes": []any{
+				map[string]any{"unique-id": "web-frontend", "node-type": "webclient", "name": "Web Frontend"},
+				map[string]any{"unique-id": "api", "node-type": "service", "name": "API Service"},
+			},
+			"relationships": []any{},
+		}
+		violations, err := v.ValidateFile(filepath.Join(patterns, "web-app-pattern.json"), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Violation{
+			{Pointer: "/nodes", Path: "nodes", Message: "must have at least 3 items, got 2"},
+			{Pointer: "/nodes/1/unique-id", Path: "nodes[1].unique-id", Message: `must be "api-service"`},
+			{Pointer: "/relationships", Path: "relationships", Message: "must have at least 2 items, got 0"},
+		}
+		if !reflect.DeepEqual(violations, want) {
+			t.Errorf("unexpected violations:\n got %v\nwant %v", violations, want)
+		}
+	})
+
+	t.Run("should resolve $ref through the url mapping", func(t *testing.T) {
+		doc := map[string]any{
+			"nodes":         []any{map[string]any{"owner": "team", "costCenter": "1234"}},
+			"relationships": []any{},
+		}
+		violations, err := v.ValidateFile(filepath.Join(patterns, "company-base-pattern.json"), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(violations) != 1 || violations[0].Pointer != "/nodes/0/costCenter" {
+			t.Errorf("unexpected violations %v", violations)
+		}
+	})
+}
+
+func TestValidate_LocalRefs(t *testing.T) {
+	schema := map[string]any{
+		"$defs":      map[string]any{"id": map[string]any{"type": "string", "minLength": 2}},
+		"properties": map[string]any{"a/b": map[string]any{"$ref": "#/$defs/id"}},
+		"oneOf":      []any{map[string]any{"required": []any{"a/b"}}, map[string]any{"required": []any{"c"}}},
+	}
+	violations, err := Validate(schema, map[string]any{"a/b": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(violations, []string{"a/b: must be at least 2 characters"}) {
+		t.Errorf("unexpected violations %v", violations)
+	}
+
+	violations, _ = Validate(map[string]any{"$ref": "#/missing"}, map[string]any{})
+	if len(violations) != 1 || !strings.Contains(violations[0], "cannot resolve $ref") {
+		t.Errorf("expected an unresolved reference, got %v", violations)
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// VocabularySchema exports metadata keys as a JSON Schema (2020-12) for CALM
+// nodes: metadata may only contain the declared keys, with their types and
+// allowed values, and keys restricted to some node types require a matching
+// node-type.
+func VocabularySchema(keys []domain.MetaKey) map[string]any {
+	props := make(map[string]any, len(keys))
+	var conditions []any
+	for _, k := range keys {
+		props[k.Name] = metaKeySchema(k)
+		if len(k.NodeTypes) == 0 {
+			continue
+		}
+		types := make([]any, len(k.NodeTypes))
+		for i, t := range k.NodeTypes {
+			types[i] = string(t)
+		}
+		conditions = append(conditions, map[string]any{
+			"if": map[string]any{
+				"required":   []any{"metadata"},
+				"properties": map[string]any{"metadata": map[string]any{"required": []any{k.Name}}},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{"node-type": map[string]any{"enum": types}},
+			},
+		})
+	}
+
+	s := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "CALM node metadata vocabulary",
+		"description": "Metadata keys declared with domain.RegisterMetaKey.",
+		"type":        "object",
+		"properties": map[string]any{
+			"metadata": map[string]any{
+				"type":                 "object",
+				"properties":           props,
+				"additionalProperties": false,
+			},
+		},
+	}
+	if len(conditions) > 0 {
+		s["allOf"] = conditions
+	}
+	return s
+}
+
+func metaKeySchema(k domain.MetaKey) map[string]any {
+	s := map[string]any{}
+	switch k.Type {
+	case domain.MetaTypeStringList:
+		s["type"] = "array"
+		s["items"] = map[string]any{"type": "string"}
+	case "":
+	default:
+		s["type"] = string(k.Type)
+	}
+	if k.Description != "" {
+		s["description"] = k.Description
+	}
+	if len(k.Enum) > 0 {
+		enum := make([]any, len(k.Enum))
+		for i, e := range k.Enum {
+			enum[i] = e
+		}
+		s["enum"] = enum
+	}
+	return s
+}
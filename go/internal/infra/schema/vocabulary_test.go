@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestVocabularySchema(t *testing.T) {
+	s := VocabularySchema([]domain.MetaKey{
+		{Name: "tier", Type: domain.MetaTypeString, Enum: []string{"tier-1", "tier-2"}},
+		{Name: "alerts", Type: domain.MetaTypeStringList},
+		{Name: "backup-schedule", Type: domain.MetaTypeString, NodeTypes: []domain.NodeType{domain.Database}},
+	})
+
+	t.Run("should accept conforming nodes", func(t *testing.T) {
+		for _, node := range []map[string]any{
+			{"node-type": "service", "metadata": map[string]any{"tier": "tier-1", "alerts": []string{"5xx"}}},
+			{"node-type": "database", "metadata": map[string]any{"backup-schedule": "daily"}},
+			{"node-type": "actor"},
+		} {
+			violations, err := Validate(s, node)
+			if err != nil || len(violations) != 0 {
+				t.Errorf("expected %v to conform, got %v (%v)", node, violations, err)
+			}
+		}
+	})
+
+	t.Run("should reject unknown, mistyped and misplaced keys", func(t *testing.T) {
+		node := map[string]any{
+			"node-type": "service",
+			"metadata":  map[string]any{"tier": "gold", "alerts": []any{1}, "backup-schedule": "daily", "x": 1},
+		}
+		violations, err := Validate(s, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := strings.Join(violations, "\n")
+		for _, want := range []string{
+			`metadata.tier: must be one of ["tier-1", "tier-2"]`,
+			`metadata.alerts[0]: must be of type string, got number`,
+			`metadata: unknown property "x"`,
+			`node-type: must be one of ["database"]`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q in:\n%s", want, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// Part is a team-owned sub-architecture of a CompositeBuilder.
+type Part struct {
+	ID      string
+	Builder Builder
+	// Prefix is applied to the IDs of the part when it is merged.
+	Prefix string
+}
+
+// PartitionedBuilder is a Builder made of parts that can also be generated on
+// their own.
+type PartitionedBuilder interface {
+	Builder
+	Parts() []Part
+}
+
+// CompositeBuilder merges sub-architectures into one platform view.
+// Root builds the platform itself (its own nodes plus the relationships that
+// cross part boundaries, referring to part nodes via ImportOptions.ID); each
+// part is then imported with its prefix.
+type CompositeBuilder struct {
+	Root  Builder
+	Items []Part
+}
+
+// Parts returns the sub-architectures in import order.
+func (b CompositeBuilder) Parts() []Part {
+	return b.Items
+}
+
+// Compose builds the merged architecture, reporting builder errors of the
+// parts and ID collisions between them.
+func (b CompositeBuilder) Compose() (*domain.Architecture, error) {
+	arch := b.Root.Build()
+	for _, p := range b.Items {
+		part := p.Builder.Build()
+		if err := part.Err(); err != nil {
+			return nil, fmt.Errorf("part %q: %w", p.ID, err)
+		}
+		if err := arch.Import(part, domain.ImportOptions{Prefix: p.Prefix}); err != nil {
+			return nil, fmt.Errorf("part %q: %w", p.ID, err)
+		}
+	}
+	return arch, nil
+}
+
+// Build returns the merged architecture. When Compose fails it returns the
+// platform root instead, with the failure recorded so that Err reports it.
+func (b CompositeBuilder) Build() *domain.Architecture {
+	arch, err := b.Compose()
+	if err != nil {
+		arch = b.Root.Build()
+		arch.RecordError(err)
+	}
+	return arch
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+type funcBuilder func() *domain.Architecture
+
+func (f funcBuilder) Build() *domain.Architecture { return f() }
+
+type idRenderer struct{}
+
+func (idRenderer) Render(a *domain.Architecture) (string, error) {
+	ids := make([]string, len(a.Nodes))
+	for i, n := range a.Nodes {
+		ids[i] = n.UniqueID
+	}
+	return strings.Join(ids, ","), nil
+}
+
+func newTeamArch(id string) funcBuilder {
+	return func() *domain.Architecture {
+		arch := domain.NewArchitecture(id, id, "desc")
+		api := arch.DefineNode("api", domain.Service, "API", "desc")
+		db := arch.DefineNode("db", domain.Database, "DB", "desc")
+		api.ConnectTo(db, "queries")
+		return arch
+	}
+}
+
+func newPlatformBuilder() CompositeBuilder {
+	root := funcBuilder(func() *domain.Architecture {
+		arch := domain.NewArchitecture("platform", "Platform", "desc")
+		arch.Connect("stock-check", "desc", "payments-api", "inventory-api")
+		return arch
+	})
+	return CompositeBuilder{
+		Root: root,
+		Items: []Part{
+			{ID: "payments", Builder: newTeamArch("payments"), Prefix: "payments-"},
+			{ID: "inventory", Builder: newTeamArch("inventory"), Prefix: "inventory-"},
+		},
+	}
+}
+
+func TestCompositeBuilder(t *testing.T) {
+	gen := Generator{
+		Builder:   newPlatformBuilder(),
+		Renderers: map[OutputFormat]Renderer{FormatJSON: idRenderer{}},
+		Validator: RuleValidator{Rules: []domain.ValidationRule{domain.NoDanglingRelationships()}},
+	}
+
+	t.Run("should generate the merged view", func(t *testing.T) {
+		out, errs, err := gen.Generate(FormatJSON, true)
+		if err != nil || len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v %v", err, errs)
+		}
+		if out != "payments-api,payments-db,inventory-api,inventory-db" {
+			t.Errorf("unexpected merged nodes %q", out)
+		}
+	})
+
+	t.Run("should generate each part separately", func(t *testing.T) {
+		outputs, invalid, err := gen.GenerateParts(FormatJSON, true)
+		if err != nil || len(invalid) != 0 {
+			t.Fatalf("unexpected errors: %v %v", err, invalid)
+		}
+		if len(outputs) != 2 || outputs["payments"] != "api,db" || outputs["inventory"] != "api,db" {
+			t.Errorf("unexpected part outputs %v", outputs)
+		}
+	})
+
+	t.Run("should surface collisions as errors", func(t *testing.T) {
+		b := newPlatformBuilder()
+		b.Items[1].Prefix = "payments-"
+		_, _, err := Generator{Builder: b, Renderers: gen.Renderers}.Generate(FormatJSON, false)
+		if err == nil || !strings.Contains(err.Error(), `part "inventory"`) {
+			t.Errorf("expected collision error for inventory, got %v", err)
+		}
+	})
+
+	t.Run("should surface builder errors of a part", func(t *testing.T) {
+		b := newPlatformBuilder()
+		b.Items[0].Builder = funcBuilder(func() *domain.Architecture {
+			arch := newTeamArch("payments")()
+			arch.DefineNode("api", domain.Service, "API", "desc")
+			return arch
+		})
+		_, _, err := Generator{Builder: b, Renderers: gen.Renderers}.Generate(FormatJSON, false)
+		if err == nil || !strings.Contains(err.Error(), `duplicate node ID "api"`) {
+			t.Errorf("expected duplicate node error, got %v", err)
+		}
+		if b.Build().Err() == nil {
+			t.Errorf("expected Build to record the failure")
+		}
+	})
+
+	t.Run("should split the registered ecommerce architecture", func(t *testing.T) {
+		b, err := LookupBuilder(DefaultArchitectureID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := Generator{Builder: b, Renderers: gen.Renderers}
+		merged, _, err := g.Generate(FormatJSON, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(merged, ",payment-service,inventory-service,inventory-db") {
+			t.Errorf("expected the parts after the platform nodes, got %q", merged)
+		}
+		outputs, _, err := g.GenerateParts(FormatJSON, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outputs["payments"] != "payment-service" || outputs["inventory"] != "inventory-service,inventory-db" {
+			t.Errorf("unexpected part outputs %v", outputs)
+		}
+	})
+}
+
+type mapLoader map[string]*domain.Architecture
+
+func (m mapLoader) Load(ref string) (*domain.Architecture, error) {
+	if a, ok := m[ref]; ok {
+		return a, nil
+	}
+	return nil, fmt.Errorf("unknown %s", ref)
+}
+
+func TestGenerateLinked(t *testing.T) {
+	newLinked := func(id, link string) *domain.Architecture {
+		arch := domain.NewArchitecture(id, id, "desc")
+		var opts []domain.NodeOption
+		if link != "" {
+			opts = append(opts, domain.WithDetails(link, ""))
+		}
+		arch.DefineNode(id+"-node", domain.System, "Node", "desc", opts...)
+		return arch
+	}
+	root := newLinked("platform", "payments")
+	loader := mapLoader{
+		"payments": newLinked("payments", "ledger"),
+		// ledger links back to payments; following must still terminate.
+		"ledger": newLinked("ledger", "payments"),
+	}
+
+	t.Run("should render every reachable architecture once", func(t *testing.T) {
+		gen := Generator{
+			Builder:   StaticBuilder{Architecture: root},
+			Renderers: map[OutputFormat]Renderer{FormatJSON: idRenderer{}},
+			Details:   loader,
+		}
+		outputs, invalid, err := gen.GenerateLinked(FormatJSON, false)
+		if err != nil || len(invalid) != 0 {
+			t.Fatalf("unexpected errors: %v %v", err, invalid)
+		}
+		if len(outputs) != 3 || outputs["ledger"] != "ledger-node" {
+			t.Errorf("unexpected linked outputs %v", outputs)
+		}
+	})
+
+	t.Run("should give architectures with the same unique-id distinct names", func(t *testing.T) {
+		gen := Generator{
+			Builder:   StaticBuilder{Architecture: newLinked("platform", "copy")},
+			Renderers: map[OutputFormat]Renderer{FormatJSON: idRenderer{}},
+			Details:   mapLoader{"copy": newLinked("platform", "")},
+		}
+		outputs, _, err := gen.GenerateLinked(FormatJSON, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(outputs) != 2 || outputs["platform"] == "" || outputs["platform-2"] == "" {
+			t.Errorf("unexpected linked outputs %v", outputs)
+		}
+	})
+
+	t.Run("should reject unique-ids that cannot name a file", func(t *testing.T) {
+		for _, id := range []string{"", "../escape", "/tmp/abs"} {
+			gen := Generator{
+				Builder:   StaticBuilder{Architecture: newLinked("platform", "bad")},
+				Renderers: map[OutputFormat]Renderer{FormatJSON: idRenderer{}},
+				Details:   mapLoader{"bad": newLinked(id, "")},
+			}
+			_, _, err := gen.GenerateLinked(FormatJSON, false)
+			if err == nil || !strings.Contains(err.Error(), `"bad"`) {
+				t.Errorf("expected error naming the ref for %q, got %v", id, err)
+			}
+		}
+	})
+
+	t.Run("should not follow links back to the root", func(t *testing.T) {
+		linked, err := FollowDetails(root, "platform", mapLoader{
+			"payments": newLinked("payments", "platform"),
+			"platform": newLinked("platform", "payments"),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(linked) != 1 || linked[0].Ref != "payments" {
+			t.Errorf("expected only payments to be linked, got %v", linked)
+		}
+	})
+
+	t.Run("should report the node with an unresolvable link", func(t *testing.T) {
+		_, err := FollowDetails(root, "platform", mapLoader{})
+		if err == nil || !strings.Contains(err.Error(), `node "platform-node"`) {
+			t.Errorf("expected error naming the node, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// ArchitectureLoader loads the architecture a node's details link refers to.
+type ArchitectureLoader interface {
+	Load(ref string) (*domain.Architecture, error)
+}
+
+// RefResolver is implemented by loaders whose references are relative to the
+// architecture holding them, such as file paths.
+type RefResolver interface {
+	// ResolveRef returns the reference ref, found in the architecture loaded
+	// from the reference from, as Load expects it.
+	ResolveRef(from, ref string) string
+}
+
+// LinkedArchitecture is an architecture reached through node details.
+type LinkedArchitecture struct {
+	Ref string
+	// Parent and NodeID identify the node that links to the architecture.
+	Parent       string
+	NodeID       string
+	Architecture *domain.Architecture
+}
+
+// FollowDetails walks detailed-architecture links from root breadth-first and
+// returns every architecture reached, each reference once, so links that loop
+// back, also to root itself under rootRef, terminate. When loader is a
+// RefResolver, references are resolved against the architecture holding them.
+func FollowDetails(root *domain.Architecture, rootRef string, loader ArchitectureLoader) ([]LinkedArchitecture, error) {
+	resolve := func(_, ref string) string { return ref }
+	if r, ok := loader.(RefResolver); ok {
+		resolve = r.ResolveRef
+	}
+
+	var linked []LinkedArchitecture
+	seen := map[string]bool{rootRef: true}
+	type pending struct {
+		ref  string
+		arch *domain.Architecture
+	}
+	queue := []pending{{rootRef, root}}
+	for len(queue) > 0 {
+		from, arch := queue[0].ref, queue[0].arch
+		queue = queue[1:]
+		for _, n := range arch.Nodes {
+			if n.Details == nil || n.Details.DetailedArchitecture == "" {
+				continue
+			}
+			ref := resolve(from, n.Details.DetailedArchitecture)
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+
+			child, err := loader.Load(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%s: node %q details: %w", arch.UniqueID, n.UniqueID, err)
+			}
+			linked = append(linked, LinkedArchitecture{
+				Ref: ref, Parent: arch.UniqueID, NodeID: n.UniqueID, Architecture: child,
+			})
+			queue = append(queue, pending{ref, child})
+		}
+	}
+	return linked, nil
+}
@@ -8,11 +8,23 @@ import (
 
 const numGateways = 2
 
-// EcommerceBuilder constructs the reference CALM architecture.
-type EcommerceBuilder struct{}
+// IDs of the payments and inventory elements the platform wires to. The parts
+// define them with literals, which Studio needs to find the nodes.
+const (
+	paymentServiceID   = "payment-service"
+	inventoryServiceID = "inventory-service"
+	inventoryDBID      = "inventory-db"
+	inventoryDBRelID   = "inventory-connects-db"
+)
+
+// ecommercePlatformBuilder constructs the platform around the parts: entry
+// points, gateways, orders and messaging, plus the relationships and flows
+// that reach into the parts. Its Build comes first in this file because Studio
+// adds new nodes to the first Build function.
+type ecommercePlatformBuilder struct{}
 
-// Build returns the populated e-commerce architecture model.
-func (EcommerceBuilder) Build() *domain.Architecture {
+// Build returns the platform without the payments and inventory nodes.
+func (ecommercePlatformBuilder) Build() *domain.Architecture {
 	arch := domain.NewArchitecture(
 		"ecommerce-platform-architecture",
 		"E-Commerce Order Processing Platform",
@@ -25,13 +37,157 @@ func (EcommerceBuilder) Build() *domain.Architecture {
 	nodes := defineNodes(arch)
 	links := wireComponents(arch, nodes)
 	defineFlows(arch, nodes, links)
-	arch.DefineNode("api-gateway-1", domain.Service, "API Gateway Instance 1", "Primary API Gateway instance.")
-	arch.DefineNode(
-		"api-gateway-2",
+
+	return arch
+}
+
+// NewEcommerceBuilder returns the builder of the reference CALM architecture:
+// the platform composed with the payments and inventory parts. The parts are
+// defined in this file too, so Studio can edit all of their nodes.
+func NewEcommerceBuilder() CompositeBuilder {
+	return CompositeBuilder{
+		Root: ecommercePlatformBuilder{},
+		Items: []Part{
+			{ID: "payments", Builder: PaymentsBuilder{}},
+			{ID: "inventory", Builder: InventoryBuilder{}},
+		},
+	}
+}
+
+// PaymentsBuilder constructs the part of the e-commerce architecture owned by
+// the payments team.
+type PaymentsBuilder struct{}
+
+// Build returns the payments architecture.
+func (PaymentsBuilder) Build() *domain.Architecture {
+	arch := domain.NewArchitecture(
+		"payments-architecture",
+		"Payments",
+		"Payment processing for the e-commerce platform.",
+	)
+	arch.AddMeta("owner", "payments-team")
+
+	svc := arch.DefineNode(
+		"payment-service",
 		domain.Service,
-		"API Gateway Instance 2",
-		"Secondary API Gateway instance for high availability.",
+		"Payment Service",
+		"Integrates with external payment providers.",
+		domain.WithOwner("payments-team", "CC-5000"),
+		domain.WithMeta(arch.Merge(metaTier1, metaOpsPayments, map[string]any{
+			"deployment-type": "serverless",
+			"tech-owner":      "Payment Team",
+			"health-endpoint": "/health",
+			"runbook":         "https://runbooks.example.com/payment-service",
+			"dashboard":       "https://grafana.example.com/d/payment-metrics",
+			"log-query":       "app:payment-service",
+			"alerts":          []string{"PaymentGatewayTimeout", "PCIViolationAttempt"},
+			"repository":      "https://github.com/example/payment-service",
+			"failure-modes": []map[string]any{
+				{
+					"check":        "Verify external gateway status page",
+					"escalation":   "Escalate to provider support",
+					"likely-cause": "External payment gateway latency",
+					"remediation":  "Enable aggressive retry for idempotent calls",
+					"symptom":      "Payment processing timeouts",
+				},
+				{
+					"check":        "Review access logs for unusual patterns",
+					"escalation":   "Contact security-team",
+					"likely-cause": "API Key leaked or compromised",
+					"remediation":  "Rotate API keys immediately",
+					"symptom":      "Unauthorized transaction spikes",
+				},
+			},
+		})),
+		domain.WithControl(
+			"compliance",
+			"PCI-DSS compliance for payment processing",
+			domain.NewRequirementURL(
+				"https://www.pcisecuritystandards.org/documents/PCI-DSS-v4.0",
+				"https://configs.example.com/compliance/pci-dss-config.json",
+			),
+		),
+	)
+	svc.Interface("payment-api", "REST").SetName("Payment Processing API").SetPort(8082)
+	svc.Interface("payment-consumer", "AMQP").SetDesc("Consumes order messages for payment processing.")
+	svc.Interface("payment-health", "HTTP").SetName("Health Check").SetPath("/health")
+	svc.AddMeta("dependencies", []string{"external-payment-provider"})
+
+	return arch
+}
+
+// InventoryBuilder constructs the part of the e-commerce architecture owned
+// by the inventory team.
+type InventoryBuilder struct{}
+
+// Build returns the inventory architecture.
+func (InventoryBuilder) Build() *domain.Architecture {
+	arch := domain.NewArchitecture(
+		"inventory-architecture",
+		"Inventory",
+		"Stock management for the e-commerce platform.",
+	)
+	arch.AddMeta("owner", "inventory-team")
+
+	svc := arch.DefineNode(
+		"inventory-service",
+		domain.Service,
+		"Inventory Service",
+		"Manages product stock levels.",
+		domain.WithOwner("inventory-team", "CC-4000"),
+		domain.WithMeta(arch.Merge(metaTier2, metaOpsInv, metaContainer, map[string]any{
+			"tech-owner":      "Warehouse Team",
+			"health-endpoint": "/health",
+			"runbook":         "https://runbooks.example.com/inventory-service",
+			"dashboard":       "https://grafana.example.com/d/inventory-metrics",
+			"log-query":       "app:inventory-service",
+			"alerts":          []string{"InventoryCacheInconsistency", "StockUpdateFailure"},
+			"repository":      "https://github.com/example/inventory-service",
+			"failure-modes": []map[string]any{
+				{
+					"check":        "Check DB lock metrics and slow query log",
+					"escalation":   "Contact DBA team for lock contention",
+					"likely-cause": "Deadlock on stock updates",
+					"remediation":  "Review transaction isolation level or retry logic",
+					"symptom":      "Inventory sync failures",
+				},
+				{
+					"check":        "Verify Redis/Memcached availability and evictions",
+					"escalation":   "Contact platform-team for cache infrastructure",
+					"likely-cause": "Cache invalidation failure",
+					"remediation":  "Flush cache for affected products",
+					"symptom":      "Stale stock levels",
+				},
+			},
+		})),
+	)
+	svc.Interface("inventory-api", "REST").SetName("Inventory API").SetPort(8081)
+	svc.Interface("inventory-db-client", "JDBC")
+	svc.Interface("inventory-health", "HTTP").SetName("Health Check").SetPath("/health")
+
+	db := arch.DefineNode(
+		"inventory-db",
+		domain.Database,
+		"Inventory Database",
+		"Stores stock levels.",
+		domain.WithOwner("dba-team", "CC-4000"),
+		domain.WithMeta(
+			arch.Merge(
+				metaDBA,
+				metaManagedSvc,
+				map[string]any{"backup-schedule": "weekly at Sunday 03:00 UTC", "restore-time": "30 minutes"},
+			),
+		),
 	)
+	db.Interface("inventory-sql", "JDBC").
+		SetPort(5432).
+		SetDB("inventory_v1").
+		SetHost("inventory-db.example.com")
+	svc.AddMeta("dependencies", []string{db.UniqueID})
+
+	svc.ConnectTo(db, "Inventory Service manages stock in Inventory Database.").
+		WithID(inventoryDBRelID).
+		Via("inventory-db-client", "inventory-sql").Is("internal").Encrypted(true).Tag("monitoring", true)
 
 	return arch
 }
@@ -62,12 +218,9 @@ type nodesContainer struct {
 	LB           *domain.Node
 	Gateways     []*domain.Node
 	OrderSvc     *domain.Node
-	InventorySvc *domain.Node
-	PaymentSvc   *domain.Node
 	OrderQueue   *domain.Node
 	OrderPrimary *domain.Node
 	OrderReplica *domain.Node
-	InventoryDB  *domain.Node
 	Broker       *domain.Node
 	DBCluster    *domain.Node
 	System       *domain.Node
@@ -78,13 +231,12 @@ type linksContainer struct {
 	AdminToLB    *domain.Relationship
 	LBToGW       map[string]*domain.ConnectionBuilder
 	GWToOrder    map[string]*domain.ConnectionBuilder
-	GWToInv      map[string]*domain.ConnectionBuilder
+	GWToInv      map[string]*domain.Relationship
 	OrderToPriDB *domain.ConnectionBuilder
 	OrderToRepDB *domain.ConnectionBuilder
 	OrderToQueue *domain.ConnectionBuilder
-	OrderToInv   *domain.ConnectionBuilder
-	QueueToPay   *domain.ConnectionBuilder
-	InvToDB      *domain.ConnectionBuilder
+	OrderToInv   *domain.Relationship
+	QueueToPay   *domain.Relationship
 }
 
 func setupGeneralInfo(a *domain.Architecture) {
@@ -161,7 +313,9 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		"Load Balancer",
 		"High-availability entry point that distributes traffic to API Gateways.",
 		domain.WithOwner("platform-team", "CC-2000"),
-		domain.WithMeta(domain.Merge(metaTier1, metaOpsPlatform, metaManagedSvc, map[string]any{
+		domain.WithEntryPoint(),
+		domain.WithLoadBalancer(),
+		domain.WithMeta(a.Merge(metaTier1, metaOpsPlatform, metaManagedSvc, map[string]any{
 			"tech-owner":      "Network Team",
 			"ha-enabled":      true,
 			"health-endpoint": "/status",
@@ -194,7 +348,7 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		gw := a.DefineNode(id, domain.Service, fmt.Sprintf("API Gateway Instance %d", i), desc,
 			domain.WithOwner("platform-team", "CC-2000"),
 			domain.WithControl("performance", "API Gateway rate limiting and caching requirements", gwPerf...),
-			domain.WithMeta(domain.Merge(metaTier1, metaOpsPlatform, metaContainer, map[string]any{
+			domain.WithMeta(a.Merge(metaTier1, metaOpsPlatform, metaContainer, map[string]any{
 				"tech-owner":      "Edge Team",
 				"health-endpoint": "/health",
 				"runbook":         "https://runbooks.example.com/api-gateway",
@@ -240,7 +394,7 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		"Order Service",
 		"Handles order creation and lifecycle management.",
 		domain.WithOwner("orders-team", "CC-3000"),
-		domain.WithMeta(domain.Merge(metaTier1, metaOpsOrders, metaContainer, map[string]any{
+		domain.WithMeta(a.Merge(metaTier1, metaOpsOrders, metaContainer, map[string]any{
 			"tech-owner":      "Order Team",
 			"health-endpoint": "/actuator/health",
 			"runbook":         "https://runbooks.example.com/order-service",
@@ -271,94 +425,13 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 	nc.OrderSvc.Interface("order-inventory-client", "REST").SetDesc("Outbound connection to check inventory")
 	nc.OrderSvc.Interface("order-health", "HTTP").SetName("Health Check").SetPath("/health")
 
-	nc.InventorySvc = a.DefineNode(
-		"inventory-service",
-		domain.Service,
-		"Inventory Service",
-		"Manages product stock levels.",
-		domain.WithOwner("inventory-team", "CC-4000"),
-		domain.WithMeta(domain.Merge(metaTier2, metaOpsInv, metaContainer, map[string]any{
-			"tech-owner":      "Warehouse Team",
-			"health-endpoint": "/health",
-			"runbook":         "https://runbooks.example.com/inventory-service",
-			"dashboard":       "https://grafana.example.com/d/inventory-metrics",
-			"log-query":       "app:inventory-service",
-			"alerts":          []string{"InventoryCacheInconsistency", "StockUpdateFailure"},
-			"repository":      "https://github.com/example/inventory-service",
-			"failure-modes": []map[string]any{
-				{
-					"check":        "Check DB lock metrics and slow query log",
-					"escalation":   "Contact DBA team for lock contention",
-					"likely-cause": "Deadlock on stock updates",
-					"remediation":  "Review transaction isolation level or retry logic",
-					"symptom":      "Inventory sync failures",
-				},
-				{
-					"check":        "Verify Redis/Memcached availability and evictions",
-					"escalation":   "Contact platform-team for cache infrastructure",
-					"likely-cause": "Cache invalidation failure",
-					"remediation":  "Flush cache for affected products",
-					"symptom":      "Stale stock levels",
-				},
-			},
-		})),
-	)
-	nc.InventorySvc.Interface("inventory-api", "REST").SetName("Inventory API").SetPort(8081)
-	nc.InventorySvc.Interface("inventory-db-client", "JDBC")
-	nc.InventorySvc.Interface("inventory-health", "HTTP").SetName("Health Check").SetPath("/health")
-
-	nc.PaymentSvc = a.DefineNode(
-		"payment-service",
-		domain.Service,
-		"Payment Service",
-		"Integrates with external payment providers.",
-		domain.WithOwner("payments-team", "CC-5000"),
-		domain.WithMeta(domain.Merge(metaTier1, metaOpsPayments, map[string]any{
-			"deployment-type": "serverless",
-			"tech-owner":      "Payment Team",
-			"health-endpoint": "/health",
-			"runbook":         "https://runbooks.example.com/payment-service",
-			"dashboard":       "https://grafana.example.com/d/payment-metrics",
-			"log-query":       "app:payment-service",
-			"alerts":          []string{"PaymentGatewayTimeout", "PCIViolationAttempt"},
-			"repository":      "https://github.com/example/payment-service",
-			"failure-modes": []map[string]any{
-				{
-					"check":        "Verify external gateway status page",
-					"escalation":   "Escalate to provider support",
-					"likely-cause": "External payment gateway latency",
-					"remediation":  "Enable aggressive retry for idempotent calls",
-					"symptom":      "Payment processing timeouts",
-				},
-				{
-					"check":        "Review access logs for unusual patterns",
-					"escalation":   "Contact security-team",
-					"likely-cause": "API Key leaked or compromised",
-					"remediation":  "Rotate API keys immediately",
-					"symptom":      "Unauthorized transaction spikes",
-				},
-			},
-		})),
-		domain.WithControl(
-			"compliance",
-			"PCI-DSS compliance for payment processing",
-			domain.NewRequirementURL(
-				"https://www.pcisecuritystandards.org/documents/PCI-DSS-v4.0",
-				"https://configs.example.com/compliance/pci-dss-config.json",
-			),
-		),
-	)
-	nc.PaymentSvc.Interface("payment-api", "REST").SetName("Payment Processing API").SetPort(8082)
-	nc.PaymentSvc.Interface("payment-consumer", "AMQP").SetDesc("Consumes order messages for payment processing.")
-	nc.PaymentSvc.Interface("payment-health", "HTTP").SetName("Health Check").SetPath("/health")
-
 	nc.Broker = a.DefineNode(
 		"message-broker",
 		domain.System,
 		"Message Broker (RabbitMQ)",
 		"Central messaging system for failure isolation and async processing.",
 		domain.WithOwner("platform-team", "CC-2000"),
-		domain.WithMeta(domain.Merge(metaOpsPlatform, metaManagedSvc, map[string]any{
+		domain.WithMeta(a.Merge(metaOpsPlatform, metaManagedSvc, map[string]any{
 			"tech-owner":      "Platform Team",
 			"tier":            "tier-1",
 			"health-endpoint": "/health",
@@ -402,7 +475,7 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		"Main writable database for orders.",
 		domain.WithOwner("dba-team", "CC-3000"),
 		domain.WithMeta(
-			domain.Merge(
+			a.Merge(
 				metaDBA,
 				metaManagedSvc,
 				map[string]any{
@@ -427,7 +500,7 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		"Read-only replica for scaling read operations.",
 		domain.WithOwner("dba-team", "CC-3000"),
 		domain.WithMeta(
-			domain.Merge(
+			a.Merge(
 				metaDBA,
 				metaManagedSvc,
 				map[string]any{
@@ -445,35 +518,14 @@ func defineNodes(a *domain.Architecture) *nodesContainer {
 		SetDB("orders_v1").
 		SetHost("orders-replica.example.com")
 
-	nc.InventoryDB = a.DefineNode(
-		"inventory-db",
-		domain.Database,
-		"Inventory Database",
-		"Stores stock levels.",
-		domain.WithOwner("dba-team", "CC-4000"),
-		domain.WithMeta(
-			domain.Merge(
-				metaDBA,
-				metaManagedSvc,
-				map[string]any{"backup-schedule": "weekly at Sunday 03:00 UTC", "restore-time": "30 minutes"},
-			),
-		),
-	)
-	nc.InventoryDB.Interface("inventory-sql", "JDBC").
-		SetPort(5432).
-		SetDB("inventory_v1").
-		SetHost("inventory-db.example.com")
-
 	// Dynamic dependencies
 	gwIDs := []string{}
 	for _, gw := range nc.Gateways {
 		gwIDs = append(gwIDs, gw.UniqueID)
-		gw.AddMeta("dependencies", []string{nc.OrderSvc.UniqueID, nc.InventorySvc.UniqueID})
+		gw.AddMeta("dependencies", []string{nc.OrderSvc.UniqueID, inventoryServiceID})
 	}
 	nc.LB.AddMeta("dependencies", gwIDs)
-	nc.OrderSvc.AddMeta("dependencies", []string{nc.DBCluster.UniqueID, nc.InventorySvc.UniqueID, nc.Broker.UniqueID})
-	nc.InventorySvc.AddMeta("dependencies", []string{nc.InventoryDB.UniqueID})
-	nc.PaymentSvc.AddMeta("dependencies", []string{"external-payment-provider"})
+	nc.OrderSvc.AddMeta("dependencies", []string{nc.DBCluster.UniqueID, inventoryServiceID, nc.Broker.UniqueID})
 
 	return nc
 }
@@ -482,7 +534,7 @@ func wireComponents(a *domain.Architecture, n *nodesContainer) *linksContainer {
 	lc := &linksContainer{
 		LBToGW:    make(map[string]*domain.ConnectionBuilder),
 		GWToOrder: make(map[string]*domain.ConnectionBuilder),
-		GWToInv:   make(map[string]*domain.ConnectionBuilder),
+		GWToInv:   make(map[string]*domain.Relationship),
 	}
 
 	lc.CustomerToLB = a.Interacts("customer-interacts-lb", "Customer accesses the platform via Load Balancer.", n.Customer.UniqueID, n.LB.UniqueID).
@@ -506,11 +558,14 @@ func wireComponents(a *domain.Architecture, n *nodesContainer) *linksContainer {
 	}
 	for i, gw := range n.Gateways {
 		id := gw.UniqueID
-		lc.GWToInv[id] = gw.ConnectTo(n.InventorySvc, fmt.Sprintf("Gateway %d forwards requests to Inventory Service.", i+1)).
-			WithID(fmt.Sprintf("gateway-%d-connects-inventory", i+1)).
-			Via(fmt.Sprintf("inventory-client-%d", i+1), "inventory-api").
-			Is("internal").
-			Encrypted(true)
+		lc.GWToInv[id] = a.Connect(
+			fmt.Sprintf("gateway-%d-connects-inventory", i+1),
+			fmt.Sprintf("Gateway %d forwards requests to Inventory Service.", i+1),
+			id, inventoryServiceID,
+		).
+			SrcIntf(fmt.Sprintf("inventory-client-%d", i+1)).
+			DstIntf("inventory-api").
+			Data("internal", true)
 	}
 
 	lc.OrderToPriDB = n.OrderSvc.ConnectTo(n.OrderPrimary, "Order Service persists data to Primary Order Database.").
@@ -518,19 +573,16 @@ func wireComponents(a *domain.Architecture, n *nodesContainer) *linksContainer {
 		Via("order-db-write-client", "order-sql-primary").Is("confidential").Encrypted(true).Tag("monitoring", true)
 	lc.OrderToQueue = n.OrderSvc.ConnectTo(n.OrderQueue, "Order Service publishes payment task to queue.").
 		WithID("order-publishes-to-queue").Via("payment-publisher", "").Is("internal").Encrypted(true).Protocol("AMQP")
-	lc.QueueToPay = n.OrderQueue.ConnectTo(n.PaymentSvc, "Payment Service consumes payment task from queue.").
-		WithID("payment-subscribes-to-queue").
-		Via("", "payment-consumer").Is("internal").Encrypted(true).Protocol("AMQP")
+	lc.QueueToPay = a.Connect("payment-subscribes-to-queue", "Payment Service consumes payment task from queue.",
+		n.OrderQueue.UniqueID, paymentServiceID).
+		DstIntf("payment-consumer").Data("internal", true).WithProtocol("AMQP")
 	lc.OrderToRepDB = n.OrderSvc.ConnectTo(n.OrderReplica, "Order Service reads data from Replica Order Database.").
 		WithID("order-connects-replica-db").
 		Via("order-db-read-client", "order-sql-replica").Is("confidential").Encrypted(true).Tag("monitoring", true)
-	lc.OrderToInv = n.OrderSvc.ConnectTo(n.InventorySvc, "Order Service checks/reserves stock in Inventory Service.").
-		WithID("order-connects-inventory").
-		Via("order-inventory-client", "inventory-api").Is("internal").Encrypted(true).
-		Tag("monitoring", true).Tag("circuit-breaker", true).Tag("latency-sla", "< 100ms")
-	lc.InvToDB = n.InventorySvc.ConnectTo(n.InventoryDB, "Inventory Service manages stock in Inventory Database.").
-		WithID("inventory-connects-db").
-		Via("inventory-db-client", "inventory-sql").Is("internal").Encrypted(true).Tag("monitoring", true)
+	lc.OrderToInv = a.Connect("order-connects-inventory", "Order Service checks/reserves stock in Inventory Service.",
+		n.OrderSvc.UniqueID, inventoryServiceID).
+		SrcIntf("order-inventory-client").DstIntf("inventory-api").Data("internal", true).
+		AddMeta("monitoring", true).AddMeta("circuit-breaker", true).AddMeta("latency-sla", "< 100ms")
 
 	a.ComposedOf("broker-composition", "Message broker contains the order queue.", n.Broker.UniqueID, []string{n.OrderQueue.UniqueID}).
 		Data("internal", false)
@@ -544,10 +596,10 @@ func wireComponents(a *domain.Architecture, n *nodesContainer) *linksContainer {
 	sysNodes = append(
 		sysNodes,
 		n.OrderSvc.UniqueID,
-		n.InventorySvc.UniqueID,
-		n.PaymentSvc.UniqueID,
+		inventoryServiceID,
+		paymentServiceID,
 		n.DBCluster.UniqueID,
-		n.InventoryDB.UniqueID,
+		inventoryDBID,
 		n.Broker.UniqueID,
 	)
 	a.ComposedOf("ecommerce-system-composition", "The E-Commerce Platform comprises its core services and databases.", n.System.UniqueID, sysNodes).
@@ -590,7 +642,7 @@ func addOrderFlow(a *domain.Architecture, gw *domain.Node, l *linksContainer) {
 				Desc: fmt.Sprintf("%s routes to Order Service", gw.Name),
 			},
 			domain.StepSpec{ID: l.OrderToQueue.GetID(), Desc: "Order Service publishes payment task"},
-			domain.StepSpec{ID: l.QueueToPay.GetID(), Desc: "Payment Service processes task from queue"},
+			domain.StepSpec{ID: l.QueueToPay.UniqueID, Desc: "Payment Service processes task from queue"},
 		)
 }
 
@@ -606,15 +658,15 @@ func addInventoryFlow(a *domain.Architecture, gw *domain.Node, l *linksContainer
 			domain.StepSpec{ID: l.AdminToLB.UniqueID, Desc: "Admin requests inventory status via LB"},
 			domain.StepSpec{ID: l.LBToGW[gw.UniqueID].GetID(), Desc: fmt.Sprintf("LB routes to %s", gw.Name)},
 			domain.StepSpec{
-				ID:   l.GWToInv[gw.UniqueID].GetID(),
+				ID:   l.GWToInv[gw.UniqueID].UniqueID,
 				Desc: fmt.Sprintf("%s routes to inventory service", gw.Name),
 			},
-			domain.StepSpec{ID: l.InvToDB.GetID(), Desc: "Query current stock levels"},
-			domain.StepSpec{ID: l.InvToDB.GetID(), Desc: "Return stock data", Dir: "destination-to-source"},
+			domain.StepSpec{ID: inventoryDBRelID, Desc: "Query current stock levels"},
+			domain.StepSpec{ID: inventoryDBRelID, Desc: "Return stock data", Dir: domain.DestinationToSource},
 			domain.StepSpec{
-				ID:   l.GWToInv[gw.UniqueID].GetID(),
+				ID:   l.GWToInv[gw.UniqueID].UniqueID,
 				Desc: "Return inventory report",
-				Dir:  "destination-to-source",
+				Dir:  domain.DestinationToSource,
 			},
 		)
 }
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/sokoide/advent-of-calm-2025/internal/domain"
 )
@@ -26,6 +28,16 @@ type Builder interface {
 	Build() *domain.Architecture
 }
 
+// StaticBuilder returns a pre-built architecture, such as one parsed from CALM JSON.
+type StaticBuilder struct {
+	Architecture *domain.Architecture
+}
+
+// Build returns the wrapped architecture.
+func (b StaticBuilder) Build() *domain.Architecture {
+	return b.Architecture
+}
+
 // Validator evaluates an architecture against rule sets.
 type Validator interface {
 	Validate(*domain.Architecture) []domain.ValidationError
@@ -41,32 +53,269 @@ func (v RuleValidator) Validate(a *domain.Architecture) []domain.ValidationError
 	return a.Validate(v.Rules...)
 }
 
+// DocumentValidator checks a rendered CALM JSON document against its
+// meta-schema.
+type DocumentValidator interface {
+	ValidateDocument(doc []byte) ([]domain.ValidationError, error)
+}
+
 // Generator orchestrates building, validating, and rendering architectures.
 type Generator struct {
 	Builder       Builder
 	Renderers     map[OutputFormat]Renderer
 	Validator     Validator
 	DefaultFormat OutputFormat
+	// Documents, when set, checks every JSON output before it is returned;
+	// violations are reported as validation errors even without validate.
+	Documents DocumentValidator
+	// FailOn is the lowest severity of validation errors that stops rendering;
+	// empty means domain.SeverityError. Less severe errors are still returned.
+	FailOn domain.Severity
+	// Choices resolves options relationships (options ID -> decision description
+	// or 1-based index) before validation and rendering.
+	Choices map[string]string
+	// Details loads the architectures that node details link to.
+	Details ArchitectureLoader
+	// Ref is the reference Details would load the built architecture from,
+	// such as its builder ID or file; links back to it are not followed.
+	Ref string
+}
+
+// composer is implemented by builders whose Build can fail, such as
+// CompositeBuilder; Generate prefers it so failures surface as errors.
+type composer interface {
+	Compose() (*domain.Architecture, error)
 }
 
-// Generate builds the architecture and returns a rendered output.
+// Generate builds the architecture and returns a rendered output. Validation
+// errors below FailOn are returned together with the output.
 func (g Generator) Generate(format OutputFormat, validate bool) (string, []ValidationError, error) {
 	if g.Builder == nil {
 		return "", nil, fmt.Errorf("builder is required")
 	}
 
-	arch := g.Builder.Build()
+	arch, err := g.build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return g.render(arch, format, validate)
+}
+
+// CheckDocument checks a rendered JSON document with Documents; it returns
+// nothing when Documents is not set. Callers that want to show non-conforming
+// output, such as Studio, generate without Documents and check afterwards.
+func (g Generator) CheckDocument(output string) ([]ValidationError, error) {
+	if g.Documents == nil {
+		return nil, nil
+	}
+	return g.Documents.ValidateDocument([]byte(output))
+}
+
+// DocumentMessages checks a rendered JSON document like CheckDocument and
+// returns its violations, or the error that prevented the check, as messages
+// for display.
+func (g Generator) DocumentMessages(output string) []string {
+	errs, err := g.CheckDocument(output)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.String()
+	}
+	return messages
+}
+
+// Validate builds the architecture, resolves choices and returns all its
+// validation errors without rendering it, for callers such as Studio that
+// render regardless.
+func (g Generator) Validate() ([]ValidationError, error) {
+	if g.Builder == nil {
+		return nil, fmt.Errorf("builder is required")
+	}
+	arch, err := g.build()
+	if err != nil {
+		return nil, err
+	}
+	if err := arch.Err(); err != nil {
+		return nil, err
+	}
+	if len(g.Choices) > 0 {
+		if arch, err = arch.Resolved(g.Choices); err != nil {
+			return nil, err
+		}
+	}
+	if g.Validator == nil {
+		return nil, nil
+	}
+	return g.Validator.Validate(arch), nil
+}
+
+// build runs the builder, preferring Compose when the builder can fail.
+func (g Generator) build() (*domain.Architecture, error) {
+	if c, ok := g.Builder.(composer); ok {
+		return c.Compose()
+	}
+	return g.Builder.Build(), nil
+}
+
+// GenerateParts renders each part of a PartitionedBuilder on its own, without
+// prefixes, keyed by output name (see outputNames) of the part ID. Validation
+// errors are returned per part.
+func (g Generator) GenerateParts(
+	format OutputFormat,
+	validate bool,
+) (map[string]string, map[string][]ValidationError, error) {
+	pb, ok := g.Builder.(PartitionedBuilder)
+	if !ok {
+		return nil, nil, fmt.Errorf("builder has no parts")
+	}
 
+	outputs := make(map[string]string)
+	invalid := make(map[string][]ValidationError)
+	names := make(outputNames)
+	for _, p := range pb.Parts() {
+		name, err := names.next(p.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("part %q: %w", p.ID, err)
+		}
+		arch := p.Builder.Build()
+		pg := g
+		pg.Choices = partChoices(arch, p.Prefix, g.Choices)
+		output, validationErrors, err := pg.render(arch, format, validate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("part %q: %w", p.ID, err)
+		}
+		if len(Failing(validationErrors, g.FailOn)) > 0 {
+			invalid[name] = validationErrors
+			continue
+		}
+		outputs[name] = output
+	}
+	return outputs, invalid, nil
+}
+
+// GenerateLinked renders the architecture together with every architecture
+// reachable through node details, keyed by output name (see outputNames) of
+// the unique-id. Choices only apply to the root architecture.
+func (g Generator) GenerateLinked(
+	format OutputFormat,
+	validate bool,
+) (map[string]string, map[string][]ValidationError, error) {
+	if g.Builder == nil {
+		return nil, nil, fmt.Errorf("builder is required")
+	}
+	if g.Details == nil {
+		return nil, nil, fmt.Errorf("details loader is required")
+	}
+
+	root, err := g.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	linked, err := FollowDetails(root, g.Ref, g.Details)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputs := make(map[string]string)
+	invalid := make(map[string][]ValidationError)
+	names := make(outputNames)
+	archs := []*domain.Architecture{root}
+	refs := []string{"root architecture"}
+	for _, l := range linked {
+		archs = append(archs, l.Architecture)
+		refs = append(refs, fmt.Sprintf("%q", l.Ref))
+	}
+	for i, arch := range archs {
+		name, err := names.next(arch.UniqueID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", refs[i], err)
+		}
+		ag := g
+		if i > 0 {
+			ag.Choices = nil
+		}
+		output, validationErrors, err := ag.render(arch, format, validate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(Failing(validationErrors, g.FailOn)) > 0 {
+			invalid[name] = validationErrors
+			continue
+		}
+		outputs[name] = output
+	}
+	return outputs, invalid, nil
+}
+
+// outputNames hands out the keys of GenerateParts and GenerateLinked, which
+// arch-gen uses as file names. A name is the ID with path separators replaced
+// by "-", plus a numeric suffix when an earlier output already took it.
+type outputNames map[string]bool
+
+// next returns the name for id. IDs that are empty or not local paths, such
+// as "../x" or "/tmp/x", are rejected.
+func (n outputNames) next(id string) (string, error) {
+	if id == "" || !filepath.IsLocal(id) {
+		return "", fmt.Errorf("unique-id %q cannot name an output file", id)
+	}
+	base := strings.NewReplacer("/", "-", `\`, "-").Replace(id)
+	name := base
+	for i := 2; n[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	n[name] = true
+	return name, nil
+}
+
+// partChoices keeps the choices that apply to a part, given with or without
+// the part prefix.
+func partChoices(arch *domain.Architecture, prefix string, choices map[string]string) map[string]string {
+	out := make(map[string]string)
+	for _, rel := range arch.Relationships {
+		if rel.RelationshipType.Options == nil {
+			continue
+		}
+		if c, ok := choices[prefix+rel.UniqueID]; ok {
+			out[rel.UniqueID] = c
+		} else if c, ok := choices[rel.UniqueID]; ok {
+			out[rel.UniqueID] = c
+		}
+	}
+	return out
+}
+
+// render resolves choices, validates and renders a built architecture.
+func (g Generator) render(
+	arch *domain.Architecture,
+	format OutputFormat,
+	validate bool,
+) (string, []ValidationError, error) {
+	if err := arch.Err(); err != nil {
+		return "", nil, err
+	}
+	if len(g.Choices) > 0 {
+		var err error
+		if arch, err = arch.Resolved(g.Choices); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var validationErrors []ValidationError
 	if validate && g.Validator != nil {
-		validationErrors := g.Validator.Validate(arch)
-		if len(validationErrors) > 0 {
+		validationErrors = g.Validator.Validate(arch)
+		if len(Failing(validationErrors, g.FailOn)) > 0 {
 			return "", validationErrors, nil
 		}
 	}
 
+	rendered := format
 	renderer := g.Renderers[format]
 	if renderer == nil {
-		renderer = g.Renderers[g.DefaultFormat]
+		rendered = g.DefaultFormat
+		renderer = g.Renderers[rendered]
 	}
 	if renderer == nil {
 		return "", nil, fmt.Errorf("renderer not configured for %s", format)
@@ -77,5 +326,16 @@ func (g Generator) Generate(format OutputFormat, validate bool) (string, []Valid
 		return "", nil, err
 	}
 
-	return output, nil, nil
+	if g.Documents != nil && rendered == FormatJSON {
+		schemaErrors, err := g.CheckDocument(output)
+		if err != nil {
+			return "", nil, err
+		}
+		validationErrors = append(validationErrors, schemaErrors...)
+		if len(Failing(schemaErrors, g.FailOn)) > 0 {
+			return "", validationErrors, nil
+		}
+	}
+
+	return output, validationErrors, nil
 }
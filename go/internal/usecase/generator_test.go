@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestGenerator_Choices(t *testing.T) {
+	arch := domain.NewArchitecture("a", "A", "desc")
+	svc := arch.DefineNode("svc", domain.Service, "Service", "desc")
+	kafka := arch.DefineNode("kafka", domain.Queue, "Kafka", "desc")
+	rabbit := arch.DefineNode("rabbitmq", domain.Queue, "RabbitMQ", "desc")
+	arch.DefineOptions("broker", "Broker").
+		Option("Kafka", []string{kafka.UniqueID}, []string{svc.ConnectTo(kafka, "publish").GetID()}).
+		Option("RabbitMQ", []string{rabbit.UniqueID}, []string{svc.ConnectTo(rabbit, "publish").GetID()})
+
+	gen := Generator{
+		Builder:   StaticBuilder{Architecture: arch},
+		Renderers: map[OutputFormat]Renderer{FormatJSON: idRenderer{}},
+		Validator: RuleValidator{},
+		Choices:   map[string]string{"broker": "Kafka"},
+	}
+	t.Run("should resolve a static architecture on every build", func(t *testing.T) {
+		if _, err := gen.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for range 2 {
+			out, _, err := gen.Generate(FormatJSON, true)
+			if err != nil || out != "svc,kafka" {
+				t.Fatalf("unexpected output %q, %v", out, err)
+			}
+		}
+	})
+}
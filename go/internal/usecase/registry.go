@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultArchitectureID is the architecture used when none is selected.
+const DefaultArchitectureID = "ecommerce"
+
+var (
+	buildersMu sync.RWMutex
+	builders   = map[string]Builder{
+		DefaultArchitectureID: NewEcommerceBuilder(),
+	}
+)
+
+// RegisterBuilder makes a builder selectable by architecture ID, typically from
+// an init function next to the DSL. Registering an existing ID replaces it.
+func RegisterBuilder(id string, b Builder) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	builders[id] = b
+}
+
+// UnregisterBuilder removes the builder registered under id, e.g. in test
+// cleanup.
+func UnregisterBuilder(id string) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	delete(builders, id)
+}
+
+// LookupBuilder returns the builder registered under id. The error lists the
+// available IDs.
+func LookupBuilder(id string) (Builder, error) {
+	buildersMu.RLock()
+	b, ok := builders[id]
+	buildersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown architecture %q (available: %s)", id, strings.Join(BuilderIDs(), ", "))
+	}
+	return b, nil
+}
+
+// BuilderIDs returns the registered architecture IDs, sorted.
+func BuilderIDs() []string {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	ids := make([]string, 0, len(builders))
+	for id := range builders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
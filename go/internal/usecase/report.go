@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"path/filepath"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+// ValidationReport is the machine-readable result of validating one
+// architecture, shared by the arch-gen -output formats and Studio.
+type ValidationReport struct {
+	Architecture string             `json:"architecture"`
+	FailOn       domain.Severity    `json:"failOn"`
+	Passed       bool               `json:"passed"`
+	Results      []ValidationResult `json:"results"`
+}
+
+// ValidationResult is one validation error of a ValidationReport.
+type ValidationResult struct {
+	// RuleID is the stable rule code, such as "CALM001", or the rule name for
+	// rules without a code, such as policy rules.
+	RuleID   string          `json:"ruleId"`
+	Rule     string          `json:"rule"`
+	Severity domain.Severity `json:"severity"`
+	// Failing reports whether the result is at FailOn or above.
+	Failing bool               `json:"failing"`
+	Kind    domain.ElementKind `json:"kind,omitempty"`
+	// ElementID is the unique-id of the node, relationship or flow at fault;
+	// empty for findings about the whole architecture.
+	ElementID string          `json:"elementId,omitempty"`
+	Message   string          `json:"message"`
+	Location  *SourceLocation `json:"location,omitempty"`
+}
+
+// SourceLocation is where the element of a result was defined in the Go DSL.
+type SourceLocation struct {
+	// File is relative to the report's base directory with forward slashes,
+	// or absolute when it lies outside of it.
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// NewValidationReport builds the report of errors for the architecture id.
+// Source files are made relative to baseDir, usually the working directory.
+func NewValidationReport(id string, errors []ValidationError, failOn domain.Severity, baseDir string) ValidationReport {
+	if failOn == "" {
+		failOn = domain.SeverityError
+	}
+	r := ValidationReport{Architecture: id, FailOn: failOn, Passed: true, Results: []ValidationResult{}}
+	for _, e := range errors {
+		res := ValidationResult{
+			RuleID:    e.Code,
+			Rule:      e.Rule,
+			Severity:  e.Severity,
+			Failing:   e.Severity.AtLeast(failOn),
+			Kind:      e.Kind,
+			ElementID: e.NodeID,
+			Message:   e.Message,
+		}
+		if res.RuleID == "" {
+			res.RuleID = e.Rule
+		}
+		if res.Severity == "" {
+			res.Severity = domain.SeverityError
+		}
+		if e.Source.File != "" {
+			res.Location = &SourceLocation{File: relativeFile(baseDir, e.Source.File), Line: e.Source.Line}
+		}
+		if res.Failing {
+			r.Passed = false
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+func relativeFile(baseDir, file string) string {
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, file); err == nil && filepath.IsLocal(rel) {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
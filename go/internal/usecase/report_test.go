@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sokoide/advent-of-calm-2025/internal/domain"
+)
+
+func TestNewValidationReport(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "repo")
+	errs := []ValidationError{
+		{
+			Rule: "AllNodesHaveOwner", Code: "CALM001", NodeID: "api", Kind: domain.KindNode, Message: "missing owner",
+			Source: domain.SourcePos{File: filepath.Join(base, "internal", "usecase", "arch.go"), Line: 12},
+		},
+		{Rule: "no-public-db", NodeID: "db", Message: "db is public", Severity: domain.SeverityWarning},
+	}
+
+	t.Run("should fail on errors and keep stable IDs", func(t *testing.T) {
+		r := NewValidationReport("shop", errs, "", base)
+		if r.Passed || r.FailOn != domain.SeverityError || len(r.Results) != 2 {
+			t.Fatalf("unexpected report %+v", r)
+		}
+		first := r.Results[0]
+		if first.RuleID != "CALM001" || !first.Failing || first.ElementID != "api" || first.Severity != "error" ||
+			first.Location == nil || *first.Location != (SourceLocation{File: "internal/usecase/arch.go", Line: 12}) {
+			t.Errorf("unexpected result %+v", first)
+		}
+		if second := r.Results[1]; second.RuleID != "no-public-db" || second.Failing || second.Location != nil {
+			t.Errorf("unexpected result %+v", second)
+		}
+	})
+
+	t.Run("should pass with findings below fail-on", func(t *testing.T) {
+		if r := NewValidationReport("shop", errs[1:], domain.SeverityError, ""); !r.Passed {
+			t.Errorf("expected report to pass, got %+v", r)
+		}
+		if r := NewValidationReport("shop", nil, "", ""); !r.Passed || r.Results == nil {
+			t.Errorf("expected empty passing report, got %+v", r)
+		}
+	})
+
+	t.Run("should keep files outside the base directory absolute", func(t *testing.T) {
+		r := NewValidationReport("shop", errs[:1], "", filepath.Join(base, "cmd"))
+		if got := r.Results[0].Location.File; got != filepath.ToSlash(errs[0].Source.File) {
+			t.Errorf("expected absolute file, got %q", got)
+		}
+	})
+}
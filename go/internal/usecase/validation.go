@@ -4,12 +4,37 @@ import "github.com/sokoide/advent-of-calm-2025/internal/domain"
 
 // DefaultValidationRules returns the standard set of validation rules.
 func DefaultValidationRules() []domain.ValidationRule {
-	return []domain.ValidationRule{
+	rules := []domain.ValidationRule{
 		domain.AllNodesHaveOwner(),
 		domain.AllServicesHaveHealthEndpoint(),
 		domain.NoDanglingRelationships(),
+		domain.InterfaceReferencesResolve(),
+		domain.NoUnusedInterfaces(),
+		domain.NoInterfaceAddressCollisions(),
 		domain.AllFlowsHaveValidTransitions(),
+	}
+	rules = append(rules, domain.FlowRules()...)
+	rules = append(rules,
 		domain.AllDatabasesHaveBackupSchedule(),
 		domain.AllTier1NodesHaveRunbook(),
+		domain.NoUnknownNodeTypes(),
+		domain.MetadataMatchesVocabulary(),
+	)
+	rules = append(rules, domain.SecurityRules()...)
+	return append(rules, domain.TopologyRules()...)
+}
+
+// Failing returns the validation errors at severity min or above. An empty
+// min means domain.SeverityError.
+func Failing(errors []ValidationError, min domain.Severity) []ValidationError {
+	if min == "" {
+		min = domain.SeverityError
+	}
+	var failing []ValidationError
+	for _, e := range errors {
+		if e.Severity.AtLeast(min) {
+			failing = append(failing, e)
+		}
 	}
+	return failing
 }
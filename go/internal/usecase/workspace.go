@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WorkspaceFileName is the workspace config file looked up in the Go module root.
+const WorkspaceFileName = "workspace.json"
+
+// WorkspaceArchitecture ties a registered builder to the files tools edit for it.
+type WorkspaceArchitecture struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// DSL is the Go DSL file, relative to the Go module root.
+	DSL string `json:"dsl"`
+	// Layout is the Studio layout ID. When empty, the layout is keyed by the
+	// architecture unique-id sent by the frontend.
+	Layout string `json:"layout,omitempty"`
+}
+
+// Workspace lists the architectures maintained in a repository.
+type Workspace struct {
+	Default       string                  `json:"default,omitempty"`
+	Architectures []WorkspaceArchitecture `json:"architectures"`
+}
+
+// DefaultWorkspace is used when no workspace file exists.
+func DefaultWorkspace() Workspace {
+	return Workspace{
+		Default: DefaultArchitectureID,
+		Architectures: []WorkspaceArchitecture{
+			{ID: DefaultArchitectureID, Name: "E-Commerce Order Processing Platform",
+				DSL: "internal/usecase/ecommerce_architecture.go"},
+		},
+	}
+}
+
+// Select returns the architecture with the given ID, or the default one when
+// id is empty.
+func (w Workspace) Select(id string) (WorkspaceArchitecture, error) {
+	if id == "" {
+		id = w.Default
+	}
+	if id == "" && len(w.Architectures) > 0 {
+		id = w.Architectures[0].ID
+	}
+	for _, a := range w.Architectures {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return WorkspaceArchitecture{}, fmt.Errorf("architecture %q is not in the workspace (available: %v)", id, w.IDs())
+}
+
+// IDs returns the workspace architecture IDs, sorted.
+func (w Workspace) IDs() []string {
+	ids := make([]string, 0, len(w.Architectures))
+	for _, a := range w.Architectures {
+		ids = append(ids, a.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Validate checks that entries have an ID and a DSL path, that IDs are unique
+// and that every entry has a registered builder.
+func (w Workspace) Validate() error {
+	seen := make(map[string]bool, len(w.Architectures))
+	for i, a := range w.Architectures {
+		if a.ID == "" || a.DSL == "" {
+			return fmt.Errorf("architectures[%d]: id and dsl are required", i)
+		}
+		if seen[a.ID] {
+			return fmt.Errorf("architectures[%d]: duplicate id %q", i, a.ID)
+		}
+		seen[a.ID] = true
+		if _, err := LookupBuilder(a.ID); err != nil {
+			return fmt.Errorf("architectures[%d]: %w", i, err)
+		}
+	}
+	if w.Default != "" && !seen[w.Default] {
+		return fmt.Errorf("default architecture %q is not in the workspace", w.Default)
+	}
+	return nil
+}
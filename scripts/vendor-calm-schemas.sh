@@ -0,0 +1,26 @@
+#!/bin/bash
+# Vendor the CALM 1.1 meta-schemas, and the JSON Schema 2020-12 meta-schemas
+# they extend, into go/internal/infra/schema/meta/upstream.
+set -euo pipefail
+
+dest="$(cd "$(dirname "$0")/.." && pwd)/go/internal/infra/schema/meta/upstream"
+repo="finos/architecture-as-code"
+ref="${CALM_REF:-main}"
+
+mkdir -p "$dest/calm-1.1" "$dest/json-schema-2020-12/meta"
+
+curl -fsSL "https://api.github.com/repos/$repo/contents/calm/release/1.1/meta?ref=$ref" |
+  grep -o '"download_url": *"[^"]*\.json"' | sed 's/.*"\(https[^"]*\)"/\1/' |
+  while read -r url; do
+    echo "Fetching $url"
+    curl -fsSL "$url" -o "$dest/calm-1.1/$(basename "$url")"
+  done
+curl -fsSL "https://raw.githubusercontent.com/$repo/$ref/LICENSE" -o "$dest/calm-1.1/LICENSE"
+
+base="https://json-schema.org/draft/2020-12"
+curl -fsSL "$base/schema" -o "$dest/json-schema-2020-12/schema"
+for m in core applicator unevaluated validation meta-data format-annotation content; do
+  curl -fsSL "$base/meta/$m" -o "$dest/json-schema-2020-12/meta/$m"
+done
+
+echo "Vendored meta-schemas into $dest"